@@ -0,0 +1,173 @@
+package bloom
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"sync"
+
+	vector "github.com/simonnyman/DISY_Projects/Synchronization/vector"
+)
+
+// Bloom clock (Ramabaja)
+// a counting Bloom filter over events: every event is hashed into a fixed
+// number of cells that are incremented, and receiving takes the cell-wise
+// max. its size does not depend on the number of processes, at the cost of
+// occasionally reporting concurrent events as ordered.
+// thread-safe for concurrent use.
+type Clock struct {
+	processID int
+	cells     []int64
+	hashes    int
+	events    int64 // events recorded by this process, identifies the next event
+	mu        sync.Mutex
+}
+
+// creates a new Bloom clock with the given filter size and hash count.
+// panics if size or hashes is less than 1.
+func NewClock(processID, size, hashes int) *Clock {
+	if size < 1 {
+		panic("bloom: filter size must be at least 1")
+	}
+	if hashes < 1 {
+		panic("bloom: hash count must be at least 1")
+	}
+	return &Clock{
+		processID: processID,
+		cells:     make([]int64, size),
+		hashes:    hashes,
+	}
+}
+
+// increments the clock for a local event.
+func (c *Clock) Tick() []int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.record()
+	return c.copyCells()
+}
+
+// increments the clock and returns timestamp for outgoing message.
+func (c *Clock) Send() []int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.record()
+	return c.copyCells()
+}
+
+// updates the clock based on received timestamp.
+// merges by taking cell-wise max, then records the receive event.
+// panics if the received filter has a different size.
+func (c *Clock) Receive(received []int64) []int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(received) != len(c.cells) {
+		panic("bloom: cannot merge filters of different sizes")
+	}
+
+	for i := range c.cells {
+		c.cells[i] = max(c.cells[i], received[i])
+	}
+	c.record()
+	return c.copyCells()
+}
+
+// returns a copy of the current filter.
+func (c *Clock) Clock() []int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.copyCells()
+}
+
+// sets all cells to zero.
+func (c *Clock) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range c.cells {
+		c.cells[i] = 0
+	}
+	c.events = 0
+}
+
+// hashes the next event of this process into the filter.
+// must be called with lock held.
+func (c *Clock) record() {
+	c.events++
+	for _, cell := range Cells(c.processID, c.events, len(c.cells), c.hashes) {
+		c.cells[cell]++
+	}
+}
+
+// creates a copy of the cells.
+// must be called with lock held.
+func (c *Clock) copyCells() []int64 {
+	cellsCopy := make([]int64, len(c.cells))
+	copy(cellsCopy, c.cells)
+	return cellsCopy
+}
+
+// returns the cells incremented for the given event of a process,
+// using double hashing of the (process, event number) pair.
+// the same cell may be returned more than once.
+func Cells(processID int, event int64, size, hashes int) []int {
+	var buf [16]byte
+	binary.LittleEndian.PutUint64(buf[:8], uint64(processID))
+	binary.LittleEndian.PutUint64(buf[8:], uint64(event))
+
+	h := fnv.New64a()
+	h.Write(buf[:])
+	sum := h.Sum64()
+	h1, h2 := sum&0xffffffff, sum>>32|1
+
+	cells := make([]int, hashes)
+	for i := range cells {
+		cells[i] = int((h1 + uint64(i)*h2) % uint64(size))
+	}
+	return cells
+}
+
+// determines the likely causal relationship between two Bloom timestamps
+// and the probability that an ordered verdict is a false positive.
+// Before and After are only likely: the events may in fact be concurrent.
+// Concurrent is always exact, since causally ordered events can never
+// produce incomparable filters.
+// panics if the filters have different sizes.
+func Compare(b1, b2 []int64) (vector.Ordering, float64) {
+	if len(b1) != len(b2) {
+		panic("bloom: cannot compare filters of different sizes")
+	}
+
+	ordering := vector.CompareClocks(b1, b2)
+	switch ordering {
+	case vector.Before:
+		return ordering, FalsePositiveRate(b1, b2)
+	case vector.After:
+		return ordering, FalsePositiveRate(b2, b1)
+	case vector.Equal:
+		return ordering, FalsePositiveRate(b1, b2)
+	default:
+		return ordering, 0
+	}
+}
+
+// returns the probability that b1 ≤ b2 holds by chance although b1 did not
+// happen before b2: (1 - (1 - 1/m)^Σb2)^Σb1, the chance that every
+// increment in b1 falls on a cell that b2 covers.
+func FalsePositiveRate(b1, b2 []int64) float64 {
+	if len(b1) == 0 {
+		return 0
+	}
+	m := float64(len(b1))
+	covered := 1 - math.Pow(1-1/m, float64(sum(b2)))
+	return math.Pow(covered, float64(sum(b1)))
+}
+
+// returns the sum of all cells.
+func sum(cells []int64) int64 {
+	total := int64(0)
+	for _, n := range cells {
+		total += n
+	}
+	return total
+}
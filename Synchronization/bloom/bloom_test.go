@@ -0,0 +1,242 @@
+package bloom
+
+import (
+	"reflect"
+	"testing"
+
+	vector "github.com/simonnyman/DISY_Projects/Synchronization/vector"
+)
+
+// Basic functionality tests
+
+// creates a new Bloom clock and verifies initial state is zero.
+func TestNewClock(t *testing.T) {
+	c := NewClock(0, 16, 3)
+
+	expected := make([]int64, 16)
+	if !reflect.DeepEqual(c.Clock(), expected) {
+		t.Errorf("Expected zero filter, got %v", c.Clock())
+	}
+}
+
+// verifies panic on invalid filter parameters.
+func TestNewClockPanic(t *testing.T) {
+	tests := []struct {
+		name         string
+		size, hashes int
+	}{
+		{"zero size", 0, 3},
+		{"zero hashes", 16, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("Expected panic for size %d, hashes %d", tt.size, tt.hashes)
+				}
+			}()
+			NewClock(0, tt.size, tt.hashes)
+		})
+	}
+}
+
+// verifies each event adds exactly one increment per hash function.
+func TestBloomTick(t *testing.T) {
+	c := NewClock(0, 16, 3)
+
+	c.Tick()
+	filter := c.Tick()
+	if got := sum(filter); got != 6 {
+		t.Errorf("Expected 6 increments after 2 events, got %d", got)
+	}
+}
+
+// verifies cells are deterministic and within the filter.
+func TestCells(t *testing.T) {
+	a := Cells(3, 7, 32, 4)
+	b := Cells(3, 7, 32, 4)
+
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("Same event hashed differently: %v vs %v", a, b)
+	}
+	for _, cell := range a {
+		if cell < 0 || cell >= 32 {
+			t.Errorf("Cell %d out of bounds", cell)
+		}
+	}
+	if reflect.DeepEqual(a, Cells(4, 7, 32, 4)) && reflect.DeepEqual(a, Cells(3, 8, 32, 4)) {
+		t.Error("Different events should not all hash to the same cells")
+	}
+}
+
+// verifies receive merges cell-wise and records the receive event.
+func TestBloomReceive(t *testing.T) {
+	sender := NewClock(0, 16, 2)
+	receiver := NewClock(1, 16, 2)
+
+	msg := sender.Send()
+	filter := receiver.Receive(msg)
+
+	for i := range msg {
+		if filter[i] < msg[i] {
+			t.Errorf("Cell %d: %d should be at least %d", i, filter[i], msg[i])
+		}
+	}
+	if got := sum(filter); got != sum(msg)+2 {
+		t.Errorf("Expected %d increments, got %d", sum(msg)+2, got)
+	}
+}
+
+// verifies panic when merging or comparing filters of different sizes.
+func TestBloomSizeMismatchPanic(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"receive", func() { NewClock(0, 8, 2).Receive(make([]int64, 4)) }},
+		{"compare", func() { Compare(make([]int64, 8), make([]int64, 4)) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("Expected panic on %s with mismatched sizes", tt.name)
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
+
+// verifies reset clears the filter and restarts event numbering.
+func TestBloomReset(t *testing.T) {
+	c := NewClock(0, 16, 2)
+	first := c.Tick()
+	c.Tick()
+
+	c.Reset()
+	if sum(c.Clock()) != 0 {
+		t.Errorf("Expected zero filter after reset, got %v", c.Clock())
+	}
+	if !reflect.DeepEqual(c.Tick(), first) {
+		t.Error("First event after reset should hash like the original first event")
+	}
+}
+
+// verifies the false-positive rate shrinks as the filter grows.
+func TestFalsePositiveRate(t *testing.T) {
+	small := FalsePositiveRate([]int64{1, 1, 0, 0}, []int64{2, 1, 1, 0})
+	if small <= 0 || small >= 1 {
+		t.Errorf("Expected probability in (0, 1), got %f", small)
+	}
+
+	b1 := make([]int64, 64)
+	b2 := make([]int64, 64)
+	b1[0], b1[1] = 1, 1
+	b2[0], b2[1], b2[2] = 2, 1, 1
+	if large := FalsePositiveRate(b1, b2); large >= small {
+		t.Errorf("Larger filter should have lower rate: %f >= %f", large, small)
+	}
+}
+
+// Scenario-based tests
+
+// verifies causally ordered events are never reported concurrent and
+// the verdicts carry a false-positive probability.
+func TestBloomCompareMessageChain(t *testing.T) {
+	p0 := NewClock(0, 32, 3)
+	p1 := NewClock(1, 32, 3)
+
+	a := p0.Send()
+	b := p1.Receive(a)
+
+	ordering, fp := Compare(a, b)
+	if ordering != vector.Before {
+		t.Errorf("Send should be before receive, got %v", ordering)
+	}
+	if fp <= 0 || fp >= 1 {
+		t.Errorf("Expected false-positive probability in (0, 1), got %f", fp)
+	}
+
+	ordering, _ = Compare(b, a)
+	if ordering != vector.After {
+		t.Errorf("Receive should be after send, got %v", ordering)
+	}
+}
+
+// verifies concurrent events on separate processes are detected exactly
+// when the filter is large enough to keep them apart.
+func TestBloomCompareConcurrent(t *testing.T) {
+	var a, b []int64
+	found := false
+
+	// find a pair whose single events hash to distinct cells
+	for pid := 1; pid < 10 && !found; pid++ {
+		a = NewClock(0, 256, 2).Tick()
+		b = NewClock(pid, 256, 2).Tick()
+		ordering, fp := Compare(a, b)
+		if ordering == vector.Concurrent {
+			found = true
+			if fp != 0 {
+				t.Errorf("Concurrent verdict should be exact, got probability %f", fp)
+			}
+		}
+	}
+
+	if !found {
+		t.Error("Expected independent events to be reported concurrent")
+	}
+}
+
+// verifies that a tiny filter produces false positives that a larger one avoids.
+func TestBloomFilterSizing(t *testing.T) {
+	falsePositives := func(size int) int {
+		count := 0
+		for pid := 0; pid < 20; pid++ {
+			a := NewClock(pid, size, 2)
+			b := NewClock(pid+100, size, 2)
+			for i := 0; i < 5; i++ {
+				a.Tick()
+				b.Tick()
+			}
+			if ordering, _ := Compare(a.Clock(), b.Clock()); ordering != vector.Concurrent {
+				count++
+			}
+		}
+		return count
+	}
+
+	tiny, large := falsePositives(2), falsePositives(1024)
+	if tiny <= large {
+		t.Errorf("Expected more false positives with 2 cells (%d) than 1024 (%d)", tiny, large)
+	}
+}
+
+// verifies thread-safety with mixed concurrent operations.
+func TestBloomConcurrentSendReceive(t *testing.T) {
+	c := NewClock(0, 16, 2)
+	other := NewClock(1, 16, 2).Tick()
+	done := make(chan bool)
+	operations := 100
+
+	for i := 0; i < operations; i++ {
+		go func(val int) {
+			if val%2 == 0 {
+				c.Send()
+			} else {
+				c.Receive(other)
+			}
+			done <- true
+		}(i)
+	}
+
+	for i := 0; i < operations; i++ {
+		<-done
+	}
+
+	if got := sum(c.Clock()); got < int64(2*operations) {
+		t.Errorf("Expected at least %d increments, got %d", 2*operations, got)
+	}
+}
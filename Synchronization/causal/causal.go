@@ -0,0 +1,123 @@
+package causal
+
+import (
+	"sync"
+)
+
+// Message is a broadcast passing through a hold-back queue.
+type Message struct {
+	From       int
+	Broadcasts []int       // sender's broadcast counts, as returned by Broadcast
+	Payload    interface{} // carried along unchanged
+}
+
+// hold-back queue of causal broadcast (Birman–Schiper–Stephenson)
+// every broadcast carries the number of broadcasts its sender had
+// delivered from each process, and its own number in the sender's entry.
+// a broadcast from j is delivered once every earlier broadcast from j has
+// been, and every other entry is covered by broadcasts already delivered.
+// the counts only change with broadcasts, so messages sent outside the
+// queue cannot make a broadcast wait for something it never receives.
+// thread-safe for concurrent use.
+type Queue struct {
+	self      int
+	delivered []int // broadcasts delivered from each sender, sent by self
+	held      []Message
+	mu        sync.Mutex
+}
+
+// creates an empty hold-back queue for process self in a group of n.
+// panics if self is not in [0, n).
+func NewQueue(self, n int) *Queue {
+	if self < 0 || self >= n {
+		panic("causal: process ID out of bounds")
+	}
+	return &Queue{
+		self:      self,
+		delivered: make([]int, n),
+	}
+}
+
+// counts a broadcast by this process and returns the broadcast counts to
+// send with it.
+func (q *Queue) Broadcast() []int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.delivered[q.self]++
+	return append([]int(nil), q.delivered...)
+}
+
+// adds a received broadcast to the queue and returns the broadcasts that
+// can now be delivered, in delivery order. broadcasts that were already
+// delivered or are already held are discarded.
+// panics if the sender is out of bounds or this process, if the counts
+// have a different length, or if the sender's own count is less than 1.
+func (q *Queue) Receive(m Message) []Message {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if m.From < 0 || m.From >= len(q.delivered) || m.From == q.self {
+		panic("causal: sender out of bounds")
+	}
+	if len(m.Broadcasts) != len(q.delivered) {
+		panic("causal: broadcast counts have a different length")
+	}
+	seq := m.Broadcasts[m.From]
+	if seq < 1 {
+		panic("causal: sequence numbers start at 1")
+	}
+
+	if seq <= q.delivered[m.From] {
+		return nil
+	}
+	for _, h := range q.held {
+		if h.From == m.From && h.Broadcasts[m.From] == seq {
+			return nil
+		}
+	}
+	q.held = append(q.held, m)
+
+	var ready []Message
+	for i := 0; i < len(q.held); {
+		if !q.deliverable(q.held[i]) {
+			i++
+			continue
+		}
+		m := q.held[i]
+		q.held = append(q.held[:i], q.held[i+1:]...)
+		q.delivered[m.From] = m.Broadcasts[m.From]
+		ready = append(ready, m)
+		// a delivery can unblock messages earlier in the queue
+		i = 0
+	}
+	return ready
+}
+
+// reports whether every causal predecessor of m has been delivered.
+// must be called with lock held.
+func (q *Queue) deliverable(m Message) bool {
+	if m.Broadcasts[m.From] != q.delivered[m.From]+1 {
+		return false
+	}
+	for k, v := range m.Broadcasts {
+		if k != m.From && v > q.delivered[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// returns the number of broadcasts waiting for their predecessors.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.held)
+}
+
+// returns the number of broadcasts delivered from each sender, and in its
+// own entry the number this process sent.
+func (q *Queue) Delivered() []int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]int(nil), q.delivered...)
+}
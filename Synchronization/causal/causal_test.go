@@ -0,0 +1,159 @@
+package causal
+
+import (
+	"math/rand"
+	"testing"
+
+	vector "github.com/simonnyman/DISY_Projects/Synchronization/vector"
+)
+
+// Basic functionality tests
+
+// verifies a broadcast with no missing predecessors is delivered at once.
+func TestImmediateDelivery(t *testing.T) {
+	q := NewQueue(1, 3)
+
+	ready := q.Receive(Message{From: 0, Broadcasts: []int{1, 0, 0}})
+	if len(ready) != 1 || q.Len() != 0 {
+		t.Errorf("Expected immediate delivery, got %v with %d held", ready, q.Len())
+	}
+}
+
+// verifies broadcasts from one sender are delivered in sending order.
+func TestFIFOHoldBack(t *testing.T) {
+	q := NewQueue(1, 2)
+
+	if ready := q.Receive(Message{From: 0, Broadcasts: []int{2, 0}, Payload: "second"}); len(ready) != 0 {
+		t.Fatalf("Second broadcast should wait for the first, got %v", ready)
+	}
+	ready := q.Receive(Message{From: 0, Broadcasts: []int{1, 0}, Payload: "first"})
+	if len(ready) != 2 || ready[0].Payload != "first" || ready[1].Payload != "second" {
+		t.Errorf("Expected first then second, got %v", ready)
+	}
+}
+
+// verifies delivered and held broadcasts are not delivered again.
+func TestDuplicateDiscarded(t *testing.T) {
+	q := NewQueue(1, 2)
+	q.Receive(Message{From: 0, Broadcasts: []int{1, 0}})
+	q.Receive(Message{From: 0, Broadcasts: []int{3, 0}})
+
+	if ready := q.Receive(Message{From: 0, Broadcasts: []int{1, 0}}); len(ready) != 0 {
+		t.Errorf("Delivered broadcast should be discarded, got %v", ready)
+	}
+	q.Receive(Message{From: 0, Broadcasts: []int{3, 0}})
+	if q.Len() != 1 {
+		t.Errorf("Held broadcast should be kept once, got %d held", q.Len())
+	}
+	if delivered := q.Delivered(); delivered[0] != 1 {
+		t.Errorf("Expected 1 broadcast delivered from 0, got %v", delivered)
+	}
+}
+
+// verifies invalid queues and messages panic.
+func TestInvalidPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"self out of bounds", func() { NewQueue(2, 2) }},
+		{"sender out of bounds", func() { NewQueue(0, 2).Receive(Message{From: 2, Broadcasts: []int{0, 0}}) }},
+		{"own broadcast", func() { NewQueue(0, 2).Receive(Message{From: 0, Broadcasts: []int{1, 0}}) }},
+		{"zero sequence", func() { NewQueue(0, 2).Receive(Message{From: 1, Broadcasts: []int{0, 0}}) }},
+		{"wrong length", func() { NewQueue(0, 2).Receive(Message{From: 1, Broadcasts: []int{1}}) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic")
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
+
+// Scenario-based tests
+
+// verifies a reply overtaking the broadcast it answers waits for it:
+// P0 broadcasts, P1 delivers it and broadcasts a reply, P2 receives the
+// reply first.
+func TestReplyOvertakesQuestion(t *testing.T) {
+	p0, p1 := NewQueue(0, 3), NewQueue(1, 3)
+	question := Message{From: 0, Broadcasts: p0.Broadcast(), Payload: "question"}
+	p1.Receive(question)
+	reply := Message{From: 1, Broadcasts: p1.Broadcast(), Payload: "reply"}
+
+	q := NewQueue(2, 3)
+	if ready := q.Receive(reply); len(ready) != 0 {
+		t.Fatalf("Reply should wait for the question, got %v", ready)
+	}
+	ready := q.Receive(question)
+	if len(ready) != 2 || ready[0].Payload != "question" || ready[1].Payload != "reply" {
+		t.Errorf("Expected question then reply, got %v", ready)
+	}
+}
+
+// verifies random broadcasts arriving in random order are all delivered,
+// and each process delivers them in an order consistent with happened-before.
+func TestRandomArrivalOrder(t *testing.T) {
+	const n = 4
+	rng := rand.New(rand.NewSource(1))
+
+	type transit struct {
+		to int
+		m  Message
+	}
+	clocks := make([]*vector.Vector, n)
+	queues := make([]*Queue, n)
+	seqs := make([]int, n)
+	delivered := make([][]Message, n)
+	for i := range clocks {
+		clocks[i] = vector.NewVector(i, n)
+		queues[i] = NewQueue(i, n)
+	}
+
+	var inFlight []transit
+	broadcasts := 0
+	for step := 0; step < 2000 || len(inFlight) > 0; step++ {
+		if step < 2000 && rng.Intn(3) == 0 {
+			from := rng.Intn(n)
+			seqs[from]++
+			broadcasts++
+			m := Message{From: from, Broadcasts: queues[from].Broadcast(), Payload: clocks[from].Send()}
+			for to := 0; to < n; to++ {
+				if to != from {
+					inFlight = append(inFlight, transit{to, m})
+				}
+			}
+			continue
+		}
+		if len(inFlight) == 0 {
+			continue
+		}
+
+		i := rng.Intn(len(inFlight))
+		tr := inFlight[i]
+		inFlight = append(inFlight[:i], inFlight[i+1:]...)
+		for _, m := range queues[tr.to].Receive(tr.m) {
+			clocks[tr.to].Receive(m.Payload.([]int64))
+			delivered[tr.to] = append(delivered[tr.to], m)
+		}
+	}
+
+	for p, ms := range delivered {
+		if len(ms) != broadcasts-seqs[p] {
+			t.Errorf("P%d delivered %d of %d broadcasts", p, len(ms), broadcasts-seqs[p])
+		}
+		for i := range ms {
+			for j := i + 1; j < len(ms); j++ {
+				earlier, later := ms[i].Payload.([]int64), ms[j].Payload.([]int64)
+				if vector.CompareClocks(later, earlier) == vector.Before {
+					t.Fatalf("P%d delivered %v before its predecessor %v", p, earlier, later)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package clock
+
+import (
+	"math/big"
+
+	bloom "github.com/simonnyman/DISY_Projects/Synchronization/bloom"
+	encoded "github.com/simonnyman/DISY_Projects/Synchronization/encoded"
+	hlc "github.com/simonnyman/DISY_Projects/Synchronization/hlc"
+	itc "github.com/simonnyman/DISY_Projects/Synchronization/itc"
+	lamport "github.com/simonnyman/DISY_Projects/Synchronization/lamport"
+	matrix "github.com/simonnyman/DISY_Projects/Synchronization/matrix"
+	sparse "github.com/simonnyman/DISY_Projects/Synchronization/sparse"
+	vector "github.com/simonnyman/DISY_Projects/Synchronization/vector"
+)
+
+// names of the built-in clocks
+const (
+	Lamport = "lamport" // int64
+	Vector  = "vector"  // []int64
+	HLC     = "hlc"     // hlc.Timestamp
+	Matrix  = "matrix"  // [][]int64
+	ITC     = "itc"     // itc.Stamp
+	Sparse  = "sparse"  // map[string]int64 keyed by process name
+	Bloom   = "bloom"   // []int64
+	Encoded = "encoded" // *big.Int
+)
+
+// default Bloom clock parameters
+const (
+	DefaultBloomSize   = 32
+	DefaultBloomHashes = 3
+)
+
+func init() {
+	Register(Lamport, func(cfg Config) Clock {
+		return &lamportClock{lamport.NewLamportClock()}
+	})
+	Register(Vector, func(cfg Config) Clock {
+		return &vectorClock{vector.NewVector(cfg.ProcessID, cfg.Capacity)}
+	})
+	Register(HLC, func(cfg Config) Clock {
+		if cfg.Now != nil {
+			return &hlcClock{hlc.NewHLCWithClock(cfg.Now)}
+		}
+		return &hlcClock{hlc.NewHLC()}
+	})
+	Register(Matrix, func(cfg Config) Clock {
+		return &matrixClock{matrix.NewMatrix(cfg.ProcessID, cfg.Capacity)}
+	})
+	Register(ITC, newITC)
+	Register(Sparse, func(cfg Config) Clock {
+		return &sparseClock{sparse.NewVector(cfg.Name)}
+	})
+	Register(Bloom, NewBloomFactory(DefaultBloomSize, DefaultBloomHashes))
+	Register(Encoded, func(cfg Config) Clock {
+		return &encodedClock{encoded.NewClock(cfg.ProcessID)}
+	})
+}
+
+// returns a factory for Bloom clocks with the given filter size and hash count.
+// panics if size or hashes is less than 1.
+func NewBloomFactory(size, hashes int) Factory {
+	if size < 1 || hashes < 1 {
+		panic("clock: Bloom filter size and hash count must be at least 1")
+	}
+	return func(cfg Config) Clock {
+		return &bloomClock{bloom.NewClock(cfg.ProcessID, size, hashes)}
+	}
+}
+
+// adapts lamport.LamportClock; comparison is the scalar order of timestamps.
+type lamportClock struct{ c *lamport.LamportClock }
+
+func (l *lamportClock) Tick() Timestamp { return l.c.Tick() }
+func (l *lamportClock) Send() Timestamp { return l.c.Send() }
+func (l *lamportClock) Receive(from int, received Timestamp) Timestamp {
+	return l.c.Receive(received.(int64))
+}
+func (l *lamportClock) Snapshot() Timestamp { return l.c.Time() }
+func (l *lamportClock) Compare(t1, t2 Timestamp) vector.Ordering {
+	return fromSign(t1.(int64), t2.(int64))
+}
+
+// adapts vector.Vector.
+type vectorClock struct{ c *vector.Vector }
+
+func (v *vectorClock) Tick() Timestamp { return v.c.Tick() }
+func (v *vectorClock) Send() Timestamp { return v.c.Send() }
+func (v *vectorClock) Receive(from int, received Timestamp) Timestamp {
+	return v.c.Receive(received.([]int64))
+}
+func (v *vectorClock) Snapshot() Timestamp { return v.c.Clock() }
+func (v *vectorClock) Compare(t1, t2 Timestamp) vector.Ordering {
+	return vector.CompareClocks(t1.([]int64), t2.([]int64))
+}
+
+// adapts hlc.HLC; comparison is the total order of timestamps.
+type hlcClock struct{ c *hlc.HLC }
+
+func (h *hlcClock) Tick() Timestamp { return h.c.Tick() }
+func (h *hlcClock) Send() Timestamp { return h.c.Send() }
+func (h *hlcClock) Receive(from int, received Timestamp) Timestamp {
+	return h.c.Receive(received.(hlc.Timestamp))
+}
+func (h *hlcClock) Snapshot() Timestamp { return h.c.Time() }
+func (h *hlcClock) Compare(t1, t2 Timestamp) vector.Ordering {
+	return fromSign(int64(t1.(hlc.Timestamp).Compare(t2.(hlc.Timestamp))), 0)
+}
+
+// adapts matrix.Matrix.
+type matrixClock struct{ c *matrix.Matrix }
+
+func (m *matrixClock) Tick() Timestamp { return m.c.Tick() }
+func (m *matrixClock) Send() Timestamp { return m.c.Send() }
+func (m *matrixClock) Receive(from int, received Timestamp) Timestamp {
+	return m.c.Receive(from, received.([][]int64))
+}
+func (m *matrixClock) Snapshot() Timestamp { return m.c.Clock() }
+func (m *matrixClock) Compare(t1, t2 Timestamp) vector.Ordering {
+	return matrix.Compare(t1.([][]int64), t2.([][]int64))
+}
+
+// adapts itc.Clock. initial processes take their share of a balanced
+// split of the seed; spawned processes must be forked from their parent.
+type itcClock struct{ c *itc.Clock }
+
+// creates the interval tree clock of one of the initial processes.
+// panics if the process is not one of them.
+func newITC(cfg Config) Clock {
+	if cfg.ProcessID < 0 || cfg.ProcessID >= max(cfg.NumProcesses, 1) {
+		panic("clock: interval tree clocks of spawned processes must be forked")
+	}
+	stamps := itc.Seed().ForkN(max(cfg.NumProcesses, 1))
+	return &itcClock{itc.NewClockFromStamp(stamps[cfg.ProcessID])}
+}
+
+func (i *itcClock) Tick() Timestamp { return i.c.Tick() }
+func (i *itcClock) Send() Timestamp { return i.c.Send() }
+func (i *itcClock) Receive(from int, received Timestamp) Timestamp {
+	return i.c.Receive(received.(itc.Stamp))
+}
+func (i *itcClock) Snapshot() Timestamp { return i.c.Stamp() }
+func (i *itcClock) Compare(t1, t2 Timestamp) vector.Ordering {
+	return itc.Compare(t1.(itc.Stamp), t2.(itc.Stamp))
+}
+func (i *itcClock) Fork() Clock { return &itcClock{i.c.Fork()} }
+func (i *itcClock) Absorb(retiring Clock) Timestamp {
+	return i.c.Absorb(retiring.(*itcClock).c)
+}
+
+// adapts sparse.Vector keyed by process name.
+type sparseClock struct{ c *sparse.Vector[string] }
+
+func (s *sparseClock) Tick() Timestamp { return s.c.Tick() }
+func (s *sparseClock) Send() Timestamp { return s.c.Send() }
+func (s *sparseClock) Receive(from int, received Timestamp) Timestamp {
+	return s.c.Receive(received.(map[string]int64))
+}
+func (s *sparseClock) Snapshot() Timestamp { return s.c.Clock() }
+func (s *sparseClock) Compare(t1, t2 Timestamp) vector.Ordering {
+	return sparse.Compare(t1.(map[string]int64), t2.(map[string]int64))
+}
+
+// adapts bloom.Clock; Compare drops the false-positive probability.
+type bloomClock struct{ c *bloom.Clock }
+
+func (b *bloomClock) Tick() Timestamp { return b.c.Tick() }
+func (b *bloomClock) Send() Timestamp { return b.c.Send() }
+func (b *bloomClock) Receive(from int, received Timestamp) Timestamp {
+	return b.c.Receive(received.([]int64))
+}
+func (b *bloomClock) Snapshot() Timestamp { return b.c.Clock() }
+func (b *bloomClock) Compare(t1, t2 Timestamp) vector.Ordering {
+	ordering, _ := bloom.Compare(t1.([]int64), t2.([]int64))
+	return ordering
+}
+
+// adapts encoded.Clock.
+type encodedClock struct{ c *encoded.Clock }
+
+func (e *encodedClock) Tick() Timestamp { return e.c.Tick() }
+func (e *encodedClock) Send() Timestamp { return e.c.Send() }
+func (e *encodedClock) Receive(from int, received Timestamp) Timestamp {
+	return e.c.Receive(received.(*big.Int))
+}
+func (e *encodedClock) Snapshot() Timestamp { return e.c.Clock() }
+func (e *encodedClock) Compare(t1, t2 Timestamp) vector.Ordering {
+	return encoded.Compare(t1.(*big.Int), t2.(*big.Int))
+}
+
+// maps the order of two scalars to an Ordering.
+// scalar clocks cannot detect concurrency, so they never return Concurrent.
+func fromSign(a, b int64) vector.Ordering {
+	switch {
+	case a < b:
+		return vector.Before
+	case a > b:
+		return vector.After
+	default:
+		return vector.Equal
+	}
+}
@@ -0,0 +1,94 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+
+	vector "github.com/simonnyman/DISY_Projects/Synchronization/vector"
+)
+
+// Timestamp is any value a clock attaches to events and messages,
+// e.g. int64 for Lamport clocks or []int64 for vector clocks.
+type Timestamp interface{}
+
+// Clock is the common interface of all logical clocks.
+// implementations must be thread-safe for concurrent use.
+type Clock interface {
+	// records a local event and returns its timestamp.
+	Tick() Timestamp
+	// records a send event and returns the timestamp for the message.
+	Send() Timestamp
+	// merges a timestamp received from process from and records the receive event.
+	Receive(from int, received Timestamp) Timestamp
+	// returns the current timestamp without recording an event.
+	Snapshot() Timestamp
+	// determines the causal relationship between two timestamps of this clock.
+	Compare(t1, t2 Timestamp) vector.Ordering
+}
+
+// Forker is implemented by clocks whose identity is split when a process
+// spawns and joined when it retires, such as interval tree clocks.
+type Forker interface {
+	Clock
+	// splits off part of the identity for a new process.
+	Fork() Clock
+	// takes over the identity of a retiring clock of the same kind and
+	// records the join as an event.
+	Absorb(retiring Clock) Timestamp
+}
+
+// Config describes the process a clock is created for.
+type Config struct {
+	ProcessID    int
+	Name         string       // hostname-style process name
+	NumProcesses int          // processes present when the simulation starts
+	Capacity     int          // process slots reserved by fixed-size clocks
+	Now          func() int64 // physical time in nanoseconds, nil for the host wall clock
+}
+
+// Factory creates a clock for one process.
+type Factory func(cfg Config) Clock
+
+var (
+	registry   = make(map[string]Factory)
+	registryMu sync.RWMutex
+)
+
+// adds a clock implementation to the registry under the given name.
+// panics if name is empty or taken, or if factory is nil.
+func Register(name string, factory Factory) {
+	if name == "" {
+		panic("clock: name must not be empty")
+	}
+	if factory == nil {
+		panic("clock: factory must not be nil")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[name]; ok {
+		panic("clock: " + name + " is already registered")
+	}
+	registry[name] = factory
+}
+
+// returns the factory registered under name, or false if there is none.
+func Lookup(name string) (Factory, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	factory, ok := registry[name]
+	return factory, ok
+}
+
+// returns the names of all registered clocks in sorted order.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
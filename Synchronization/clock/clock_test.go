@@ -0,0 +1,185 @@
+package clock
+
+import (
+	"reflect"
+	"testing"
+
+	hlc "github.com/simonnyman/DISY_Projects/Synchronization/hlc"
+	itc "github.com/simonnyman/DISY_Projects/Synchronization/itc"
+	vector "github.com/simonnyman/DISY_Projects/Synchronization/vector"
+)
+
+// Basic functionality tests
+
+// verifies all built-in clocks are registered.
+func TestBuiltinsRegistered(t *testing.T) {
+	expected := []string{Bloom, Encoded, HLC, ITC, Lamport, Matrix, Sparse, Vector}
+
+	if got := Names(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	for _, name := range expected {
+		if _, ok := Lookup(name); !ok {
+			t.Errorf("Lookup(%q) failed", name)
+		}
+	}
+	if _, ok := Lookup("missing"); ok {
+		t.Error("Lookup of an unregistered clock should fail")
+	}
+}
+
+// verifies panic on invalid registrations.
+func TestRegisterPanic(t *testing.T) {
+	factory := func(cfg Config) Clock { return nil }
+	tests := []struct {
+		name    string
+		clock   string
+		factory Factory
+	}{
+		{"empty name", "", factory},
+		{"nil factory", "test-nil", nil},
+		{"duplicate", Lamport, factory},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("Expected panic for %s", tt.name)
+				}
+			}()
+			Register(tt.clock, tt.factory)
+		})
+	}
+}
+
+// verifies panic on invalid Bloom parameters.
+func TestNewBloomFactoryPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic for zero filter size")
+		}
+	}()
+	NewBloomFactory(0, 2)
+}
+
+// verifies snapshot returns the last timestamp without recording an event.
+func TestSnapshot(t *testing.T) {
+	for _, name := range Names() {
+		t.Run(name, func(t *testing.T) {
+			factory, _ := Lookup(name)
+			c := factory(Config{ProcessID: 0, Name: "p0", NumProcesses: 1, Capacity: 2})
+
+			ts := c.Tick()
+			if got := c.Snapshot(); c.Compare(ts, got) != vector.Equal {
+				t.Errorf("Snapshot %v should equal last timestamp %v", got, ts)
+			}
+			if got := c.Snapshot(); c.Compare(ts, got) != vector.Equal {
+				t.Errorf("Repeated snapshot should not record an event, got %v", got)
+			}
+		})
+	}
+}
+
+// verifies the HLC reads physical time from the configured source.
+func TestHLCTimeSource(t *testing.T) {
+	factory, _ := Lookup(HLC)
+	c := factory(Config{ProcessID: 0, Name: "p0", NumProcesses: 1, Capacity: 1, Now: func() int64 { return 42 }})
+
+	if ts := c.Tick().(hlc.Timestamp); ts.Wall != 42 {
+		t.Errorf("Expected wall time 42, got %v", ts)
+	}
+}
+
+// Scenario-based tests
+
+// verifies every built-in clock orders a send before its receive and
+// before later events, through the common interface only.
+func TestMessagePassingAllClocks(t *testing.T) {
+	for _, name := range Names() {
+		t.Run(name, func(t *testing.T) {
+			factory, _ := Lookup(name)
+			p0 := factory(Config{ProcessID: 0, Name: "p0", NumProcesses: 2, Capacity: 2})
+			p1 := factory(Config{ProcessID: 1, Name: "p1", NumProcesses: 2, Capacity: 2})
+
+			local := p0.Tick()
+			send := p0.Send()
+			receive := p1.Receive(0, send)
+			later := p1.Tick()
+
+			if got := p1.Compare(send, receive); got != vector.Before {
+				t.Errorf("Send should be before receive, got %v", got)
+			}
+			if got := p1.Compare(local, later); got != vector.Before {
+				t.Errorf("Local event should be before later event, got %v", got)
+			}
+			if got := p1.Compare(later, local); got != vector.After {
+				t.Errorf("Later event should be after local event, got %v", got)
+			}
+		})
+	}
+}
+
+// verifies only clocks that can detect concurrency report it.
+func TestConcurrentDetection(t *testing.T) {
+	exact := map[string]bool{Vector: true, Matrix: true, ITC: true, Sparse: true, Encoded: true}
+
+	for _, name := range Names() {
+		t.Run(name, func(t *testing.T) {
+			factory, _ := Lookup(name)
+			p0 := factory(Config{ProcessID: 0, Name: "p0", NumProcesses: 2, Capacity: 2})
+			p1 := factory(Config{ProcessID: 1, Name: "p1", NumProcesses: 2, Capacity: 2})
+
+			got := p0.Compare(p0.Tick(), p1.Tick())
+			if exact[name] && got != vector.Concurrent {
+				t.Errorf("Expected Concurrent, got %v", got)
+			}
+			if (name == Lamport || name == HLC) && got == vector.Concurrent {
+				t.Error("Scalar clocks cannot report Concurrent")
+			}
+		})
+	}
+}
+
+// verifies interval tree clocks fork and absorb through the Forker interface.
+func TestITCForker(t *testing.T) {
+	factory, _ := Lookup(ITC)
+	parent, ok := factory(Config{ProcessID: 0, NumProcesses: 1}).(Forker)
+	if !ok {
+		t.Fatal("Interval tree clock should implement Forker")
+	}
+
+	spawn := parent.Tick()
+	child := parent.Fork()
+	start := child.Receive(0, spawn)
+	if got := parent.Compare(spawn, start); got != vector.Before {
+		t.Errorf("Spawn should be before start, got %v", got)
+	}
+
+	retire := child.Send()
+	join := parent.Absorb(child)
+	if got := parent.Compare(retire, join); got != vector.Before {
+		t.Errorf("Retire should be before join, got %v", got)
+	}
+	if !child.Snapshot().(itc.Stamp).IsAnonymous() {
+		t.Error("Absorbed clock should have given up its identity")
+	}
+}
+
+// verifies initial interval tree clocks get disjoint identities.
+func TestITCInitialProcesses(t *testing.T) {
+	factory, _ := Lookup(ITC)
+	p0 := factory(Config{ProcessID: 0, NumProcesses: 3})
+	p2 := factory(Config{ProcessID: 2, NumProcesses: 3})
+
+	if got := p0.Compare(p0.Tick(), p2.Tick()); got != vector.Concurrent {
+		t.Errorf("Independent processes should be concurrent, got %v", got)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic for a process beyond the initial ones")
+		}
+	}()
+	factory(Config{ProcessID: 3, NumProcesses: 3})
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/simonnyman/DISY_Projects/Synchronization/simulator"
+)
+
+// runs a small discrete-event simulation and prints the size and width of
+// the lattice of its consistent global states, and whether the given cuts
+// are consistent.
+//
+//	demo lattice [-processes 3] [-duration 100ms] [-seed 1] [-max 1000000]
+//	             [-lower 0,0,0] [-upper 4,5,3] [-cut 2,3,1]
+func runLattice(args []string) {
+	flags := flag.NewFlagSet("lattice", flag.ExitOnError)
+	processes := flags.Int("processes", 3, "number of processes")
+	duration := flags.Duration("duration", 100*time.Millisecond, "virtual time simulated")
+	local := flags.Float64("local", 0.3, "probability of a local event per tick")
+	send := flags.Float64("send", 0.5, "probability of a send per tick")
+	seed := flags.Int64("seed", 1, "seed of the run")
+	maxCuts := flags.Int("max", 1000000, "stop after this many consistent cuts, 0 for no limit")
+	lower := flags.String("lower", "", "lower bound as comma-separated event counts per process")
+	upper := flags.String("upper", "", "upper bound as comma-separated event counts per process")
+	cut := flags.String("cut", "", "cut to test as comma-separated event counts per process")
+	flags.Parse(args)
+
+	sim := simulator.NewSimulator(*processes)
+	sim.RunDiscreteEvent(*duration, *local, *send, *seed)
+
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Println("Global State Lattice")
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	counts := make([]string, len(sim.Processes))
+	for i, p := range sim.Processes {
+		counts[i] = strconv.Itoa(len(p.Events))
+	}
+	fmt.Printf("Events per process: %s (%d in total)\n", strings.Join(counts, ","), len(sim.Events))
+
+	if *cut != "" {
+		c := parseCut(sim, "cut", *cut)
+		fmt.Printf("Cut %s consistent: %v\n", *cut, sim.IsConsistentCut(c))
+	}
+
+	bounds := simulator.LatticeBounds{
+		Lower:   parseCut(sim, "lower bound", *lower),
+		Upper:   parseCut(sim, "upper bound", *upper),
+		MaxCuts: *maxCuts,
+	}
+	checkBounds(sim, bounds)
+	lattice := sim.EnumerateLattice(bounds)
+	fmt.Printf("Lattice size:  %d consistent cuts\n", lattice.Size())
+	fmt.Printf("Lattice width: %d cuts in the widest level\n", lattice.Width())
+	fmt.Printf("Levels:        %d\n", len(lattice.Levels))
+	if lattice.Truncated {
+		fmt.Printf("(stopped after %d cuts; raise -max or narrow the bounds)\n", *maxCuts)
+	}
+}
+
+// parses comma-separated event counts, one per process of the run and at
+// most its number of events, or returns nil for an empty string.
+// exits if they are not.
+func parseCut(sim *simulator.Simulator, name, s string) simulator.Cut {
+	if s == "" {
+		return nil
+	}
+	var cut simulator.Cut
+	for _, field := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			exitUsage("invalid %s %q: %v", name, s, err)
+		}
+		cut = append(cut, n)
+	}
+
+	if len(cut) != len(sim.Processes) {
+		exitUsage("invalid %s %q: expected %d event counts, one per process", name, s, len(sim.Processes))
+	}
+	for i, n := range cut {
+		if events := len(sim.Processes[i].Events); n < 0 || n > events {
+			exitUsage("invalid %s %q: P%d has %d events", name, s, i, events)
+		}
+	}
+	return cut
+}
+
+// exits unless the given bounds are consistent cuts and the lower one is
+// contained in the upper one.
+func checkBounds(sim *simulator.Simulator, bounds simulator.LatticeBounds) {
+	if bounds.Lower != nil && !sim.IsConsistentCut(bounds.Lower) {
+		exitUsage("invalid lower bound: not a consistent cut")
+	}
+	if bounds.Upper != nil && !sim.IsConsistentCut(bounds.Upper) {
+		exitUsage("invalid upper bound: not a consistent cut")
+	}
+	if bounds.Lower == nil || bounds.Upper == nil {
+		return
+	}
+	for i := range bounds.Lower {
+		if bounds.Lower[i] > bounds.Upper[i] {
+			exitUsage("invalid bounds: the lower bound is not contained in the upper bound at P%d", i)
+		}
+	}
+}
+
+// prints an error about the command line and exits with status 2.
+func exitUsage(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(2)
+}
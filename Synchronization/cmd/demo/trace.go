@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/simonnyman/DISY_Projects/Synchronization/simulator"
+)
+
+// runs a small discrete-event simulation, builds its happened-before graph
+// and prints the causal past, future and concurrent events of an event,
+// and the chain of messages explaining why one event happened before
+// another. events are given as process:position, counting from 0.
+//
+//	demo trace [-processes 3] [-duration 100ms] [-seed 1]
+//	           [-event 1:4] [-from 0:2 -to 2:7]
+func runTrace(args []string) {
+	flags := flag.NewFlagSet("trace", flag.ExitOnError)
+	processes := flags.Int("processes", 3, "number of processes")
+	duration := flags.Duration("duration", 100*time.Millisecond, "virtual time simulated")
+	local := flags.Float64("local", 0.3, "probability of a local event per tick")
+	send := flags.Float64("send", 0.5, "probability of a send per tick")
+	seed := flags.Int64("seed", 1, "seed of the run")
+	event := flags.String("event", "", "event to show the causal past, future and concurrent events of")
+	from := flags.String("from", "", "earlier event of a happened-before pair to explain")
+	to := flags.String("to", "", "later event of a happened-before pair to explain")
+	flags.Parse(args)
+
+	sim := simulator.NewSimulator(*processes)
+	sim.RunDiscreteEvent(*duration, *local, *send, *seed)
+	g := sim.HappenedBeforeGraph()
+
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Println("Happened-Before Graph")
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Printf("Events:               %d\n", len(g.Events))
+	fmt.Printf("Edges:                %d\n", len(g.Edges()))
+	fmt.Printf("Transitive reduction: %d edges\n", len(g.TransitiveReduction()))
+
+	if *event != "" {
+		e := parseEvent(sim, g, *event)
+		fmt.Printf("\n%s (%s)\n", eventName(g, e), g.Events[e].EventType)
+		fmt.Printf("  Causal past:   %s\n", eventNames(g, g.Past(e)))
+		fmt.Printf("  Causal future: %s\n", eventNames(g, g.Future(e)))
+		fmt.Printf("  Concurrent:    %s\n", eventNames(g, g.Concurrent(e)))
+	}
+
+	if *from != "" && *to != "" {
+		a, b := parseEvent(sim, g, *from), parseEvent(sim, g, *to)
+		chain, ok := g.Explain(a, b)
+		fmt.Println()
+		switch {
+		case !ok:
+			fmt.Printf("%s did not happen before %s\n", eventName(g, a), eventName(g, b))
+		case len(chain) == 0:
+			fmt.Printf("%s → %s by program order\n", eventName(g, a), eventName(g, b))
+		default:
+			fmt.Printf("%s → %s through %d message(s):\n", eventName(g, a), eventName(g, b), len(chain))
+			for _, edge := range chain {
+				fmt.Printf("  message %d: %s → %s\n", edge.MessageID, eventName(g, edge.From), eventName(g, edge.To))
+			}
+		}
+	}
+	fmt.Println()
+}
+
+// parses an event given as process:position.
+// exits if it is malformed or the process recorded no such event.
+func parseEvent(sim *simulator.Simulator, g *simulator.CausalGraph, s string) int {
+	id, position, ok := strings.Cut(s, ":")
+	p, err1 := strconv.Atoi(strings.TrimSpace(id))
+	n, err2 := strconv.Atoi(strings.TrimSpace(position))
+	if !ok || err1 != nil || err2 != nil {
+		exitUsage("invalid event %q: expected process:position", s)
+	}
+	if p < 0 || p >= len(sim.Processes) {
+		exitUsage("invalid event %q: there are %d processes", s, len(sim.Processes))
+	}
+	if events := len(sim.Processes[p].Events); n < 0 || n >= events {
+		exitUsage("invalid event %q: P%d has %d events", s, p, events)
+	}
+	return g.Index(p, n)
+}
+
+// names an event as process:position.
+func eventName(g *simulator.CausalGraph, e int) string {
+	return fmt.Sprintf("P%d:%d", g.Events[e].ProcessID, g.Position(e))
+}
+
+// lists event names, or "none".
+func eventNames(g *simulator.CausalGraph, events []int) string {
+	if len(events) == 0 {
+		return "none"
+	}
+	names := make([]string, len(events))
+	for i, e := range events {
+		names[i] = eventName(g, e)
+	}
+	return strings.Join(names, " ")
+}
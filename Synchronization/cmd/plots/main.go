@@ -9,6 +9,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/simonnyman/DISY_Projects/Synchronization/encoded"
 	"github.com/simonnyman/DISY_Projects/Synchronization/simulator"
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
@@ -20,9 +21,28 @@ const (
 	simulationTime = 1 * time.Second
 	localEventProb = 0.3
 	sendEventProb  = 0.4
-	numRuns        = 3 // number of runs per scenario (for averaging)
+	numRuns        = 3  // number of runs per scenario (for averaging)
+	encodedBucket  = 50 // events averaged per point of the encoded bit-length curve
 )
 
+// mutual exclusion configuration, run in virtual time
+const (
+	mutexTime        = 1 * time.Second
+	mutexRequestProb = 0.1                   // probability of requesting per tick
+	mutexHold        = 10 * time.Millisecond // time spent in the critical section
+)
+
+// mutual exclusion algorithms to compare, with their plot colors
+var mutexAlgorithms = []struct {
+	Name  string
+	Color color.RGBA
+}{
+	{simulator.MutexLamport, color.RGBA{R: 244, G: 67, B: 54, A: 255}},
+	{simulator.MutexRicartAgrawala, color.RGBA{R: 33, G: 150, B: 243, A: 255}},
+	{simulator.MutexSuzukiKasami, color.RGBA{R: 76, G: 175, B: 80, A: 255}},
+	{simulator.MutexMaekawa, color.RGBA{R: 156, G: 39, B: 176, A: 255}},
+}
+
 // scenarios to test (varying number of processes)
 var scenarios = []struct {
 	NumProcesses int
@@ -51,6 +71,16 @@ type SimulationResult struct {
 	VectorMsgBytes      int // Average message overhead
 	TotalMessages       int
 	ConcurrencyRate     float64
+	EncodedBitsAvg      float64     // average prime-encoded timestamp size
+	EncodedBitsMax      int         // largest prime-encoded timestamp
+	EncodedGrowth       plotter.XYs // encoded bits against events so far (first run)
+}
+
+// MutexResult holds one algorithm's averages for each scenario.
+type MutexResult struct {
+	Algorithm string
+	Messages  plotter.XYs // messages per critical-section entry against processes
+	Wait      plotter.XYs // mean wait in milliseconds against processes
 }
 
 func main() {
@@ -64,6 +94,12 @@ func main() {
 	generateSpaceOverheadPlot(results)
 	generateMessageOverheadPlot(results)
 	generateConcurrencyCausalityPlot(results)
+	generateEncodedSizePlot(results)
+	generateEncodedGrowthPlot(results)
+
+	mutexResults := runMutexSimulations()
+	generateMutexMessagesPlot(mutexResults)
+	generateMutexWaitPlot(mutexResults)
 
 	fmt.Println("\nCombining plots into 2x2 grid...")
 	combinePlots()
@@ -112,6 +148,13 @@ func runSimulations() []SimulationResult {
 			avgResult.VectorMsgBytes += (8 * scenario.NumProcesses) // Vector: full vector
 
 			avgResult.TotalMessages += metrics.TotalMessages
+
+			// Encoded vector clock size, measured on the same trace
+			avgResult.EncodedBitsAvg += metrics.EncodedClockBits
+			avgResult.EncodedBitsMax = max(avgResult.EncodedBitsMax, metrics.EncodedMaxBits)
+			if run == 0 {
+				avgResult.EncodedGrowth = encodedGrowth(sim.Events)
+			}
 		}
 
 		// Calculate averages
@@ -126,6 +169,7 @@ func runSimulations() []SimulationResult {
 		avgResult.LamportMsgBytes /= numRuns
 		avgResult.VectorMsgBytes /= numRuns
 		avgResult.TotalMessages /= numRuns
+		avgResult.EncodedBitsAvg /= numRuns
 
 		if avgResult.TotalPairs > 0 {
 			avgResult.ConcurrencyRate = float64(avgResult.ConcurrentPairs) / float64(avgResult.TotalPairs) * 100
@@ -305,6 +349,174 @@ func generateConcurrencyCausalityPlot(results []SimulationResult) {
 	}
 }
 
+// returns the average encoded bit length of each bucket of events,
+// in the order the events happened.
+func encodedGrowth(events []simulator.Event) plotter.XYs {
+	pts := make(plotter.XYs, 0, len(events)/encodedBucket+1)
+
+	for start := 0; start < len(events); start += encodedBucket {
+		end := min(start+encodedBucket, len(events))
+		bits := 0
+		for _, e := range events[start:end] {
+			bits += encoded.Encode(e.VectorTime).BitLen()
+		}
+		pts = append(pts, plotter.XY{
+			X: float64(end),
+			Y: float64(bits) / float64(end-start),
+		})
+	}
+
+	return pts
+}
+
+// Plot 5: Encoded vs Dense Vector Clock Size by Number of Processes
+func generateEncodedSizePlot(results []SimulationResult) {
+	p := plot.New()
+	p.Title.Text = "Plot 5: Prime-Encoded vs Dense Vector Clock Size"
+	p.X.Label.Text = "Number of Processes"
+	p.Y.Label.Text = "Timestamp Size (bits)"
+	p.Legend.Top = true
+
+	densePts := make(plotter.XYs, len(results))
+	avgPts := make(plotter.XYs, len(results))
+	maxPts := make(plotter.XYs, len(results))
+
+	for i, r := range results {
+		x := float64(r.NumProcesses)
+		densePts[i] = plotter.XY{X: x, Y: float64(8 * r.VectorBytesPerProc)}
+		avgPts[i] = plotter.XY{X: x, Y: r.EncodedBitsAvg}
+		maxPts[i] = plotter.XY{X: x, Y: float64(r.EncodedBitsMax)}
+	}
+
+	denseLine, densePoints, _ := plotter.NewLinePoints(densePts)
+	denseLine.Color = color.RGBA{R: 156, G: 39, B: 176, A: 255}
+	denseLine.Width = vg.Points(2)
+	densePoints.Color = color.RGBA{R: 156, G: 39, B: 176, A: 255}
+	densePoints.Radius = vg.Points(4)
+
+	avgLine, avgPoints, _ := plotter.NewLinePoints(avgPts)
+	avgLine.Color = color.RGBA{R: 0, G: 150, B: 136, A: 255}
+	avgLine.Width = vg.Points(2)
+	avgPoints.Color = color.RGBA{R: 0, G: 150, B: 136, A: 255}
+	avgPoints.Radius = vg.Points(4)
+
+	maxLine, maxPoints, _ := plotter.NewLinePoints(maxPts)
+	maxLine.Color = color.RGBA{R: 0, G: 150, B: 136, A: 255}
+	maxLine.Width = vg.Points(1)
+	maxLine.Dashes = []vg.Length{vg.Points(4), vg.Points(4)}
+	maxPoints.Color = color.RGBA{R: 0, G: 150, B: 136, A: 255}
+	maxPoints.Radius = vg.Points(3)
+
+	p.Add(denseLine, densePoints, avgLine, avgPoints, maxLine, maxPoints)
+	p.Add(plotter.NewGrid())
+	p.Legend.Add("Dense vector (64n bits)", denseLine, densePoints)
+	p.Legend.Add("Encoded: average (measured)", avgLine, avgPoints)
+	p.Legend.Add("Encoded: maximum (measured)", maxLine, maxPoints)
+
+	if err := p.Save(8*vg.Inch, 6*vg.Inch, "plot_pictures/5_encoded_size.png"); err != nil {
+		panic(err)
+	}
+}
+
+// Plot 6: Encoded Vector Clock Bit-Length as Events Accumulate
+func generateEncodedGrowthPlot(results []SimulationResult) {
+	p := plot.New()
+	p.Title.Text = "Plot 6: Prime-Encoded Clock Growth over Events"
+	p.X.Label.Text = "Events Executed"
+	p.Y.Label.Text = fmt.Sprintf("Encoded Timestamp Size (bits, avg per %d events)", encodedBucket)
+	p.Legend.Top = true
+
+	for i, r := range results {
+		line, err := plotter.NewLine(r.EncodedGrowth)
+		if err != nil {
+			panic(err)
+		}
+		shade := uint8(40 + 200*i/max(len(results)-1, 1))
+		line.Color = color.RGBA{R: shade, G: 100, B: 255 - shade, A: 255}
+		line.Width = vg.Points(2)
+
+		p.Add(line)
+		p.Legend.Add(r.Label, line)
+	}
+	p.Add(plotter.NewGrid())
+
+	if err := p.Save(8*vg.Inch, 6*vg.Inch, "plot_pictures/6_encoded_growth.png"); err != nil {
+		panic(err)
+	}
+}
+
+// runs every mutual exclusion algorithm on the same workload for each
+// scenario, averaging numRuns seeds.
+func runMutexSimulations() []MutexResult {
+	results := make([]MutexResult, 0, len(mutexAlgorithms))
+
+	for _, alg := range mutexAlgorithms {
+		fmt.Printf("Running mutual exclusion: %s...\n", alg.Name)
+		result := MutexResult{Algorithm: alg.Name}
+
+		for _, scenario := range scenarios {
+			var messages, wait float64
+			for run := 0; run < numRuns; run++ {
+				sim := simulator.NewSimulator(scenario.NumProcesses)
+				sim.SetMutualExclusion(alg.Name, mutexRequestProb, mutexHold)
+				sim.RunDiscreteEvent(mutexTime, localEventProb, sendEventProb, int64(run+1))
+
+				stats := sim.GetMutexStatistics()
+				messages += stats["messages_per_entry"].(float64)
+				wait += float64(stats["avg_wait"].(time.Duration)) / float64(time.Millisecond)
+			}
+
+			x := float64(scenario.NumProcesses)
+			result.Messages = append(result.Messages, plotter.XY{X: x, Y: messages / numRuns})
+			result.Wait = append(result.Wait, plotter.XY{X: x, Y: wait / numRuns})
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// Plot 7: Mutual Exclusion Messages per Entry by Number of Processes
+func generateMutexMessagesPlot(results []MutexResult) {
+	generateMutexPlot(results, "Plot 7: Mutual Exclusion Messages per Entry", "Messages per Entry",
+		func(r MutexResult) plotter.XYs { return r.Messages }, "plot_pictures/7_mutex_messages.png")
+}
+
+// Plot 8: Mutual Exclusion Mean Wait by Number of Processes
+func generateMutexWaitPlot(results []MutexResult) {
+	generateMutexPlot(results, "Plot 8: Mutual Exclusion Mean Wait", "Mean Wait from Request to Entry (ms)",
+		func(r MutexResult) plotter.XYs { return r.Wait }, "plot_pictures/8_mutex_wait.png")
+}
+
+// draws one line per algorithm against the number of processes.
+func generateMutexPlot(results []MutexResult, title, yLabel string, points func(MutexResult) plotter.XYs, file string) {
+	p := plot.New()
+	p.Title.Text = title
+	p.X.Label.Text = "Number of Processes"
+	p.Y.Label.Text = yLabel
+	p.Legend.Top = true
+	p.Legend.Left = true
+
+	for i, r := range results {
+		line, linePoints, err := plotter.NewLinePoints(points(r))
+		if err != nil {
+			panic(err)
+		}
+		line.Color = mutexAlgorithms[i].Color
+		line.Width = vg.Points(2)
+		linePoints.Color = mutexAlgorithms[i].Color
+		linePoints.Radius = vg.Points(4)
+
+		p.Add(line, linePoints)
+		p.Legend.Add(r.Algorithm, line, linePoints)
+	}
+	p.Add(plotter.NewGrid())
+
+	if err := p.Save(8*vg.Inch, 6*vg.Inch, file); err != nil {
+		panic(err)
+	}
+}
+
 // combinePlots combines the 4 individual plots into a 2x2 grid
 func combinePlots() {
 	// Define the 4 plots to combine (2x2 grid of trade-off analysis)
@@ -0,0 +1,167 @@
+package dvv
+
+import (
+	vector "github.com/simonnyman/DISY_Projects/Synchronization/vector"
+)
+
+// Dot identifies a single write: the counter-th event of a replica.
+type Dot struct {
+	Replica int
+	Counter int64
+}
+
+// dotted version vector (Preguiça et al.)
+// Context is a dense version vector indexed by replica like vector.Vector,
+// Dot is the write this clock stands for (Counter 0 means no dot).
+// the clock describes the set of dots {(i, 1..Context[i])} ∪ {Dot}.
+type Clock struct {
+	Dot     Dot
+	Context []int64
+}
+
+// creates a clock with an empty context for numReplicas replicas.
+func NewClock(numReplicas int) Clock {
+	return Clock{Context: make([]int64, numReplicas)}
+}
+
+// reports whether the clock has seen the given dot.
+func (c Clock) Contains(d Dot) bool {
+	if d.Counter == 0 {
+		return true
+	}
+	if d == c.Dot {
+		return true
+	}
+	return d.Replica >= 0 && d.Replica < len(c.Context) && d.Counter <= c.Context[d.Replica]
+}
+
+// returns the version vector covering the context and the dot.
+func (c Clock) Join() []int64 {
+	joined := make([]int64, len(c.Context))
+	copy(joined, c.Context)
+	if c.Dot.Counter > 0 {
+		joined[c.Dot.Replica] = max(joined[c.Dot.Replica], c.Dot.Counter)
+	}
+	return joined
+}
+
+// reports whether every dot described by c is also described by other.
+// panics if the clocks have different lengths.
+func (c Clock) Leq(other Clock) bool {
+	if len(c.Context) != len(other.Context) {
+		panic("dvv: cannot compare clocks of different lengths")
+	}
+
+	for i, n := range c.Context {
+		covered := other.Context[i]
+		// the other clock's dot may extend its context contiguously
+		if other.Dot.Replica == i && other.Dot.Counter == covered+1 {
+			covered++
+		}
+		if n > covered {
+			return false
+		}
+	}
+	return other.Contains(c.Dot)
+}
+
+// determines the causal relationship between two dotted version vectors.
+// uses set inclusion of the described dots, so a write whose dot lies
+// outside another clock's contiguous range is still ordered correctly.
+// panics if the clocks have different lengths.
+func Compare(a, b Clock) vector.Ordering {
+	ab := a.Leq(b)
+	ba := b.Leq(a)
+
+	switch {
+	case ab && ba:
+		return vector.Equal
+	case ab:
+		return vector.Before
+	case ba:
+		return vector.After
+	default:
+		return vector.Concurrent
+	}
+}
+
+// Sibling is a value stored together with the clock of the write that produced it.
+type Sibling struct {
+	Clock Clock
+	Value interface{}
+}
+
+// returns the version vector that summarizes all siblings.
+// clients read this as the context for their next write.
+func Context(siblings []Sibling, numReplicas int) []int64 {
+	ctx := make([]int64, numReplicas)
+	for _, s := range siblings {
+		for i, n := range s.Clock.Join() {
+			ctx[i] = max(ctx[i], n)
+		}
+	}
+	return ctx
+}
+
+// applies a client write at replica with the context the client last read.
+// siblings the client has seen are replaced, concurrent ones are kept.
+// returns the new sibling list and the new sibling.
+func Update(siblings []Sibling, ctx []int64, replica int, value interface{}) ([]Sibling, Sibling) {
+	// the new dot must be unique for this replica
+	counter := int64(0)
+	if replica < len(ctx) {
+		counter = ctx[replica]
+	}
+	for _, s := range siblings {
+		counter = max(counter, s.Clock.Join()[replica])
+	}
+
+	clockCtx := make([]int64, len(ctx))
+	copy(clockCtx, ctx)
+	written := Sibling{
+		Clock: Clock{Dot: Dot{Replica: replica, Counter: counter + 1}, Context: clockCtx},
+		Value: value,
+	}
+
+	kept := make([]Sibling, 0, len(siblings)+1)
+	for _, s := range siblings {
+		if !written.Clock.Contains(s.Clock.Dot) {
+			kept = append(kept, s)
+		}
+	}
+	return append(kept, written), written
+}
+
+// merges two sibling lists from different replicas, dropping any sibling
+// that another sibling's clock has already seen.
+func Sync(a, b []Sibling) []Sibling {
+	all := make([]Sibling, 0, len(a)+len(b))
+	all = append(all, a...)
+	all = append(all, b...)
+
+	merged := make([]Sibling, 0, len(all))
+	for i, s := range all {
+		obsolete := false
+		for j, other := range all {
+			if i == j {
+				continue
+			}
+			if other.Clock.Dot == s.Clock.Dot {
+				// keep only the first copy of a duplicated write
+				if j < i {
+					obsolete = true
+					break
+				}
+				continue
+			}
+			if other.Clock.Contains(s.Clock.Dot) {
+				obsolete = true
+				break
+			}
+		}
+		if !obsolete {
+			merged = append(merged, s)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,258 @@
+package dvv
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	vector "github.com/simonnyman/DISY_Projects/Synchronization/vector"
+)
+
+// returns the sibling values as sorted strings.
+func sortedValues(values []interface{}) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = v.(string)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Basic functionality tests
+
+// verifies a clock contains its context range and its dot, nothing else.
+func TestClockContains(t *testing.T) {
+	c := Clock{Dot: Dot{Replica: 1, Counter: 5}, Context: []int64{2, 3}}
+
+	tests := []struct {
+		dot      Dot
+		expected bool
+	}{
+		{Dot{0, 1}, true},
+		{Dot{0, 2}, true},
+		{Dot{0, 3}, false},
+		{Dot{1, 3}, true},
+		{Dot{1, 4}, false}, // gap between context and dot
+		{Dot{1, 5}, true},
+		{Dot{1, 6}, false},
+	}
+
+	for _, tt := range tests {
+		if got := c.Contains(tt.dot); got != tt.expected {
+			t.Errorf("Contains(%v) = %v, expected %v", tt.dot, got, tt.expected)
+		}
+	}
+}
+
+// verifies the join covers context and dot.
+func TestClockJoin(t *testing.T) {
+	c := Clock{Dot: Dot{Replica: 1, Counter: 5}, Context: []int64{2, 3}}
+
+	expected := []int64{2, 5}
+	if !reflect.DeepEqual(c.Join(), expected) {
+		t.Errorf("Expected %v, got %v", expected, c.Join())
+	}
+}
+
+// verifies comparison uses set inclusion rather than the joined vectors.
+func TestCompareClocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     Clock
+		expected vector.Ordering
+	}{
+		{
+			"equal",
+			Clock{Dot{0, 1}, []int64{0, 0}},
+			Clock{Dot{0, 1}, []int64{0, 0}},
+			vector.Equal,
+		},
+		{
+			"before via context",
+			Clock{Dot{0, 1}, []int64{0, 0}},
+			Clock{Dot{1, 1}, []int64{1, 0}},
+			vector.Before,
+		},
+		{
+			"after",
+			Clock{Dot{1, 1}, []int64{1, 0}},
+			Clock{Dot{0, 1}, []int64{0, 0}},
+			vector.After,
+		},
+		{
+			// joined vectors are [2 0] and [1 0], but b never saw dot (0, 2)
+			"concurrent writes on same replica",
+			Clock{Dot{0, 2}, []int64{0, 0}},
+			Clock{Dot{0, 1}, []int64{0, 0}},
+			vector.Concurrent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Compare(tt.a, tt.b); got != tt.expected {
+				t.Errorf("Compare = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// verifies panic on mismatched clock lengths.
+func TestCompareClocksPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic when comparing different length clocks")
+		}
+	}()
+
+	Compare(NewClock(2), NewClock(3))
+}
+
+// verifies an update with a fresh context replaces the siblings it has seen.
+func TestUpdateReplacesSeenSiblings(t *testing.T) {
+	siblings, _ := Update(nil, []int64{0, 0}, 0, "v1")
+	ctx := Context(siblings, 2)
+
+	siblings, written := Update(siblings, ctx, 0, "v2")
+
+	if len(siblings) != 1 || siblings[0].Value != "v2" {
+		t.Errorf("Expected only v2, got %v", siblings)
+	}
+	if written.Clock.Dot != (Dot{0, 2}) {
+		t.Errorf("Expected dot (0, 2), got %v", written.Clock.Dot)
+	}
+}
+
+// verifies sync drops siblings the other side has already overwritten.
+func TestSync(t *testing.T) {
+	a, _ := Update(nil, []int64{0, 0}, 0, "a1")
+	b := append([]Sibling(nil), a...)
+
+	// replica 1 overwrites a1, replica 0 keeps it
+	b, _ = Update(b, Context(b, 2), 1, "b1")
+
+	merged := Sync(a, b)
+	if len(merged) != 1 || merged[0].Value != "b1" {
+		t.Errorf("Expected only b1 after sync, got %v", merged)
+	}
+
+	// syncing with itself changes nothing
+	if again := Sync(merged, merged); len(again) != 1 {
+		t.Errorf("Sync should be idempotent, got %v", again)
+	}
+}
+
+// Scenario-based tests
+
+// tests that two clients writing through the same replica keep both values.
+// a plain per-replica version vector would give the second write [2 0],
+// which dominates [1 0] and silently loses the first value.
+func TestConcurrentWritesSameReplica(t *testing.T) {
+	empty := []int64{0, 0}
+
+	siblings, _ := Update(nil, empty, 0, "client A")
+	siblings, _ = Update(siblings, empty, 0, "client B")
+
+	if len(siblings) != 2 {
+		t.Fatalf("Both concurrent writes should survive, got %v", siblings)
+	}
+	if Compare(siblings[0].Clock, siblings[1].Clock) != vector.Concurrent {
+		t.Error("Writes with the same context should be concurrent")
+	}
+
+	// a read-modify-write resolves both
+	siblings, _ = Update(siblings, Context(siblings, 2), 0, "merged")
+	if len(siblings) != 1 {
+		t.Errorf("Read-modify-write should resolve siblings, got %v", siblings)
+	}
+}
+
+// tests that a stale re-write by the same client adds only one sibling,
+// instead of one per previous value.
+func TestStaleRewriteNoExplosion(t *testing.T) {
+	set := NewSet(2)
+
+	// client A reads, then client B keeps writing with fresh contexts
+	staleCtx := set.Join()
+	for i := 0; i < 5; i++ {
+		set = set.Update(set.Join(), 0, "B")
+	}
+	if set.Size() != 1 {
+		t.Fatalf("Fresh writes should not create siblings, got %d", set.Size())
+	}
+
+	// client A writes with its stale context: one real conflict
+	set = set.Update(staleCtx, 0, "A")
+	if set.Size() != 2 {
+		t.Errorf("Stale write should create exactly one sibling, got %v", set.Values())
+	}
+}
+
+// verifies the compact set tracks values and dots per replica.
+func TestSetUpdateAndDots(t *testing.T) {
+	set := NewSet(2)
+	set = set.Update([]int64{0, 0}, 1, "x")
+	set = set.Update([]int64{0, 0}, 1, "y")
+
+	if !reflect.DeepEqual(sortedValues(set.Values()), []string{"x", "y"}) {
+		t.Errorf("Expected values x and y, got %v", set.Values())
+	}
+	expectedDots := []Dot{{1, 2}, {1, 1}}
+	if !reflect.DeepEqual(set.Dots(), expectedDots) {
+		t.Errorf("Expected dots %v, got %v", expectedDots, set.Dots())
+	}
+	if !reflect.DeepEqual(set.Join(), []int64{0, 2}) {
+		t.Errorf("Expected join [0 2], got %v", set.Join())
+	}
+}
+
+// verifies merge keeps concurrent values and drops overwritten ones.
+func TestMergeSets(t *testing.T) {
+	base := NewSet(3).Update([]int64{0, 0, 0}, 0, "base")
+
+	// replica 1 overwrites base, replica 2 writes concurrently
+	r1 := base.Update(base.Join(), 1, "r1")
+	r2 := NewSet(3).Update([]int64{0, 0, 0}, 2, "r2")
+
+	merged := Merge(Merge(base, r1), r2)
+	if !reflect.DeepEqual(sortedValues(merged.Values()), []string{"r1", "r2"}) {
+		t.Errorf("Expected r1 and r2, got %v", merged.Values())
+	}
+
+	// merge is commutative
+	other := Merge(r2, Merge(r1, base))
+	if !reflect.DeepEqual(sortedValues(other.Values()), sortedValues(merged.Values())) {
+		t.Errorf("Merge should be commutative, got %v vs %v", other.Values(), merged.Values())
+	}
+
+	if CompareSets(base, merged) != vector.Before {
+		t.Error("Base should be before the merged set")
+	}
+	if CompareSets(r1, r2) != vector.Concurrent {
+		t.Error("Independent replica writes should be concurrent")
+	}
+}
+
+// verifies discard drops values covered by a context.
+func TestSetDiscard(t *testing.T) {
+	set := NewSet(1)
+	set = set.Update([]int64{0}, 0, "a")
+	set = set.Update([]int64{0}, 0, "b")
+	set = set.Update([]int64{0}, 0, "c")
+
+	set = set.Discard([]int64{2})
+	if !reflect.DeepEqual(set.Values(), []interface{}{"c"}) {
+		t.Errorf("Only c should survive, got %v", set.Values())
+	}
+}
+
+// verifies panic on context length mismatch.
+func TestSetUpdatePanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic for mismatched context length")
+		}
+	}()
+
+	NewSet(2).Update([]int64{0}, 0, "v")
+}
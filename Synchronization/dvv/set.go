@@ -0,0 +1,149 @@
+package dvv
+
+import (
+	vector "github.com/simonnyman/DISY_Projects/Synchronization/vector"
+)
+
+// one replica's entry: its highest counter and the values still live for
+// its most recent dots, newest first. values[k] has dot (replica, counter-k).
+type entry struct {
+	counter int64
+	values  []interface{}
+}
+
+// dotted version vector set (Almeida et al.)
+// stores all siblings of a key in one compact structure with a single
+// counter per replica, indexed like vector.Vector.
+// sets are immutable values; every operation returns a new set.
+type Set struct {
+	entries []entry
+}
+
+// creates an empty set for numReplicas replicas.
+func NewSet(numReplicas int) Set {
+	return Set{entries: make([]entry, numReplicas)}
+}
+
+// returns the version vector of the set, i.e. the causal context a client
+// receives when reading the key.
+func (s Set) Join() []int64 {
+	vv := make([]int64, len(s.entries))
+	for i, e := range s.entries {
+		vv[i] = e.counter
+	}
+	return vv
+}
+
+// returns all sibling values, grouped by replica.
+func (s Set) Values() []interface{} {
+	values := make([]interface{}, 0)
+	for _, e := range s.entries {
+		values = append(values, e.values...)
+	}
+	return values
+}
+
+// returns the dots of all sibling values, in the same order as Values.
+func (s Set) Dots() []Dot {
+	dots := make([]Dot, 0)
+	for i, e := range s.entries {
+		for k := range e.values {
+			dots = append(dots, Dot{Replica: i, Counter: e.counter - int64(k)})
+		}
+	}
+	return dots
+}
+
+// returns the number of sibling values.
+func (s Set) Size() int {
+	size := 0
+	for _, e := range s.entries {
+		size += len(e.values)
+	}
+	return size
+}
+
+// applies a client write at replica with the context the client last read.
+// values whose dots are covered by ctx are discarded, the new value gets
+// the next dot of the replica.
+// panics if ctx has a different length or replica is out of bounds.
+func (s Set) Update(ctx []int64, replica int, value interface{}) Set {
+	if len(ctx) != len(s.entries) {
+		panic("dvv: context length does not match set")
+	}
+	if replica < 0 || replica >= len(s.entries) {
+		panic("dvv: replica out of bounds")
+	}
+
+	updated := s.Discard(ctx)
+	e := updated.entries[replica]
+	counter := max(e.counter, ctx[replica]) + 1
+	values := make([]interface{}, 0, len(e.values)+1)
+	values = append(values, value)
+	values = append(values, e.values...)
+	updated.entries[replica] = entry{counter: counter, values: values}
+	return updated
+}
+
+// drops every value whose dot is covered by ctx and raises the
+// counters to at least ctx.
+// panics if ctx has a different length.
+func (s Set) Discard(ctx []int64) Set {
+	if len(ctx) != len(s.entries) {
+		panic("dvv: context length does not match set")
+	}
+
+	discarded := Set{entries: make([]entry, len(s.entries))}
+	for i, e := range s.entries {
+		live := e.counter - ctx[i]
+		if live < 0 {
+			live = 0
+		}
+		keep := min(int64(len(e.values)), live)
+		discarded.entries[i] = entry{
+			counter: max(e.counter, ctx[i]),
+			values:  append([]interface{}(nil), e.values[:keep]...),
+		}
+	}
+	return discarded
+}
+
+// merges two replicas' sets, keeping a value only if the other side has
+// not seen its dot yet.
+// panics if the sets have different lengths.
+func Merge(a, b Set) Set {
+	if len(a.entries) != len(b.entries) {
+		panic("dvv: cannot merge sets of different lengths")
+	}
+
+	merged := Set{entries: make([]entry, len(a.entries))}
+	for i := range a.entries {
+		merged.entries[i] = mergeEntry(a.entries[i], b.entries[i])
+	}
+	return merged
+}
+
+// merges the entries of one replica.
+func mergeEntry(a, b entry) entry {
+	if a.counter < b.counter {
+		a, b = b, a
+	}
+
+	// a's oldest live dot is newer than b's: a already dropped what b holds
+	oldestA := a.counter - int64(len(a.values))
+	oldestB := b.counter - int64(len(b.values))
+	if oldestA >= oldestB {
+		return entry{counter: a.counter, values: append([]interface{}(nil), a.values...)}
+	}
+
+	// b still holds values that a discarded, so keep a's values down to b's newest
+	keep := a.counter - oldestB
+	return entry{counter: a.counter, values: append([]interface{}(nil), a.values[:keep]...)}
+}
+
+// determines the causal relationship between two sets by comparing their
+// version vectors with vector.CompareClocks.
+// panics if the sets have different lengths.
+func CompareSets(a, b Set) vector.Ordering {
+	return vector.CompareClocks(a.Join(), b.Join())
+}
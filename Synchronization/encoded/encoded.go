@@ -0,0 +1,187 @@
+package encoded
+
+import (
+	"math/big"
+	"sync"
+
+	vector "github.com/simonnyman/DISY_Projects/Synchronization/vector"
+)
+
+// encoded vector clock
+// represents the vector timestamp [v0, v1, ...] as the single integer
+// p0^v0 · p1^v1 · ..., where pi is the i-th prime. ticking multiplies by
+// the own prime, merging takes the LCM, and one timestamp happened before
+// another exactly when it divides it.
+// thread-safe for concurrent use.
+type Clock struct {
+	processID int
+	prime     *big.Int
+	value     *big.Int
+	mu        sync.Mutex
+}
+
+// creates a new encoded clock for the specified process, starting at 1.
+// panics if processID is negative.
+func NewClock(processID int) *Clock {
+	if processID < 0 {
+		panic("encoded: processID must not be negative")
+	}
+	return &Clock{
+		processID: processID,
+		prime:     Prime(processID),
+		value:     big.NewInt(1),
+	}
+}
+
+// increments the clock for a local event.
+func (c *Clock) Tick() *big.Int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.value.Mul(c.value, c.prime)
+	return new(big.Int).Set(c.value)
+}
+
+// increments the clock and returns timestamp for outgoing message.
+func (c *Clock) Send() *big.Int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.value.Mul(c.value, c.prime)
+	return new(big.Int).Set(c.value)
+}
+
+// updates the clock based on received timestamp.
+// merges by taking the LCM, then increments own component.
+func (c *Clock) Receive(received *big.Int) *big.Int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.value = Merge(c.value, received)
+	c.value.Mul(c.value, c.prime)
+	return new(big.Int).Set(c.value)
+}
+
+// returns a copy of the current timestamp.
+func (c *Clock) Clock() *big.Int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return new(big.Int).Set(c.value)
+}
+
+// returns the number of bits needed to store the current timestamp.
+func (c *Clock) BitLen() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.value.BitLen()
+}
+
+// resets the clock to 1, the encoding of the zero vector.
+func (c *Clock) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.value = big.NewInt(1)
+}
+
+// returns the least common multiple of two timestamps, which encodes
+// the entry-wise max of the vectors they represent.
+func Merge(a, b *big.Int) *big.Int {
+	gcd := new(big.Int).GCD(nil, nil, a, b)
+	lcm := new(big.Int).Quo(a, gcd)
+	return lcm.Mul(lcm, b)
+}
+
+// determines the causal relationship between two encoded timestamps.
+// a happened before b exactly when a properly divides b.
+func Compare(a, b *big.Int) vector.Ordering {
+	if a.Cmp(b) == 0 {
+		return vector.Equal
+	}
+	r := new(big.Int)
+	if r.Rem(b, a).Sign() == 0 {
+		return vector.Before
+	}
+	if r.Rem(a, b).Sign() == 0 {
+		return vector.After
+	}
+	return vector.Concurrent
+}
+
+// returns the encoding of a vector timestamp.
+// panics if an entry is negative.
+func Encode(clock []int64) *big.Int {
+	value := big.NewInt(1)
+	power := new(big.Int)
+	for i, n := range clock {
+		if n < 0 {
+			panic("encoded: vector entries must not be negative")
+		}
+		if n > 0 {
+			power.Exp(Prime(i), big.NewInt(n), nil)
+			value.Mul(value, power)
+		}
+	}
+	return value
+}
+
+// returns the n-entry vector timestamp encoded by value.
+// panics if value is not positive or has prime factors beyond the n-th prime.
+func Decode(value *big.Int, n int) []int64 {
+	if value.Sign() <= 0 {
+		panic("encoded: value must be positive")
+	}
+
+	clock := make([]int64, n)
+	rest := new(big.Int).Set(value)
+	q, r := new(big.Int), new(big.Int)
+	for i := range clock {
+		p := Prime(i)
+		for {
+			q.QuoRem(rest, p, r)
+			if r.Sign() != 0 {
+				break
+			}
+			rest.Set(q)
+			clock[i]++
+		}
+	}
+
+	if rest.Cmp(big.NewInt(1)) != 0 {
+		panic("encoded: value has prime factors beyond the vector length")
+	}
+	return clock
+}
+
+var (
+	primes   = []int64{2}
+	primesMu sync.Mutex
+)
+
+// returns the i-th prime, counting from 0.
+// panics if i is negative.
+func Prime(i int) *big.Int {
+	if i < 0 {
+		panic("encoded: prime index must not be negative")
+	}
+
+	primesMu.Lock()
+	defer primesMu.Unlock()
+
+	for candidate := primes[len(primes)-1] + 1; len(primes) <= i; candidate++ {
+		if isPrime(candidate) {
+			primes = append(primes, candidate)
+		}
+	}
+	return big.NewInt(primes[i])
+}
+
+// reports whether n is prime, using the primes found so far.
+// must be called with primesMu held and all primes below sqrt(n) known.
+func isPrime(n int64) bool {
+	for _, p := range primes {
+		if p*p > n {
+			break
+		}
+		if n%p == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,225 @@
+package encoded
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	vector "github.com/simonnyman/DISY_Projects/Synchronization/vector"
+)
+
+// Basic functionality tests
+
+// creates a new encoded clock and verifies it starts at 1.
+func TestNewClock(t *testing.T) {
+	c := NewClock(2)
+
+	if c.Clock().Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("Expected 1, got %v", c.Clock())
+	}
+}
+
+// verifies the first primes.
+func TestPrime(t *testing.T) {
+	expected := []int64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29}
+
+	for i, p := range expected {
+		if got := Prime(i).Int64(); got != p {
+			t.Errorf("Prime(%d) = %d, expected %d", i, got, p)
+		}
+	}
+	if got := Prime(99).Int64(); got != 541 {
+		t.Errorf("Prime(99) = %d, expected 541", got)
+	}
+}
+
+// verifies tick multiplies by the own prime.
+func TestEncodedTick(t *testing.T) {
+	c := NewClock(1)
+
+	c.Tick()
+	if got := c.Tick().Int64(); got != 9 {
+		t.Errorf("Expected 3^2 = 9, got %d", got)
+	}
+}
+
+// verifies receive takes the LCM and multiplies by the own prime.
+func TestEncodedReceive(t *testing.T) {
+	c := NewClock(0)
+	c.Tick() // [1, 0, 0] = 2
+
+	// [0, 2, 1] = 3^2 · 5 = 45
+	got := c.Receive(big.NewInt(45))
+
+	// [2, 2, 1] = 4 · 9 · 5 = 180
+	if got.Int64() != 180 {
+		t.Errorf("Expected 180, got %v", got)
+	}
+}
+
+// verifies returned timestamps are copies.
+func TestEncodedClockIsCopy(t *testing.T) {
+	c := NewClock(0)
+	ts := c.Tick()
+	ts.SetInt64(100)
+
+	if c.Clock().Int64() != 2 {
+		t.Errorf("Modifying a timestamp should not change the clock, got %v", c.Clock())
+	}
+}
+
+// verifies reset returns to the encoding of the zero vector.
+func TestEncodedReset(t *testing.T) {
+	c := NewClock(3)
+	c.Tick()
+	c.Tick()
+
+	c.Reset()
+	if c.Clock().Int64() != 1 || c.BitLen() != 1 {
+		t.Errorf("Expected 1 after reset, got %v", c.Clock())
+	}
+}
+
+// verifies encoding and decoding round trip.
+func TestEncodeDecode(t *testing.T) {
+	clocks := [][]int64{
+		{0, 0, 0},
+		{1, 0, 0},
+		{3, 1, 4},
+		{0, 0, 0, 0, 0, 0, 0, 0, 0, 7},
+	}
+
+	for _, clock := range clocks {
+		got := Decode(Encode(clock), len(clock))
+		if !reflect.DeepEqual(got, clock) {
+			t.Errorf("Round trip of %v gave %v", clock, got)
+		}
+	}
+	if got := Encode([]int64{3, 1, 4}).Int64(); got != 8*3*625 {
+		t.Errorf("Expected 2^3 · 3 · 5^4 = 15000, got %d", got)
+	}
+}
+
+// verifies panics on invalid input.
+func TestEncodedPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"negative process", func() { NewClock(-1) }},
+		{"negative entry", func() { Encode([]int64{1, -1}) }},
+		{"non-positive value", func() { Decode(big.NewInt(0), 2) }},
+		{"factor beyond length", func() { Decode(big.NewInt(5), 2) }},
+		{"negative prime index", func() { Prime(-1) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("Expected panic for %s", tt.name)
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
+
+// verifies Compare agrees with CompareClocks on the decoded vectors.
+func TestEncodedCompare(t *testing.T) {
+	tests := []struct {
+		name   string
+		v1, v2 []int64
+	}{
+		{"equal", []int64{1, 2, 0}, []int64{1, 2, 0}},
+		{"before", []int64{1, 0, 0}, []int64{1, 1, 0}},
+		{"after", []int64{2, 3, 1}, []int64{1, 3, 1}},
+		{"concurrent", []int64{1, 0, 0}, []int64{0, 1, 0}},
+		{"zero before", []int64{0, 0, 0}, []int64{0, 0, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := vector.CompareClocks(tt.v1, tt.v2)
+			if got := Compare(Encode(tt.v1), Encode(tt.v2)); got != want {
+				t.Errorf("Expected %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+// Scenario-based tests
+
+// verifies encoded clocks track dense vector clocks on a message chain.
+func TestEncodedMatchesVector(t *testing.T) {
+	const n = 3
+	enc := make([]*Clock, n)
+	dense := make([]*vector.Vector, n)
+	for i := 0; i < n; i++ {
+		enc[i] = NewClock(i)
+		dense[i] = vector.NewVector(i, n)
+	}
+
+	check := func(e *big.Int, d []int64) {
+		t.Helper()
+		if got := Decode(e, n); !reflect.DeepEqual(got, d) {
+			t.Errorf("Encoded clock decodes to %v, vector clock is %v", got, d)
+		}
+	}
+
+	check(enc[0].Tick(), dense[0].Tick())
+	e, d := enc[0].Send(), dense[0].Send()
+	check(e, d)
+	check(enc[1].Receive(e), dense[1].Receive(d))
+	check(enc[2].Tick(), dense[2].Tick())
+	e, d = enc[1].Send(), dense[1].Send()
+	check(e, d)
+	check(enc[2].Receive(e), dense[2].Receive(d))
+}
+
+// verifies the encoding stays smaller than a dense vector for few events
+// and grows past it as events accumulate.
+func TestEncodedBitLength(t *testing.T) {
+	const n = 20
+	c := NewClock(n - 1) // prime 71, about 6.2 bits per event
+
+	c.Tick()
+	if bits := c.BitLen(); bits >= 64*n {
+		t.Errorf("One event should need fewer than %d bits, got %d", 64*n, bits)
+	}
+
+	for i := 0; i < 250; i++ {
+		c.Tick()
+	}
+	if bits := c.BitLen(); bits <= 64*n {
+		t.Errorf("251 events should need more than %d bits, got %d", 64*n, bits)
+	}
+}
+
+// verifies thread-safety with mixed concurrent operations.
+func TestEncodedConcurrentSendReceive(t *testing.T) {
+	c := NewClock(0)
+	other := Encode([]int64{0, 1})
+	done := make(chan bool)
+	operations := 100
+
+	for i := 0; i < operations; i++ {
+		go func(val int) {
+			if val%2 == 0 {
+				c.Send()
+			} else {
+				c.Receive(other)
+			}
+			done <- true
+		}(i)
+	}
+
+	for i := 0; i < operations; i++ {
+		<-done
+	}
+
+	expected := []int64{int64(operations), 1}
+	if got := Decode(c.Clock(), 2); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,135 @@
+package hlc
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// hybrid logical clock timestamp.
+// Wall follows physical time, Logical orders events that share a wall value.
+type Timestamp struct {
+	Wall    int64 // physical component in nanoseconds
+	Logical int64 // logical counter
+}
+
+// compares two timestamps.
+// returns -1 if t < other, 1 if t > other and 0 if they are identical.
+func (t Timestamp) Compare(other Timestamp) int {
+	switch {
+	case t.Wall < other.Wall:
+		return -1
+	case t.Wall > other.Wall:
+		return 1
+	case t.Logical < other.Logical:
+		return -1
+	case t.Logical > other.Logical:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// reports whether t is ordered strictly before other.
+func (t Timestamp) Before(other Timestamp) bool {
+	return t.Compare(other) < 0
+}
+
+// string returns readable representation of the timestamp.
+func (t Timestamp) String() string {
+	return fmt.Sprintf("%d.%d", t.Wall, t.Logical)
+}
+
+// Hybrid Logical Clock (Kulkarni et al.)
+// thread-safe for concurrent use.
+type HLC struct {
+	mu   sync.Mutex
+	now  func() int64
+	last Timestamp
+}
+
+// creates a new HLC that reads physical time from the host wall clock.
+func NewHLC() *HLC {
+	return NewHLCWithClock(func() int64 {
+		return time.Now().UnixNano()
+	})
+}
+
+// creates a new HLC that reads physical time from the given source.
+// panics if now is nil.
+func NewHLCWithClock(now func() int64) *HLC {
+	if now == nil {
+		panic("hlc: physical clock source must not be nil")
+	}
+	return &HLC{
+		now: now,
+	}
+}
+
+// advances the clock for a local event.
+// the wall component moves to physical time when it is ahead,
+// otherwise the logical counter is incremented.
+func (h *HLC) Tick() Timestamp {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.advance()
+	return h.last
+}
+
+// advances the clock and returns the timestamp for an outgoing message.
+func (h *HLC) Send() Timestamp {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.advance()
+	return h.last
+}
+
+// updates the clock based on received timestamp.
+// wall becomes max(local, received, physical) and the logical counter
+// is chosen so the result is greater than both local and received.
+func (h *HLC) Receive(received Timestamp) Timestamp {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	pt := h.now()
+	wall := max(h.last.Wall, received.Wall, pt)
+
+	switch {
+	case wall == h.last.Wall && wall == received.Wall:
+		h.last.Logical = max(h.last.Logical, received.Logical) + 1
+	case wall == h.last.Wall:
+		h.last.Logical++
+	case wall == received.Wall:
+		h.last.Logical = received.Logical + 1
+	default:
+		h.last.Logical = 0
+	}
+	h.last.Wall = wall
+	return h.last
+}
+
+// returns the current clock value.
+func (h *HLC) Time() Timestamp {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.last
+}
+
+// sets the clock back to zero.
+func (h *HLC) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.last = Timestamp{}
+}
+
+// moves the clock forward for a local or send event.
+// must be called with lock held.
+func (h *HLC) advance() {
+	pt := h.now()
+	if pt > h.last.Wall {
+		h.last.Wall = pt
+		h.last.Logical = 0
+		return
+	}
+	h.last.Logical++
+}
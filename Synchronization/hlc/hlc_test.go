@@ -0,0 +1,209 @@
+package hlc
+
+import (
+	"testing"
+)
+
+// returns a physical clock source that can be set manually.
+func manualClock(start int64) (func() int64, *int64) {
+	now := start
+	return func() int64 { return now }, &now
+}
+
+// Basic functionality tests
+
+// creates a new HLC and verifies initial state is zero.
+func TestNewHLC(t *testing.T) {
+	h := NewHLC()
+
+	if h.Time() != (Timestamp{}) {
+		t.Errorf("Expected initial time 0.0, got %v", h.Time())
+	}
+}
+
+// verifies panic when creating HLC without a clock source.
+func TestNewHLCWithClockPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic for nil clock source")
+		}
+	}()
+
+	NewHLCWithClock(nil)
+}
+
+// verifies tick follows physical time when it advances.
+func TestHLCTickFollowsPhysicalTime(t *testing.T) {
+	source, now := manualClock(100)
+	h := NewHLCWithClock(source)
+
+	ts := h.Tick()
+	if ts != (Timestamp{Wall: 100, Logical: 0}) {
+		t.Errorf("Expected 100.0, got %v", ts)
+	}
+
+	*now = 200
+	ts = h.Tick()
+	if ts != (Timestamp{Wall: 200, Logical: 0}) {
+		t.Errorf("Expected 200.0, got %v", ts)
+	}
+}
+
+// verifies tick increments the logical counter when physical time stalls.
+func TestHLCTickStalledPhysicalTime(t *testing.T) {
+	source, _ := manualClock(100)
+	h := NewHLCWithClock(source)
+
+	h.Tick()
+	h.Tick()
+	ts := h.Send()
+
+	if ts != (Timestamp{Wall: 100, Logical: 2}) {
+		t.Errorf("Expected 100.2, got %v", ts)
+	}
+}
+
+// verifies receive from a clock that is ahead adopts its wall time.
+func TestHLCReceiveFromFuture(t *testing.T) {
+	source, _ := manualClock(100)
+	h := NewHLCWithClock(source)
+	h.Tick() // 100.0
+
+	ts := h.Receive(Timestamp{Wall: 500, Logical: 3})
+
+	// should be 500.(3+1)
+	if ts != (Timestamp{Wall: 500, Logical: 4}) {
+		t.Errorf("Expected 500.4, got %v", ts)
+	}
+}
+
+// verifies receive with equal wall times takes the larger logical counter.
+func TestHLCReceiveEqualWall(t *testing.T) {
+	source, _ := manualClock(100)
+	h := NewHLCWithClock(source)
+	h.Tick() // 100.0
+	h.Tick() // 100.1
+
+	ts := h.Receive(Timestamp{Wall: 100, Logical: 5})
+
+	if ts != (Timestamp{Wall: 100, Logical: 6}) {
+		t.Errorf("Expected 100.6, got %v", ts)
+	}
+}
+
+// verifies receive resets the logical counter when physical time is ahead.
+func TestHLCReceivePhysicalAhead(t *testing.T) {
+	source, now := manualClock(100)
+	h := NewHLCWithClock(source)
+	h.Tick()
+
+	*now = 900
+	ts := h.Receive(Timestamp{Wall: 300, Logical: 7})
+
+	if ts != (Timestamp{Wall: 900, Logical: 0}) {
+		t.Errorf("Expected 900.0, got %v", ts)
+	}
+}
+
+// verifies reset sets clock back to zero.
+func TestHLCReset(t *testing.T) {
+	h := NewHLC()
+	h.Tick()
+	h.Tick()
+
+	h.Reset()
+	if h.Time() != (Timestamp{}) {
+		t.Errorf("Expected time 0.0 after reset, got %v", h.Time())
+	}
+}
+
+// verifies timestamp comparison orders by wall then logical.
+func TestTimestampCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     Timestamp
+		expected int
+	}{
+		{"equal", Timestamp{10, 1}, Timestamp{10, 1}, 0},
+		{"smaller wall", Timestamp{5, 9}, Timestamp{10, 0}, -1},
+		{"larger wall", Timestamp{11, 0}, Timestamp{10, 9}, 1},
+		{"smaller logical", Timestamp{10, 1}, Timestamp{10, 2}, -1},
+		{"larger logical", Timestamp{10, 3}, Timestamp{10, 2}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Compare(tt.b); got != tt.expected {
+				t.Errorf("Compare(%v, %v) = %d, expected %d", tt.a, tt.b, got, tt.expected)
+			}
+			if tt.a.Before(tt.b) != (tt.expected < 0) {
+				t.Errorf("Before(%v, %v) inconsistent with Compare", tt.a, tt.b)
+			}
+		})
+	}
+}
+
+// Scenario-based tests
+
+// verifies causality is preserved even when the receiver's physical clock lags.
+func TestHLCCausalityWithSkew(t *testing.T) {
+	fastSource, _ := manualClock(1000)
+	slowSource, _ := manualClock(10)
+
+	sender := NewHLCWithClock(fastSource)
+	receiver := NewHLCWithClock(slowSource)
+
+	sendTime := sender.Send()
+	receiveTime := receiver.Receive(sendTime)
+
+	if !sendTime.Before(receiveTime) {
+		t.Errorf("Causality violation: receive(%v) should be > send(%v)",
+			receiveTime, sendTime)
+	}
+
+	// the receiver's next local event must still be after the receive
+	next := receiver.Tick()
+	if !receiveTime.Before(next) {
+		t.Errorf("Local event %v should be after receive %v", next, receiveTime)
+	}
+}
+
+// verifies the wall component stays close to the largest physical time seen.
+func TestHLCBoundedByPhysicalTime(t *testing.T) {
+	source, now := manualClock(0)
+	h := NewHLCWithClock(source)
+
+	for i := int64(1); i <= 50; i++ {
+		*now = i * 10
+		ts := h.Tick()
+		if ts.Wall != *now {
+			t.Fatalf("Wall time %d should match physical time %d", ts.Wall, *now)
+		}
+	}
+}
+
+// verifies thread-safety with mixed concurrent operations.
+func TestHLCConcurrentSendReceive(t *testing.T) {
+	h := NewHLC()
+	done := make(chan bool)
+	operations := 100
+
+	for i := 0; i < operations; i++ {
+		go func(val int) {
+			if val%2 == 0 {
+				h.Send()
+			} else {
+				h.Receive(Timestamp{Wall: int64(val)})
+			}
+			done <- true
+		}(i)
+	}
+
+	for i := 0; i < operations; i++ {
+		<-done
+	}
+
+	if h.Time() == (Timestamp{}) {
+		t.Error("Clock should have advanced after concurrent operations")
+	}
+}
@@ -0,0 +1,440 @@
+package itc
+
+import (
+	"fmt"
+	"sync"
+
+	vector "github.com/simonnyman/DISY_Projects/Synchronization/vector"
+)
+
+// identity tree: a leaf is 0 or 1, a node splits the interval in two halves.
+// trees are never modified after construction, so stamps can share them.
+type idTree struct {
+	value       int // leaf value, 0 or 1
+	left, right *idTree
+}
+
+// event tree: a node stores a base counter that applies to both halves.
+type eventTree struct {
+	n           int64
+	left, right *eventTree
+}
+
+var (
+	zeroID = &idTree{value: 0}
+	oneID  = &idTree{value: 1}
+)
+
+// a large cost so growing an existing subtree is preferred over expanding a leaf.
+const expandCost = 1 << 20
+
+// Stamp is an Interval Tree Clock stamp (Almeida, Baquero, Fonte).
+// it pairs an identity, which can be forked and joined as processes come
+// and go, with an event tree recording causal history.
+// stamps are immutable values; every operation returns a new stamp.
+type Stamp struct {
+	id    *idTree
+	event *eventTree
+}
+
+// creates the seed stamp that owns the whole identity interval.
+func Seed() Stamp {
+	return Stamp{id: oneID, event: &eventTree{}}
+}
+
+// splits the identity in two, returning two stamps with the same history.
+func (s Stamp) Fork() (Stamp, Stamp) {
+	s = s.normalized()
+	l, r := splitID(s.id)
+	return Stamp{id: l, event: s.event}, Stamp{id: r, event: s.event}
+}
+
+// splits the identity into n stamps using a balanced sequence of forks.
+// panics if n is less than 1.
+func (s Stamp) ForkN(n int) []Stamp {
+	if n < 1 {
+		panic("itc: fork count must be at least 1")
+	}
+	if n == 1 {
+		return []Stamp{s.normalized()}
+	}
+
+	l, r := s.Fork()
+	return append(l.ForkN(n/2), r.ForkN(n-n/2)...)
+}
+
+// merges two stamps, summing their identities and joining their histories.
+func Join(a, b Stamp) Stamp {
+	a, b = a.normalized(), b.normalized()
+	return Stamp{id: sumID(a.id, b.id), event: joinEvent(a.event, b.event)}
+}
+
+// records a new event, inflating the event tree inside the owned identity.
+// panics if the stamp is anonymous.
+func (s Stamp) Event() Stamp {
+	s = s.normalized()
+	if s.IsAnonymous() {
+		panic("itc: cannot record an event on an anonymous stamp")
+	}
+
+	filled := fill(s.id, s.event)
+	if !equalEvent(filled, s.event) {
+		return Stamp{id: s.id, event: filled}
+	}
+
+	grown, _ := grow(s.id, s.event)
+	return Stamp{id: s.id, event: grown}
+}
+
+// returns an anonymous stamp with the same history, suitable for messages.
+func (s Stamp) Peek() Stamp {
+	s = s.normalized()
+	return Stamp{id: zeroID, event: s.event}
+}
+
+// reports whether the stamp owns no part of the identity interval.
+func (s Stamp) IsAnonymous() bool {
+	return s.id == nil || (s.id.left == nil && s.id.value == 0)
+}
+
+// reports whether the history of s is contained in the history of other.
+func (s Stamp) Leq(other Stamp) bool {
+	s, other = s.normalized(), other.normalized()
+	return leqEvent(s.event, other.event)
+}
+
+// returns the number of tree nodes in the identity and event components.
+func (s Stamp) Size() int {
+	s = s.normalized()
+	return sizeID(s.id) + sizeEvent(s.event)
+}
+
+// string returns the notation used in the ITC paper, e.g. "((1, 0), (0, 1, 0))".
+func (s Stamp) String() string {
+	s = s.normalized()
+	return fmt.Sprintf("(%s, %s)", formatID(s.id), formatEvent(s.event))
+}
+
+// determines the causal relationship between two stamps.
+func Compare(a, b Stamp) vector.Ordering {
+	ab := a.Leq(b)
+	ba := b.Leq(a)
+
+	switch {
+	case ab && ba:
+		return vector.Equal
+	case ab:
+		return vector.Before
+	case ba:
+		return vector.After
+	default:
+		return vector.Concurrent
+	}
+}
+
+// replaces nil components of the zero value with empty trees.
+func (s Stamp) normalized() Stamp {
+	if s.id == nil {
+		s.id = zeroID
+	}
+	if s.event == nil {
+		s.event = &eventTree{}
+	}
+	return s
+}
+
+// identity operations
+
+func newID(l, r *idTree) *idTree {
+	if l.left == nil && r.left == nil && l.value == r.value {
+		if l.value == 0 {
+			return zeroID
+		}
+		return oneID
+	}
+	return &idTree{left: l, right: r}
+}
+
+func splitID(i *idTree) (*idTree, *idTree) {
+	if i.left == nil {
+		if i.value == 0 {
+			return zeroID, zeroID
+		}
+		return newID(oneID, zeroID), newID(zeroID, oneID)
+	}
+
+	if isZeroID(i.left) {
+		r1, r2 := splitID(i.right)
+		return newID(zeroID, r1), newID(zeroID, r2)
+	}
+	if isZeroID(i.right) {
+		l1, l2 := splitID(i.left)
+		return newID(l1, zeroID), newID(l2, zeroID)
+	}
+	return newID(i.left, zeroID), newID(zeroID, i.right)
+}
+
+func sumID(a, b *idTree) *idTree {
+	if isZeroID(a) {
+		return b
+	}
+	if isZeroID(b) {
+		return a
+	}
+	if a.left == nil || b.left == nil {
+		// two overlapping identities can only come from joining a stamp with itself
+		panic("itc: cannot join overlapping identities")
+	}
+	return newID(sumID(a.left, b.left), sumID(a.right, b.right))
+}
+
+func isZeroID(i *idTree) bool {
+	return i.left == nil && i.value == 0
+}
+
+func isOneID(i *idTree) bool {
+	return i.left == nil && i.value == 1
+}
+
+// event operations
+
+func newEvent(n int64, l, r *eventTree) *eventTree {
+	if l.left == nil && r.left == nil && l.n == r.n {
+		return &eventTree{n: n + l.n}
+	}
+	m := min(minEvent(l), minEvent(r))
+	return &eventTree{n: n + m, left: sink(l, m), right: sink(r, m)}
+}
+
+func lift(e *eventTree, m int64) *eventTree {
+	return &eventTree{n: e.n + m, left: e.left, right: e.right}
+}
+
+func sink(e *eventTree, m int64) *eventTree {
+	return &eventTree{n: e.n - m, left: e.left, right: e.right}
+}
+
+func minEvent(e *eventTree) int64 {
+	if e.left == nil {
+		return e.n
+	}
+	return e.n + min(minEvent(e.left), minEvent(e.right))
+}
+
+func maxEvent(e *eventTree) int64 {
+	if e.left == nil {
+		return e.n
+	}
+	return e.n + max(maxEvent(e.left), maxEvent(e.right))
+}
+
+func joinEvent(a, b *eventTree) *eventTree {
+	if a.left == nil && b.left == nil {
+		return &eventTree{n: max(a.n, b.n)}
+	}
+	if a.left == nil {
+		a = &eventTree{n: a.n, left: &eventTree{}, right: &eventTree{}}
+	}
+	if b.left == nil {
+		b = &eventTree{n: b.n, left: &eventTree{}, right: &eventTree{}}
+	}
+	if a.n > b.n {
+		a, b = b, a
+	}
+
+	d := b.n - a.n
+	return newEvent(a.n,
+		joinEvent(a.left, lift(b.left, d)),
+		joinEvent(a.right, lift(b.right, d)))
+}
+
+func leqEvent(a, b *eventTree) bool {
+	if a.left == nil {
+		return a.n <= b.n
+	}
+	if b.left == nil {
+		return a.n <= b.n &&
+			leqEvent(lift(a.left, a.n), b) &&
+			leqEvent(lift(a.right, a.n), b)
+	}
+	return a.n <= b.n &&
+		leqEvent(lift(a.left, a.n), lift(b.left, b.n)) &&
+		leqEvent(lift(a.right, a.n), lift(b.right, b.n))
+}
+
+func equalEvent(a, b *eventTree) bool {
+	if a.n != b.n || (a.left == nil) != (b.left == nil) {
+		return false
+	}
+	if a.left == nil {
+		return true
+	}
+	return equalEvent(a.left, b.left) && equalEvent(a.right, b.right)
+}
+
+// raises the event tree as far as possible inside the owned identity
+// without adding new nodes.
+func fill(i *idTree, e *eventTree) *eventTree {
+	if isZeroID(i) {
+		return e
+	}
+	if isOneID(i) {
+		return &eventTree{n: maxEvent(e)}
+	}
+	if e.left == nil {
+		return e
+	}
+
+	if isOneID(i.left) {
+		r := fill(i.right, e.right)
+		l := &eventTree{n: max(maxEvent(e.left), minEvent(r))}
+		return newEvent(e.n, l, r)
+	}
+	if isOneID(i.right) {
+		l := fill(i.left, e.left)
+		r := &eventTree{n: max(maxEvent(e.right), minEvent(l))}
+		return newEvent(e.n, l, r)
+	}
+	return newEvent(e.n, fill(i.left, e.left), fill(i.right, e.right))
+}
+
+// inflates the event tree by one step inside the owned identity,
+// returning the new tree and the cost of the chosen expansion.
+func grow(i *idTree, e *eventTree) (*eventTree, int) {
+	if e.left == nil {
+		if isOneID(i) {
+			return &eventTree{n: e.n + 1}, 0
+		}
+		grown, cost := grow(i, &eventTree{n: e.n, left: &eventTree{}, right: &eventTree{}})
+		return grown, cost + expandCost
+	}
+
+	if isZeroID(i.left) {
+		r, cost := grow(i.right, e.right)
+		return &eventTree{n: e.n, left: e.left, right: r}, cost + 1
+	}
+	if isZeroID(i.right) {
+		l, cost := grow(i.left, e.left)
+		return &eventTree{n: e.n, left: l, right: e.right}, cost + 1
+	}
+
+	l, costL := grow(i.left, e.left)
+	r, costR := grow(i.right, e.right)
+	if costL < costR {
+		return &eventTree{n: e.n, left: l, right: e.right}, costL + 1
+	}
+	return &eventTree{n: e.n, left: e.left, right: r}, costR + 1
+}
+
+// formatting and sizing helpers
+
+func sizeID(i *idTree) int {
+	if i.left == nil {
+		return 1
+	}
+	return 1 + sizeID(i.left) + sizeID(i.right)
+}
+
+func sizeEvent(e *eventTree) int {
+	if e.left == nil {
+		return 1
+	}
+	return 1 + sizeEvent(e.left) + sizeEvent(e.right)
+}
+
+func formatID(i *idTree) string {
+	if i.left == nil {
+		return fmt.Sprintf("%d", i.value)
+	}
+	return fmt.Sprintf("(%s, %s)", formatID(i.left), formatID(i.right))
+}
+
+func formatEvent(e *eventTree) string {
+	if e.left == nil {
+		return fmt.Sprintf("%d", e.n)
+	}
+	return fmt.Sprintf("(%d, %s, %s)", e.n, formatEvent(e.left), formatEvent(e.right))
+}
+
+// Interval Tree Clock owned by a single process
+// thread-safe for concurrent use.
+type Clock struct {
+	mu    sync.Mutex
+	stamp Stamp
+}
+
+// creates a new clock that owns the whole identity interval.
+func NewClock() *Clock {
+	return NewClockFromStamp(Seed())
+}
+
+// creates a new clock from an existing stamp, e.g. one half of a fork.
+// panics if the stamp is anonymous.
+func NewClockFromStamp(s Stamp) *Clock {
+	if s.IsAnonymous() {
+		panic("itc: clock needs a non-anonymous stamp")
+	}
+	return &Clock{stamp: s.normalized()}
+}
+
+// records a local event.
+func (c *Clock) Tick() Stamp {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stamp = c.stamp.Event()
+	return c.stamp
+}
+
+// records a send event and returns the anonymous stamp for the message.
+func (c *Clock) Send() Stamp {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stamp = c.stamp.Event()
+	return c.stamp.Peek()
+}
+
+// joins the received history and records a receive event.
+func (c *Clock) Receive(received Stamp) Stamp {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stamp = Join(c.stamp, received.Peek()).Event()
+	return c.stamp
+}
+
+// splits off half of the identity for a new process.
+// the parent keeps the other half; both share the current history.
+func (c *Clock) Fork() *Clock {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	kept, given := c.stamp.Fork()
+	c.stamp = kept
+	return &Clock{stamp: given}
+}
+
+// takes over the identity and history of a retiring clock and records
+// the join as an event. the retiring clock becomes anonymous.
+func (c *Clock) Absorb(retiring *Clock) Stamp {
+	retiring.mu.Lock()
+	taken := retiring.stamp
+	retiring.stamp = retiring.stamp.Peek()
+	retiring.mu.Unlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stamp = Join(c.stamp, taken).Event()
+	return c.stamp
+}
+
+// returns the current stamp.
+func (c *Clock) Stamp() Stamp {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stamp
+}
+
+// clears the recorded history while keeping the identity.
+func (c *Clock) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stamp = Stamp{id: c.stamp.id, event: &eventTree{}}
+}
@@ -0,0 +1,263 @@
+package itc
+
+import (
+	"testing"
+
+	vector "github.com/simonnyman/DISY_Projects/Synchronization/vector"
+)
+
+// Basic functionality tests
+
+// verifies the seed owns the whole interval and has empty history.
+func TestSeed(t *testing.T) {
+	s := Seed()
+
+	if s.String() != "(1, 0)" {
+		t.Errorf("Expected seed (1, 0), got %s", s)
+	}
+	if s.IsAnonymous() {
+		t.Error("Seed should not be anonymous")
+	}
+}
+
+// verifies the zero value behaves like an anonymous stamp.
+func TestZeroStamp(t *testing.T) {
+	var s Stamp
+
+	if !s.IsAnonymous() {
+		t.Error("Zero stamp should be anonymous")
+	}
+	if Compare(s, Seed()) != vector.Equal {
+		t.Error("Zero stamp should have the same (empty) history as the seed")
+	}
+}
+
+// verifies fork splits the identity and keeps the history.
+func TestFork(t *testing.T) {
+	a, b := Seed().Event().Fork()
+
+	if a.String() != "((1, 0), 1)" {
+		t.Errorf("Expected ((1, 0), 1), got %s", a)
+	}
+	if b.String() != "((0, 1), 1)" {
+		t.Errorf("Expected ((0, 1), 1), got %s", b)
+	}
+	if Compare(a, b) != vector.Equal {
+		t.Error("Forked stamps should share the same history")
+	}
+}
+
+// verifies ForkN produces distinct identities that join back to the seed.
+func TestForkN(t *testing.T) {
+	stamps := Seed().ForkN(5)
+
+	if len(stamps) != 5 {
+		t.Fatalf("Expected 5 stamps, got %d", len(stamps))
+	}
+
+	joined := stamps[0]
+	for _, s := range stamps[1:] {
+		joined = Join(joined, s)
+	}
+	if joined.String() != "(1, 0)" {
+		t.Errorf("Joining all forks should restore the seed, got %s", joined)
+	}
+}
+
+// verifies event increments the history within the owned interval.
+func TestEvent(t *testing.T) {
+	a, b := Seed().Fork()
+
+	a = a.Event()
+	if a.String() != "((1, 0), (0, 1, 0))" {
+		t.Errorf("Expected ((1, 0), (0, 1, 0)), got %s", a)
+	}
+
+	b = b.Event()
+	if Compare(a, b) != vector.Concurrent {
+		t.Errorf("Independent events should be concurrent, got %v", Compare(a, b))
+	}
+}
+
+// verifies join merges identities and histories and normalizes the result.
+func TestJoin(t *testing.T) {
+	a, b := Seed().Fork()
+	a = a.Event()
+	b = b.Event()
+
+	joined := Join(a, b)
+	if joined.String() != "(1, 1)" {
+		t.Errorf("Expected (1, 1), got %s", joined)
+	}
+	if Compare(a, joined) != vector.Before || Compare(b, joined) != vector.Before {
+		t.Error("Both inputs should happen before the joined stamp")
+	}
+}
+
+// verifies peek produces an anonymous stamp with the same history.
+func TestPeek(t *testing.T) {
+	s := Seed().Event().Event()
+	p := s.Peek()
+
+	if !p.IsAnonymous() {
+		t.Error("Peek should return an anonymous stamp")
+	}
+	if Compare(s, p) != vector.Equal {
+		t.Error("Peek should keep the history")
+	}
+}
+
+// verifies panic when recording an event on an anonymous stamp.
+func TestEventAnonymousPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic for event on anonymous stamp")
+		}
+	}()
+
+	Seed().Peek().Event()
+}
+
+// verifies panic when joining a stamp with itself.
+func TestJoinOverlapPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic for overlapping identities")
+		}
+	}()
+
+	s := Seed()
+	Join(s, s)
+}
+
+// Scenario-based tests
+
+// runs the example from the ITC paper (fork, events, join) and checks orderings.
+func TestPaperExample(t *testing.T) {
+	seed := Seed()
+	a, b := seed.Fork()
+
+	a = a.Event()
+	b = b.Event()
+
+	a, c := a.Fork()
+	b = b.Event()
+
+	a = a.Event()
+	b, d := Join(b, c).Fork()
+
+	if Compare(d, b) != vector.Equal {
+		t.Error("Both halves of a fork should have equal history")
+	}
+	if Compare(a, b) != vector.Concurrent {
+		t.Errorf("a and b should be concurrent, got %v", Compare(a, b))
+	}
+
+	e := Join(a, b).Event()
+	if Compare(a, e) != vector.Before || Compare(d, e) != vector.Before {
+		t.Error("Joined event should be after both inputs")
+	}
+	if Compare(e, a) != vector.After {
+		t.Errorf("Expected After, got %v", Compare(e, a))
+	}
+}
+
+// verifies message passing with clocks matches vector clock orderings.
+func TestClockMessagePassing(t *testing.T) {
+	stamps := Seed().ForkN(3)
+	p0 := NewClockFromStamp(stamps[0])
+	p1 := NewClockFromStamp(stamps[1])
+	p2 := NewClockFromStamp(stamps[2])
+
+	v0 := vector.NewVector(0, 3)
+	v1 := vector.NewVector(1, 3)
+	v2 := vector.NewVector(2, 3)
+
+	type sample struct {
+		stamp Stamp
+		clock []int64
+	}
+	samples := make([]sample, 0)
+	record := func(s Stamp, v []int64) {
+		samples = append(samples, sample{s, v})
+	}
+
+	record(p0.Tick(), v0.Tick())
+	msg, vmsg := p0.Send(), v0.Send()
+	record(p0.Stamp(), vmsg)
+	record(p2.Tick(), v2.Tick())
+	record(p1.Receive(msg), v1.Receive(vmsg))
+	msg, vmsg = p1.Send(), v1.Send()
+	record(p1.Stamp(), vmsg)
+	record(p2.Receive(msg), v2.Receive(vmsg))
+	record(p0.Tick(), v0.Tick())
+
+	for i := range samples {
+		for j := range samples {
+			got := Compare(samples[i].stamp, samples[j].stamp)
+			want := vector.CompareClocks(samples[i].clock, samples[j].clock)
+			if got != want {
+				t.Errorf("Sample %d vs %d: ITC says %v, vector says %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+// verifies a forked clock can later be absorbed by another process.
+func TestClockForkAndAbsorb(t *testing.T) {
+	parent := NewClock()
+	parent.Tick()
+
+	child := parent.Fork()
+	childEvent := child.Tick()
+	parentEvent := parent.Tick()
+
+	if Compare(childEvent, parentEvent) != vector.Concurrent {
+		t.Error("Events after a fork should be concurrent")
+	}
+
+	joined := parent.Absorb(child)
+	if Compare(childEvent, joined) != vector.Before {
+		t.Error("Absorb should be after the retiring clock's last event")
+	}
+	if !child.Stamp().IsAnonymous() {
+		t.Error("Retired clock should be anonymous after absorb")
+	}
+	if parent.Stamp().String() != "(1, 3)" {
+		t.Errorf("Parent should own the whole interval again, got %s", parent.Stamp())
+	}
+}
+
+// verifies repeated churn keeps stamps small after identities are rejoined.
+func TestClockChurnStaysCompact(t *testing.T) {
+	root := NewClock()
+
+	for round := 0; round < 50; round++ {
+		workers := []*Clock{root.Fork(), root.Fork(), root.Fork()}
+		for _, w := range workers {
+			w.Tick()
+			root.Receive(w.Send())
+		}
+		for _, w := range workers {
+			root.Absorb(w)
+		}
+	}
+
+	if size := root.Stamp().Size(); size != 2 {
+		t.Errorf("Stamp should collapse to a single id and event leaf, got size %d (%s)",
+			size, root.Stamp())
+	}
+}
+
+// verifies reset clears history but keeps the identity.
+func TestClockReset(t *testing.T) {
+	stamps := Seed().ForkN(2)
+	c := NewClockFromStamp(stamps[0])
+	c.Tick()
+	c.Tick()
+
+	c.Reset()
+	if c.Stamp().String() != "((1, 0), 0)" {
+		t.Errorf("Expected ((1, 0), 0) after reset, got %s", c.Stamp())
+	}
+}
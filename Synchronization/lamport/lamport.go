@@ -56,3 +56,15 @@ func (lc *LamportClock) Reset() {
 	defer lc.mu.Unlock()
 	lc.time = 0
 }
+
+// sets the clock to a previously saved time, e.g. when a process recovers
+// from a crash. the clock may move backwards.
+// panics if t is negative.
+func (lc *LamportClock) Restore(t int64) {
+	if t < 0 {
+		panic("lamport: cannot restore a negative time")
+	}
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.time = t
+}
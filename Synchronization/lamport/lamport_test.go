@@ -86,6 +86,59 @@ func TestReset(t *testing.T) {
 	}
 }
 
+// verifies restore sets the clock to a saved time, even an earlier one.
+func TestRestore(t *testing.T) {
+	lc := NewLamportClock()
+	lc.Tick()
+	lc.Tick()
+	lc.Tick()
+
+	lc.Restore(1)
+	if lc.Tick() != 2 {
+		t.Errorf("Expected time 2 after restoring 1 and ticking, got %d", lc.Time())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic for a negative time")
+		}
+	}()
+	lc.Restore(-1)
+}
+
+// verifies the total order compares time first, then process ID.
+func TestTimestampCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		t1, t2   Timestamp
+		expected int
+	}{
+		{"earlier time", Timestamp{1, 5}, Timestamp{2, 0}, -1},
+		{"later time", Timestamp{3, 0}, Timestamp{2, 5}, 1},
+		{"tie broken by lower ID", Timestamp{2, 0}, Timestamp{2, 1}, -1},
+		{"tie broken by higher ID", Timestamp{2, 3}, Timestamp{2, 1}, 1},
+		{"identical", Timestamp{2, 1}, Timestamp{2, 1}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.t1.Compare(tt.t2); got != tt.expected {
+				t.Errorf("Compare(%v, %v) = %d, expected %d", tt.t1, tt.t2, got, tt.expected)
+			}
+			if got := tt.t1.Less(tt.t2); got != (tt.expected < 0) {
+				t.Errorf("Less(%v, %v) = %v", tt.t1, tt.t2, got)
+			}
+		})
+	}
+}
+
+// verifies String() formats time and process.
+func TestTimestampString(t *testing.T) {
+	if got := (Timestamp{Time: 7, ProcessID: 2}).String(); got != "7.P2" {
+		t.Errorf("Expected 7.P2, got %s", got)
+	}
+}
+
 // Scenario-based tests
 
 // simulates message exchange between two processes.
@@ -223,3 +276,26 @@ func TestConcurrentSendReceive(t *testing.T) {
 		t.Error("Clock should have advanced after concurrent operations")
 	}
 }
+
+// verifies concurrent events with equal Lamport times still get a strict
+// order, while causally related events keep theirs.
+func TestTotalOrderTieBreaking(t *testing.T) {
+	p0 := NewLamportClock()
+	p1 := NewLamportClock()
+
+	a := Timestamp{p0.Tick(), 0}
+	b := Timestamp{p1.Tick(), 1}
+	if a.Time != b.Time {
+		t.Fatalf("Expected equal Lamport times, got %d and %d", a.Time, b.Time)
+	}
+	if !a.Less(b) || b.Less(a) {
+		t.Errorf("Equal times should be ordered by process ID: %v, %v", a, b)
+	}
+
+	// P1 → P0: the receive must come after the send despite the lower ID
+	send := Timestamp{p1.Send(), 1}
+	receive := Timestamp{p0.Receive(send.Time), 0}
+	if !send.Less(receive) {
+		t.Errorf("Send %v should be ordered before receive %v", send, receive)
+	}
+}
@@ -0,0 +1,41 @@
+package lamport
+
+import (
+	"fmt"
+)
+
+// Lamport timestamp with process-ID tie-breaking.
+// ordering by Time and then ProcessID gives the total order from Lamport's
+// paper: it never contradicts happened-before, and no two events of
+// different processes compare equal.
+type Timestamp struct {
+	Time      int64 // Lamport clock value
+	ProcessID int   // process that recorded the event
+}
+
+// compares two timestamps in the total order.
+// returns -1 if t < other, 1 if t > other and 0 if they are identical.
+func (t Timestamp) Compare(other Timestamp) int {
+	switch {
+	case t.Time < other.Time:
+		return -1
+	case t.Time > other.Time:
+		return 1
+	case t.ProcessID < other.ProcessID:
+		return -1
+	case t.ProcessID > other.ProcessID:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// reports whether t is ordered strictly before other.
+func (t Timestamp) Less(other Timestamp) bool {
+	return t.Compare(other) < 0
+}
+
+// string returns readable representation of the timestamp.
+func (t Timestamp) String() string {
+	return fmt.Sprintf("%d.P%d", t.Time, t.ProcessID)
+}
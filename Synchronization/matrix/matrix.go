@@ -0,0 +1,177 @@
+package matrix
+
+import (
+	"sync"
+
+	vector "github.com/simonnyman/DISY_Projects/Synchronization/vector"
+)
+
+// matrix clock
+// row i holds this process's view of process i's vector clock,
+// the own row is the ordinary vector clock.
+// thread-safe for concurrent use.
+type Matrix struct {
+	processID int
+	clock     [][]int64
+	mu        sync.RWMutex
+}
+
+// creates a new Matrix clock for the specified process.
+func NewMatrix(processID, numProcesses int) *Matrix {
+	clock := make([][]int64, numProcesses)
+	for i := range clock {
+		clock[i] = make([]int64, numProcesses)
+	}
+	return &Matrix{
+		processID: processID,
+		clock:     clock,
+	}
+}
+
+// increments the clock for a local event.
+func (m *Matrix) Tick() [][]int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clock[m.processID][m.processID]++
+	return m.copyClock()
+}
+
+// increments the clock and returns timestamp for outgoing message.
+func (m *Matrix) Send() [][]int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clock[m.processID][m.processID]++
+	return m.copyClock()
+}
+
+// updates the clock based on a matrix received from senderID.
+// merges the sender's row into the own row, takes the component-wise max
+// of every row, then increments own counter.
+// panics if the received matrix has different dimensions.
+func (m *Matrix) Receive(senderID int, receivedClock [][]int64) [][]int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(receivedClock) != len(m.clock) {
+		panic("matrix: cannot merge clocks of different dimensions")
+	}
+	if senderID < 0 || senderID >= len(m.clock) {
+		panic("matrix: senderID out of bounds")
+	}
+
+	own := m.clock[m.processID]
+	for k, val := range receivedClock[senderID] {
+		own[k] = max(own[k], val)
+	}
+	for i, row := range receivedClock {
+		if len(row) != len(m.clock[i]) {
+			panic("matrix: cannot merge clocks of different dimensions")
+		}
+		for k, val := range row {
+			m.clock[i][k] = max(m.clock[i][k], val)
+		}
+	}
+	own[m.processID]++
+	return m.copyClock()
+}
+
+// returns a copy of the current matrix clock.
+func (m *Matrix) Clock() [][]int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.copyClock()
+}
+
+// returns a copy of the own row, which equals the process's vector clock.
+func (m *Matrix) Vector() []int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	row := make([]int64, len(m.clock[m.processID]))
+	copy(row, m.clock[m.processID])
+	return row
+}
+
+// reports whether this process knows that every process has seen
+// the counter-th event of processID.
+func (m *Matrix) KnownByAll(processID int, counter int64) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return KnownByAll(m.clock, processID, counter)
+}
+
+// sets all components to zero.
+func (m *Matrix) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.clock {
+		for k := range m.clock[i] {
+			m.clock[i][k] = 0
+		}
+	}
+}
+
+// creates a deep copy of the clock matrix.
+// must be called with lock held.
+func (m *Matrix) copyClock() [][]int64 {
+	return Copy(m.clock)
+}
+
+// creates a deep copy of a matrix timestamp.
+func Copy(clock [][]int64) [][]int64 {
+	clockCopy := make([][]int64, len(clock))
+	for i, row := range clock {
+		clockCopy[i] = make([]int64, len(row))
+		copy(clockCopy[i], row)
+	}
+	return clockCopy
+}
+
+// returns the highest event counter of processID that every process
+// is known to have seen, i.e. the minimum of column processID.
+// panics if processID is out of bounds.
+func MinKnown(clock [][]int64, processID int) int64 {
+	if len(clock) == 0 {
+		return 0
+	}
+	if processID < 0 || processID >= len(clock[0]) {
+		panic("matrix: processID out of bounds")
+	}
+
+	minimum := clock[0][processID]
+	for _, row := range clock[1:] {
+		minimum = min(minimum, row[processID])
+	}
+	return minimum
+}
+
+// reports whether the matrix timestamp shows that every process has seen
+// the counter-th event of processID. such events can be garbage collected.
+func KnownByAll(clock [][]int64, processID int, counter int64) bool {
+	return MinKnown(clock, processID) >= counter
+}
+
+// determines the causal relationship between two matrix timestamps.
+// the column-wise max of a matrix is its owner's vector time, so the
+// timestamps are ordered exactly like the vector clocks they contain.
+// panics if the timestamps have different dimensions.
+func Compare(m1, m2 [][]int64) vector.Ordering {
+	if len(m1) != len(m2) {
+		panic("matrix: cannot compare clocks of different dimensions")
+	}
+	return vector.CompareClocks(columnMax(m1), columnMax(m2))
+}
+
+// returns the maximum of each column.
+func columnMax(clock [][]int64) []int64 {
+	if len(clock) == 0 {
+		return nil
+	}
+	maxima := make([]int64, len(clock[0]))
+	for _, row := range clock {
+		for j, n := range row {
+			maxima[j] = max(maxima[j], n)
+		}
+	}
+	return maxima
+}
@@ -0,0 +1,215 @@
+package matrix
+
+import (
+	"reflect"
+	"testing"
+
+	vector "github.com/simonnyman/DISY_Projects/Synchronization/vector"
+)
+
+// Basic functionality tests
+
+// creates a new Matrix clock and verifies initial state is zero.
+func TestNewMatrix(t *testing.T) {
+	m := NewMatrix(0, 3)
+
+	expected := [][]int64{{0, 0, 0}, {0, 0, 0}, {0, 0, 0}}
+	if !reflect.DeepEqual(m.Clock(), expected) {
+		t.Errorf("Expected %v, got %v", expected, m.Clock())
+	}
+}
+
+// verifies tick increments only the own diagonal entry.
+func TestMatrixTick(t *testing.T) {
+	m := NewMatrix(1, 3)
+
+	clock := m.Tick()
+	expected := [][]int64{{0, 0, 0}, {0, 1, 0}, {0, 0, 0}}
+
+	if !reflect.DeepEqual(clock, expected) {
+		t.Errorf("Expected %v, got %v", expected, clock)
+	}
+}
+
+// verifies send increments and returns correct timestamp.
+func TestMatrixSend(t *testing.T) {
+	m := NewMatrix(0, 2)
+
+	m.Send()
+	clock := m.Send()
+	expected := [][]int64{{2, 0}, {0, 0}}
+
+	if !reflect.DeepEqual(clock, expected) {
+		t.Errorf("Expected %v, got %v", expected, clock)
+	}
+}
+
+// verifies receive merges the sender's row into the own row.
+func TestMatrixReceive(t *testing.T) {
+	sender := NewMatrix(0, 2)
+	receiver := NewMatrix(1, 2)
+
+	sender.Tick()
+	msg := sender.Send() // [[2 0] [0 0]]
+	clock := receiver.Receive(0, msg)
+
+	// own row learns P0's vector, row 0 is copied as P0's view
+	expected := [][]int64{{2, 0}, {2, 1}}
+	if !reflect.DeepEqual(clock, expected) {
+		t.Errorf("Expected %v, got %v", expected, clock)
+	}
+}
+
+// verifies the returned timestamp is a copy.
+func TestMatrixClockIsCopy(t *testing.T) {
+	m := NewMatrix(0, 2)
+	clock := m.Tick()
+	clock[0][0] = 42
+
+	if m.Clock()[0][0] != 1 {
+		t.Error("Modifying a returned timestamp should not change the clock")
+	}
+}
+
+// verifies the own row matches the vector clock view.
+func TestMatrixVector(t *testing.T) {
+	m := NewMatrix(1, 3)
+	m.Tick()
+	m.Receive(2, [][]int64{{0, 0, 0}, {0, 0, 0}, {0, 0, 4}})
+
+	expected := []int64{0, 2, 4}
+	if !reflect.DeepEqual(m.Vector(), expected) {
+		t.Errorf("Expected %v, got %v", expected, m.Vector())
+	}
+}
+
+// verifies reset sets all components to zero.
+func TestMatrixReset(t *testing.T) {
+	m := NewMatrix(0, 2)
+	m.Tick()
+	m.Receive(1, [][]int64{{0, 0}, {3, 3}})
+
+	m.Reset()
+	expected := [][]int64{{0, 0}, {0, 0}}
+	if !reflect.DeepEqual(m.Clock(), expected) {
+		t.Errorf("Expected %v after reset, got %v", expected, m.Clock())
+	}
+}
+
+// verifies panic when receiving a matrix of different dimensions.
+func TestMatrixReceivePanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic for mismatched dimensions")
+		}
+	}()
+
+	m := NewMatrix(0, 2)
+	m.Receive(1, [][]int64{{0, 0, 0}, {0, 0, 0}, {0, 0, 0}})
+}
+
+// verifies MinKnown returns the column minimum.
+func TestMinKnown(t *testing.T) {
+	clock := [][]int64{
+		{3, 1, 0},
+		{2, 4, 0},
+		{5, 1, 2},
+	}
+
+	if got := MinKnown(clock, 0); got != 2 {
+		t.Errorf("Expected min 2 for P0, got %d", got)
+	}
+	if got := MinKnown(clock, 1); got != 1 {
+		t.Errorf("Expected min 1 for P1, got %d", got)
+	}
+	if !KnownByAll(clock, 0, 2) {
+		t.Error("P0's second event should be known by all")
+	}
+	if KnownByAll(clock, 0, 3) {
+		t.Error("P0's third event should not be known by all")
+	}
+}
+
+// verifies matrix timestamps are ordered like the vector clocks they contain.
+func TestMatrixCompare(t *testing.T) {
+	p0 := NewMatrix(0, 2)
+	p1 := NewMatrix(1, 2)
+
+	a := p0.Send()
+	b := p1.Tick()
+	c := p1.Receive(0, a)
+
+	tests := []struct {
+		name     string
+		m1, m2   [][]int64
+		expected vector.Ordering
+	}{
+		{"send before receive", a, c, vector.Before},
+		{"receive after send", c, a, vector.After},
+		{"concurrent", a, b, vector.Concurrent},
+		{"equal", c, Copy(c), vector.Equal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Compare(tt.m1, tt.m2); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// Scenario-based tests
+
+// tests that an event becomes known by all after a full round of gossip.
+func TestMatrixKnowledgeOfKnowledge(t *testing.T) {
+	p0 := NewMatrix(0, 3)
+	p1 := NewMatrix(1, 3)
+	p2 := NewMatrix(2, 3)
+
+	p0.Tick() // the event we track: P0's first event
+
+	// P0 → P1 → P2: everybody has seen the event, but only P2 knows P1 saw it
+	p1.Receive(0, p0.Send())
+	p2.Receive(1, p1.Send())
+
+	if p0.KnownByAll(0, 1) {
+		t.Error("P0 should not yet know that everybody has seen its event")
+	}
+
+	// P2 → P0: P0 now learns P1's and P2's views
+	p0.Receive(2, p2.Send())
+
+	if !p0.KnownByAll(0, 1) {
+		t.Errorf("P0 should know that everybody has seen its event, matrix %v", p0.Clock())
+	}
+	if p1.KnownByAll(0, 1) {
+		t.Error("P1 has not heard from P2 and should not know the event is stable")
+	}
+}
+
+// verifies thread-safety with mixed concurrent operations.
+func TestMatrixConcurrentOperations(t *testing.T) {
+	m := NewMatrix(0, 3)
+	done := make(chan bool)
+	operations := 100
+
+	for i := 0; i < operations; i++ {
+		go func(val int) {
+			if val%2 == 0 {
+				m.Send()
+			} else {
+				m.Receive(1, [][]int64{{0, 0, 0}, {0, int64(val), 0}, {0, 0, 0}})
+			}
+			done <- true
+		}(i)
+	}
+
+	for i := 0; i < operations; i++ {
+		<-done
+	}
+
+	if m.Clock()[0][0] != int64(operations) {
+		t.Errorf("Expected own counter %d, got %d", operations, m.Clock()[0][0])
+	}
+}
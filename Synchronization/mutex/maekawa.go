@@ -0,0 +1,378 @@
+package mutex
+
+import (
+	"math"
+	"sort"
+
+	lamport "github.com/simonnyman/DISY_Projects/Synchronization/lamport"
+)
+
+// Voter is implemented by quorum algorithms, in which every process votes
+// for one request at a time and a process enters once its whole quorum
+// voted for it. the votes give the wait-for relation between processes.
+type Voter interface {
+	// returns the processes whose votes this process needs, including itself.
+	Quorum() []int
+	// returns the quorum members that voted for the pending request.
+	Votes() []int
+	// returns the process this process voted for, or -1.
+	VotedFor() int
+}
+
+// returns the grid quorum of process self in a group of n: the processes
+// in its row and column when the group is laid out row by row in a grid
+// ceil(√n) wide. any two quorums intersect, also when the last row is
+// incomplete, and each has fewer than 2√n members.
+// panics if self is not in [0, n).
+func GridQuorum(self, n int) []int {
+	checkMember(self, n)
+	width := int(math.Ceil(math.Sqrt(float64(n))))
+	row, col := self/width, self%width
+
+	var quorum []int
+	for id := row * width; id < min((row+1)*width, n); id++ {
+		quorum = append(quorum, id)
+	}
+	for id := col; id < n; id += width {
+		if id != self {
+			quorum = append(quorum, id)
+		}
+	}
+	sort.Ints(quorum)
+	return quorum
+}
+
+// a request waiting for a process's vote
+type ticket struct {
+	stamp  lamport.Timestamp
+	failed bool // the requester was told an earlier request holds the vote
+}
+
+// Maekawa's quorum-based mutual exclusion
+// a process timestamps its request and sends it to its grid quorum. every
+// process votes for one request at a time and queues the others; a
+// process enters once its whole quorum voted for it, and on leaving it
+// releases the votes. since quorums intersect, two processes never hold
+// all their votes at once. votes granted to different requests can form a
+// cycle, which the deadlock-resolving variant breaks: a voter asks the
+// holder of its vote to give it back (INQUIRE) when an earlier request
+// arrives, tells later requests they lost (FAILED), and a requester that
+// lost a vote elsewhere gives inquired votes back (RELINQUISH). costs
+// 3 to 5 times √n messages per entry and needs reliable FIFO links.
+// thread-safe for concurrent use.
+type Maekawa struct {
+	state
+	resolve bool
+	clock   *lamport.LamportClock
+	quorum  []int
+
+	// as a requester
+	own       lamport.Timestamp // own pending request
+	votes     map[int]bool      // quorum members that voted for it
+	failedBy  map[int]bool      // quorum members that reported an earlier request
+	yielded   map[int]bool      // quorum members given their vote back
+	inquiries map[int]bool      // inquiries deferred until a vote is lost
+
+	// as a voter
+	voted    bool
+	votedFor lamport.Timestamp // request holding the vote
+	inquired bool              // an inquiry for the vote is outstanding
+	waiting  []ticket          // queued requests, earliest first
+}
+
+// creates the deadlock-resolving algorithm for process self in a group
+// of n. panics if self is not in [0, n).
+func NewMaekawa(self, n int) *Maekawa {
+	return newMaekawa(self, n, true)
+}
+
+// creates Maekawa's algorithm without deadlock resolution, which sends no
+// INQUIRE, RELINQUISH or FAILED messages and can deadlock; for comparison.
+// panics if self is not in [0, n).
+func NewNaiveMaekawa(self, n int) *Maekawa {
+	return newMaekawa(self, n, false)
+}
+
+func newMaekawa(self, n int, resolve bool) *Maekawa {
+	return &Maekawa{
+		state:   state{self: self, n: n},
+		resolve: resolve,
+		clock:   lamport.NewLamportClock(),
+		quorum:  GridQuorum(self, n),
+	}
+}
+
+// requests the critical section from the quorum. returns the packets to
+// send and whether the process may enter right away, which only a group
+// of one can.
+// panics if a request is already pending or the section is held.
+func (m *Maekawa) Request() ([]Packet, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.request()
+	m.own = lamport.Timestamp{Time: m.clock.Send(), ProcessID: m.self}
+	m.votes = make(map[int]bool)
+	m.failedBy = make(map[int]bool)
+	m.yielded = make(map[int]bool)
+	m.inquiries = make(map[int]bool)
+
+	return m.route(m.toQuorum(Request, m.own))
+}
+
+// handles a packet as a requester or as a voter.
+// panics on an unknown packet kind or sender.
+func (m *Maekawa) Receive(p Packet) ([]Packet, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.checkSender(p)
+	m.clock.Receive(p.Stamp.Time)
+	return m.route([]Packet{p})
+}
+
+// leaves the critical section and releases the quorum's votes.
+// panics if the section is not held.
+func (m *Maekawa) Release() []Packet {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.release()
+	m.votes = nil
+	packets, _ := m.route(m.toQuorum(Release, m.own))
+	return packets
+}
+
+// returns the processes whose votes this process needs, including itself.
+func (m *Maekawa) Quorum() []int {
+	return append([]int(nil), m.quorum...)
+}
+
+// returns the quorum members that voted for the pending request.
+func (m *Maekawa) Votes() []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var votes []int
+	for id := range m.votes {
+		votes = append(votes, id)
+	}
+	sort.Ints(votes)
+	return votes
+}
+
+// returns the process this process voted for, or -1.
+func (m *Maekawa) VotedFor() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.voted {
+		return -1
+	}
+	return m.votedFor.ProcessID
+}
+
+// returns a packet for every quorum member, including this process.
+// must be called with lock held.
+func (m *Maekawa) toQuorum(kind string, stamp lamport.Timestamp) []Packet {
+	packets := make([]Packet, 0, len(m.quorum))
+	for _, to := range m.quorum {
+		packets = append(packets, Packet{Kind: kind, From: m.self, To: to, Stamp: stamp})
+	}
+	return packets
+}
+
+// handles the packets addressed to this process itself, with everything
+// they cause, and returns the packets for others and whether the process
+// entered the critical section.
+// must be called with lock held.
+func (m *Maekawa) route(packets []Packet) ([]Packet, bool) {
+	var out []Packet
+	entered := false
+	for len(packets) > 0 {
+		p := packets[0]
+		packets = packets[1:]
+		if p.To != m.self {
+			out = append(out, p)
+			continue
+		}
+		more, ok := m.handle(p)
+		packets = append(packets, more...)
+		entered = entered || ok
+	}
+	return out, entered
+}
+
+// handles one packet. returns the packets it causes and whether the
+// process entered the critical section.
+// must be called with lock held.
+func (m *Maekawa) handle(p Packet) ([]Packet, bool) {
+	switch p.Kind {
+	case Request:
+		return m.onRequest(p.Stamp), false
+	case Release:
+		if m.voted && m.votedFor == p.Stamp {
+			m.voted = false
+			return m.voteNext(), false
+		}
+		return nil, false
+	case Relinquish:
+		if m.voted && m.votedFor == p.Stamp {
+			m.enqueue(ticket{stamp: p.Stamp, failed: true})
+			m.voted = false
+			return m.voteNext(), false
+		}
+		return nil, false
+	case Reply:
+		return m.onVote(p.From, p.Stamp)
+	case Failed:
+		return m.onFailed(p.From, p.Stamp), false
+	case Inquire:
+		return m.onInquire(p.From, p.Stamp), false
+	default:
+		panic("mutex: unexpected packet kind " + p.Kind)
+	}
+}
+
+// votes for a request or queues it. with deadlock resolution, a request
+// earlier than every other one makes the voter inquire at the holder of
+// its vote, and any other request is told it failed.
+// must be called with lock held.
+func (m *Maekawa) onRequest(stamp lamport.Timestamp) []Packet {
+	if !m.voted {
+		return []Packet{m.vote(stamp)}
+	}
+
+	t := ticket{stamp: stamp}
+	var packets []Packet
+	if m.resolve {
+		earliest := stamp.Less(m.votedFor) && (len(m.waiting) == 0 || stamp.Less(m.waiting[0].stamp))
+		if earliest {
+			if !m.inquired {
+				m.inquired = true
+				packets = append(packets, Packet{Kind: Inquire, From: m.self, To: m.votedFor.ProcessID, Stamp: m.votedFor})
+			}
+			packets = append(packets, m.failWaiting()...)
+		} else {
+			t.failed = true
+			packets = append(packets, m.fail(stamp))
+		}
+	}
+	m.enqueue(t)
+	return packets
+}
+
+// votes for the earliest queued request, if any, and tells the others
+// they failed.
+// must be called with lock held.
+func (m *Maekawa) voteNext() []Packet {
+	m.inquired = false
+	if len(m.waiting) == 0 {
+		return nil
+	}
+	next := m.waiting[0]
+	m.waiting = m.waiting[1:]
+	packets := []Packet{m.vote(next.stamp)}
+	if m.resolve {
+		packets = append(packets, m.failWaiting()...)
+	}
+	return packets
+}
+
+// records a vote for a request and returns it.
+// must be called with lock held.
+func (m *Maekawa) vote(stamp lamport.Timestamp) Packet {
+	m.voted = true
+	m.votedFor = stamp
+	return Packet{Kind: Reply, From: m.self, To: stamp.ProcessID, Stamp: stamp}
+}
+
+// tells every queued request not told yet that it failed.
+// must be called with lock held.
+func (m *Maekawa) failWaiting() []Packet {
+	var packets []Packet
+	for i := range m.waiting {
+		if !m.waiting[i].failed {
+			m.waiting[i].failed = true
+			packets = append(packets, m.fail(m.waiting[i].stamp))
+		}
+	}
+	return packets
+}
+
+// returns a failure notice for a request.
+// must be called with lock held.
+func (m *Maekawa) fail(stamp lamport.Timestamp) Packet {
+	return Packet{Kind: Failed, From: m.self, To: stamp.ProcessID, Stamp: stamp}
+}
+
+// adds a request to the queue, keeping it sorted.
+// must be called with lock held.
+func (m *Maekawa) enqueue(t ticket) {
+	i := sort.Search(len(m.waiting), func(i int) bool { return t.stamp.Less(m.waiting[i].stamp) })
+	m.waiting = append(m.waiting, ticket{})
+	copy(m.waiting[i+1:], m.waiting[i:])
+	m.waiting[i] = t
+}
+
+// counts a vote for the own request and enters once the quorum voted.
+// must be called with lock held.
+func (m *Maekawa) onVote(from int, stamp lamport.Timestamp) ([]Packet, bool) {
+	if !m.requesting || m.holding || stamp != m.own {
+		return nil, false
+	}
+	m.votes[from] = true
+	delete(m.failedBy, from)
+	delete(m.yielded, from)
+	if len(m.votes) < len(m.quorum) {
+		return nil, false
+	}
+	// deferred inquiries are answered by the release
+	m.inquiries = make(map[int]bool)
+	m.holding = true
+	return nil, true
+}
+
+// notes a lost vote and gives back every vote whose inquiry was deferred.
+// must be called with lock held.
+func (m *Maekawa) onFailed(from int, stamp lamport.Timestamp) []Packet {
+	if !m.requesting || m.holding || stamp != m.own {
+		return nil
+	}
+	m.failedBy[from] = true
+
+	var inquirers []int
+	for id := range m.inquiries {
+		inquirers = append(inquirers, id)
+	}
+	sort.Ints(inquirers)
+	var packets []Packet
+	for _, id := range inquirers {
+		packets = append(packets, m.relinquish(id))
+	}
+	return packets
+}
+
+// gives an inquired vote back if the own request cannot complete soon,
+// having lost or given back another vote; otherwise defers the answer.
+// inquiries for votes not held, or while in the section, are ignored.
+// must be called with lock held.
+func (m *Maekawa) onInquire(from int, stamp lamport.Timestamp) []Packet {
+	if !m.requesting || m.holding || stamp != m.own || !m.votes[from] {
+		return nil
+	}
+	if len(m.failedBy) > 0 || len(m.yielded) > 0 {
+		return []Packet{m.relinquish(from)}
+	}
+	m.inquiries[from] = true
+	return nil
+}
+
+// gives a vote back.
+// must be called with lock held.
+func (m *Maekawa) relinquish(to int) Packet {
+	delete(m.votes, to)
+	delete(m.inquiries, to)
+	m.yielded[to] = true
+	return Packet{Kind: Relinquish, From: m.self, To: to, Stamp: m.own}
+}
@@ -0,0 +1,458 @@
+package mutex
+
+import (
+	"sort"
+	"sync"
+
+	lamport "github.com/simonnyman/DISY_Projects/Synchronization/lamport"
+)
+
+// kinds of protocol packets
+const (
+	Request = "request" // asks for the critical section
+	Reply   = "reply"   // acknowledges, permits or votes for a request
+	Release = "release" // Lamport, Maekawa: announces leaving the critical section
+	Grant   = "grant"   // Suzuki–Kasami: hands over the token
+
+	Inquire    = "inquire"    // Maekawa: asks a voter's holder to give the vote back
+	Relinquish = "relinquish" // Maekawa: gives a vote back
+	Failed     = "failed"     // Maekawa: a vote went to an earlier request
+)
+
+// Packet is a protocol message from one process to another.
+type Packet struct {
+	Kind  string
+	From  int
+	To    int
+	Stamp lamport.Timestamp // sender's Lamport time when sending, or for Maekawa the request concerned
+	Seq   int               // request number, Suzuki–Kasami requests only
+	Token *Token            // the token, Suzuki–Kasami grants only
+}
+
+// Token is the privilege passed around by Suzuki–Kasami.
+type Token struct {
+	Last  []int // number of each process's last granted request
+	Queue []int // processes waiting for the token, in grant order
+}
+
+// Protocol is a mutual exclusion algorithm run by one process of a fixed
+// group. packets are returned for the caller to transmit; a process never
+// sends packets to itself.
+type Protocol interface {
+	// requests the critical section. returns the packets to send and
+	// whether the process may enter right away.
+	// panics if a request is already pending or the section is held.
+	Request() ([]Packet, bool)
+	// handles a packet from another process. returns the packets to send
+	// and whether the process may now enter the critical section.
+	Receive(p Packet) ([]Packet, bool)
+	// leaves the critical section and returns the packets to send.
+	// panics if the section is not held.
+	Release() []Packet
+	// reports whether the process is in the critical section.
+	Holding() bool
+	// reports whether the process waits for the critical section.
+	Waiting() bool
+}
+
+// state every algorithm keeps about its process's request, and the lock
+// that serializes the whole algorithm
+type state struct {
+	self       int
+	n          int
+	requesting bool
+	holding    bool
+	mu         sync.Mutex
+}
+
+// panics if a request is pending or the section is held, then marks the
+// section requested. must be called with lock held.
+func (st *state) request() {
+	if st.requesting {
+		panic("mutex: critical section already requested")
+	}
+	st.requesting = true
+}
+
+// panics if the section is not held, then marks it released.
+// must be called with lock held.
+func (st *state) release() {
+	if !st.holding {
+		panic("mutex: critical section not held")
+	}
+	st.holding = false
+	st.requesting = false
+}
+
+// panics if a packet cannot come from another member of the group.
+// must be called with lock held.
+func (st *state) checkSender(p Packet) {
+	if p.From < 0 || p.From >= st.n || p.From == st.self {
+		panic("mutex: sender out of bounds")
+	}
+}
+
+// reports whether the process is in the critical section.
+func (st *state) Holding() bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.holding
+}
+
+// reports whether the process waits for the critical section.
+func (st *state) Waiting() bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.requesting && !st.holding
+}
+
+// panics if self is not in [0, n).
+func checkMember(self, n int) {
+	if self < 0 || self >= n {
+		panic("mutex: process ID out of bounds")
+	}
+}
+
+// returns copies of p from self to every other member of a group of n.
+func toOthers(p Packet, self, n int) []Packet {
+	packets := make([]Packet, 0, n-1)
+	for to := 0; to < n; to++ {
+		if to != self {
+			p.From, p.To = self, to
+			packets = append(packets, p)
+		}
+	}
+	return packets
+}
+
+// Lamport's distributed mutual exclusion algorithm
+// a process timestamps its request and sends it to all others, who queue
+// it and reply. every process keeps the requests it knows in a queue
+// ordered by (LamportTime, ProcessID). a process enters once its own
+// request heads its queue and it has received a later-stamped message
+// from every other process, and on leaving it sends a release that
+// removes the request from every queue. costs 3(n-1) messages per entry
+// and needs reliable FIFO links.
+// thread-safe for concurrent use.
+type Lamport struct {
+	state
+	clock  *lamport.LamportClock
+	queue  []lamport.Timestamp // pending requests, earliest first
+	latest []lamport.Timestamp // stamp of the last message from each process
+	own    lamport.Timestamp   // own pending request
+}
+
+// creates the algorithm for process self in a group of n.
+// panics if self is not in [0, n).
+func NewLamport(self, n int) *Lamport {
+	checkMember(self, n)
+	return &Lamport{
+		state:  state{self: self, n: n},
+		clock:  lamport.NewLamportClock(),
+		latest: make([]lamport.Timestamp, n),
+	}
+}
+
+// requests the critical section. returns the packets to send and whether
+// the process may enter right away, which only a group of one can.
+// panics if a request is already pending or the section is held.
+func (l *Lamport) Request() ([]Packet, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.request()
+	l.own = lamport.Timestamp{Time: l.clock.Send(), ProcessID: l.self}
+	l.enqueue(l.own)
+
+	packets := toOthers(Packet{Kind: Request, Stamp: l.own}, l.self, l.n)
+	return packets, l.tryEnter()
+}
+
+// handles a packet from another process. returns the packets to send and
+// whether the process may now enter the critical section.
+// panics on an unknown packet kind or sender.
+func (l *Lamport) Receive(p Packet) ([]Packet, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.checkSender(p)
+	l.clock.Receive(p.Stamp.Time)
+	l.latest[p.From] = p.Stamp
+
+	var packets []Packet
+	switch p.Kind {
+	case Request:
+		l.enqueue(p.Stamp)
+		stamp := lamport.Timestamp{Time: l.clock.Send(), ProcessID: l.self}
+		packets = []Packet{{Kind: Reply, From: l.self, To: p.From, Stamp: stamp}}
+	case Reply:
+	case Release:
+		l.dequeue(p.From)
+	default:
+		panic("mutex: unexpected packet kind " + p.Kind)
+	}
+	return packets, l.tryEnter()
+}
+
+// leaves the critical section and returns the packets to send.
+// panics if the section is not held.
+func (l *Lamport) Release() []Packet {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.release()
+	l.dequeue(l.self)
+
+	stamp := lamport.Timestamp{Time: l.clock.Send(), ProcessID: l.self}
+	return toOthers(Packet{Kind: Release, Stamp: stamp}, l.self, l.n)
+}
+
+// adds a request to the queue, keeping it sorted.
+// must be called with lock held.
+func (l *Lamport) enqueue(stamp lamport.Timestamp) {
+	i := sort.Search(len(l.queue), func(i int) bool { return stamp.Less(l.queue[i]) })
+	l.queue = append(l.queue, lamport.Timestamp{})
+	copy(l.queue[i+1:], l.queue[i:])
+	l.queue[i] = stamp
+}
+
+// removes the request of a process from the queue.
+// must be called with lock held.
+func (l *Lamport) dequeue(processID int) {
+	for i, stamp := range l.queue {
+		if stamp.ProcessID == processID {
+			l.queue = append(l.queue[:i], l.queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// enters the critical section if the own request heads the queue and
+// every other process has sent a later message. returns true if it
+// entered. must be called with lock held.
+func (l *Lamport) tryEnter() bool {
+	if !l.requesting || l.holding || l.queue[0] != l.own {
+		return false
+	}
+	for k, stamp := range l.latest {
+		if k != l.self && !l.own.Less(stamp) {
+			return false
+		}
+	}
+	l.holding = true
+	return true
+}
+
+// Ricart–Agrawala mutual exclusion
+// a process timestamps its request and sends it to all others. a process
+// replies at once unless it holds the section or has an earlier pending
+// request of its own, in which case it defers the reply until it leaves.
+// a process enters once every other process has replied. costs 2(n-1)
+// messages per entry and works over reliable links in any order.
+// thread-safe for concurrent use.
+type RicartAgrawala struct {
+	state
+	clock    *lamport.LamportClock
+	own      lamport.Timestamp // own pending request
+	replies  int               // replies to the own request
+	deferred []int             // processes whose requests wait for the release
+}
+
+// creates the algorithm for process self in a group of n.
+// panics if self is not in [0, n).
+func NewRicartAgrawala(self, n int) *RicartAgrawala {
+	checkMember(self, n)
+	return &RicartAgrawala{
+		state: state{self: self, n: n},
+		clock: lamport.NewLamportClock(),
+	}
+}
+
+// requests the critical section. returns the packets to send and whether
+// the process may enter right away, which only a group of one can.
+// panics if a request is already pending or the section is held.
+func (ra *RicartAgrawala) Request() ([]Packet, bool) {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+
+	ra.request()
+	ra.own = lamport.Timestamp{Time: ra.clock.Send(), ProcessID: ra.self}
+	ra.replies = 0
+
+	packets := toOthers(Packet{Kind: Request, Stamp: ra.own}, ra.self, ra.n)
+	return packets, ra.tryEnter()
+}
+
+// replies to or defers a request, or counts a reply.
+// panics on an unknown packet kind or sender.
+func (ra *RicartAgrawala) Receive(p Packet) ([]Packet, bool) {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+
+	ra.checkSender(p)
+	ra.clock.Receive(p.Stamp.Time)
+
+	switch p.Kind {
+	case Request:
+		if ra.holding || (ra.requesting && ra.own.Less(p.Stamp)) {
+			ra.deferred = append(ra.deferred, p.From)
+			return nil, false
+		}
+		return []Packet{ra.reply(p.From)}, false
+	case Reply:
+		ra.replies++
+		return nil, ra.tryEnter()
+	default:
+		panic("mutex: unexpected packet kind " + p.Kind)
+	}
+}
+
+// leaves the critical section and replies to the deferred requests.
+// panics if the section is not held.
+func (ra *RicartAgrawala) Release() []Packet {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+
+	ra.release()
+	packets := make([]Packet, 0, len(ra.deferred))
+	for _, to := range ra.deferred {
+		packets = append(packets, ra.reply(to))
+	}
+	ra.deferred = nil
+	return packets
+}
+
+// returns a reply to a process.
+// must be called with lock held.
+func (ra *RicartAgrawala) reply(to int) Packet {
+	stamp := lamport.Timestamp{Time: ra.clock.Send(), ProcessID: ra.self}
+	return Packet{Kind: Reply, From: ra.self, To: to, Stamp: stamp}
+}
+
+// enters the critical section once every other process replied.
+// returns true if it entered. must be called with lock held.
+func (ra *RicartAgrawala) tryEnter() bool {
+	if !ra.requesting || ra.holding || ra.replies < ra.n-1 {
+		return false
+	}
+	ra.holding = true
+	return true
+}
+
+// Suzuki–Kasami token-based mutual exclusion
+// a single token grants the section and starts at process 0. a process
+// without the token broadcasts a numbered request; the token holder
+// passes the token on when it is idle, or on leaving to the next process
+// with an outstanding request, queued in the token. costs n messages per
+// entry, or none if the process already has the token, and works over
+// reliable links in any order.
+// thread-safe for concurrent use.
+type SuzukiKasami struct {
+	state
+	requests []int  // highest request number seen from each process
+	token    *Token // nil unless this process has the token
+}
+
+// creates the algorithm for process self in a group of n. process 0 starts
+// with the token.
+// panics if self is not in [0, n).
+func NewSuzukiKasami(self, n int) *SuzukiKasami {
+	checkMember(self, n)
+	sk := &SuzukiKasami{
+		state:    state{self: self, n: n},
+		requests: make([]int, n),
+	}
+	if self == 0 {
+		sk.token = &Token{Last: make([]int, n)}
+	}
+	return sk
+}
+
+// requests the critical section. a process holding the token enters right
+// away without messages; others broadcast a request.
+// panics if a request is already pending or the section is held.
+func (sk *SuzukiKasami) Request() ([]Packet, bool) {
+	sk.mu.Lock()
+	defer sk.mu.Unlock()
+
+	sk.request()
+	if sk.token != nil {
+		sk.holding = true
+		return nil, true
+	}
+	sk.requests[sk.self]++
+	return toOthers(Packet{Kind: Request, Seq: sk.requests[sk.self]}, sk.self, sk.n), false
+}
+
+// records a request and passes on an idle token, or takes the token and
+// enters. panics on an unknown packet kind or sender, or a token arriving
+// without a pending request.
+func (sk *SuzukiKasami) Receive(p Packet) ([]Packet, bool) {
+	sk.mu.Lock()
+	defer sk.mu.Unlock()
+
+	sk.checkSender(p)
+	switch p.Kind {
+	case Request:
+		sk.requests[p.From] = max(sk.requests[p.From], p.Seq)
+		if sk.token != nil && !sk.holding && sk.outstanding(p.From) {
+			return []Packet{sk.pass(p.From)}, false
+		}
+		return nil, false
+	case Grant:
+		if !sk.requesting {
+			panic("mutex: token received without a request")
+		}
+		sk.token = p.Token
+		sk.holding = true
+		return nil, true
+	default:
+		panic("mutex: unexpected packet kind " + p.Kind)
+	}
+}
+
+// leaves the critical section, queues every process with an outstanding
+// request in the token and passes it to the first.
+// panics if the section is not held.
+func (sk *SuzukiKasami) Release() []Packet {
+	sk.mu.Lock()
+	defer sk.mu.Unlock()
+
+	sk.release()
+	sk.token.Last[sk.self] = sk.requests[sk.self]
+	for j := range sk.requests {
+		if j != sk.self && sk.outstanding(j) && !contains(sk.token.Queue, j) {
+			sk.token.Queue = append(sk.token.Queue, j)
+		}
+	}
+	if len(sk.token.Queue) == 0 {
+		return nil
+	}
+	next := sk.token.Queue[0]
+	sk.token.Queue = sk.token.Queue[1:]
+	return []Packet{sk.pass(next)}
+}
+
+// reports whether a process has a request the token has not granted yet.
+// must be called with lock held and the token present.
+func (sk *SuzukiKasami) outstanding(j int) bool {
+	return sk.requests[j] == sk.token.Last[j]+1
+}
+
+// hands the token to another process.
+// must be called with lock held and the token present.
+func (sk *SuzukiKasami) pass(to int) Packet {
+	token := sk.token
+	sk.token = nil
+	return Packet{Kind: Grant, From: sk.self, To: to, Token: token}
+}
+
+// reports whether ids contains id.
+func contains(ids []int, id int) bool {
+	for _, x := range ids {
+		if x == id {
+			return true
+		}
+	}
+	return false
+}
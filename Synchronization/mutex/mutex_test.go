@@ -0,0 +1,384 @@
+package mutex
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// constructors of every algorithm
+var algorithms = []struct {
+	name string
+	new  func(self, n int) Protocol
+	fifo bool // needs FIFO links
+}{
+	{"lamport", func(self, n int) Protocol { return NewLamport(self, n) }, true},
+	{"ricart-agrawala", func(self, n int) Protocol { return NewRicartAgrawala(self, n) }, false},
+	{"suzuki-kasami", func(self, n int) Protocol { return NewSuzukiKasami(self, n) }, false},
+	{"maekawa", func(self, n int) Protocol { return NewMaekawa(self, n) }, true},
+}
+
+// a group of processes connected by reliable links, delivering packets
+// from a random link at each step, in order unless fifo is false
+type group struct {
+	procs   []Protocol
+	links   map[[2]int][]Packet
+	entries []int // processes in the order they entered
+	sent    int
+	rng     *rand.Rand
+	fifo    bool
+}
+
+func newGroup(n int, new func(self, n int) Protocol, fifo bool, seed int64) *group {
+	g := &group{links: make(map[[2]int][]Packet), rng: rand.New(rand.NewSource(seed)), fifo: fifo}
+	for i := 0; i < n; i++ {
+		g.procs = append(g.procs, new(i, n))
+	}
+	return g
+}
+
+func (g *group) send(packets []Packet) {
+	for _, p := range packets {
+		link := [2]int{p.From, p.To}
+		g.links[link] = append(g.links[link], p)
+		g.sent++
+	}
+}
+
+func (g *group) entered(self int, ok bool, t *testing.T) {
+	if !ok {
+		return
+	}
+	for i, p := range g.procs {
+		if i != self && p.Holding() {
+			t.Fatalf("Process %d entered while %d holds the critical section", self, i)
+		}
+	}
+	g.entries = append(g.entries, self)
+}
+
+func (g *group) request(self int, t *testing.T) {
+	packets, ok := g.procs[self].Request()
+	g.send(packets)
+	g.entered(self, ok, t)
+}
+
+// delivers one packet from a random link; returns false if none is in flight.
+func (g *group) step(t *testing.T) bool {
+	var links [][2]int
+	for from := range g.procs {
+		for to := range g.procs {
+			if len(g.links[[2]int{from, to}]) > 0 {
+				links = append(links, [2]int{from, to})
+			}
+		}
+	}
+	if len(links) == 0 {
+		return false
+	}
+	link := links[g.rng.Intn(len(links))]
+	i := 0
+	if !g.fifo {
+		i = g.rng.Intn(len(g.links[link]))
+	}
+	p := g.links[link][i]
+	g.links[link] = append(g.links[link][:i:i], g.links[link][i+1:]...)
+
+	packets, ok := g.procs[p.To].Receive(p)
+	g.send(packets)
+	g.entered(p.To, ok, t)
+	return true
+}
+
+// delivers packets until none is in flight.
+func (g *group) settle(t *testing.T) {
+	for g.step(t) {
+	}
+}
+
+// Basic functionality tests
+
+// verifies a single process enters at once with every algorithm.
+func TestSingleProcess(t *testing.T) {
+	for _, alg := range algorithms {
+		p := alg.new(0, 1)
+		packets, ok := p.Request()
+		if len(packets) != 0 || !ok || !p.Holding() {
+			t.Fatalf("%s: expected immediate entry, got %v and %v", alg.name, packets, ok)
+		}
+		if packets := p.Release(); len(packets) != 0 || p.Holding() || p.Waiting() {
+			t.Errorf("%s: expected to leave without messages, got %v", alg.name, packets)
+		}
+	}
+}
+
+// verifies a Lamport request waits for a reply from every other process.
+func TestLamportWaitsForReplies(t *testing.T) {
+	g := newGroup(3, algorithms[0].new, true, 1)
+	g.request(0, t)
+	if !g.procs[0].Waiting() {
+		t.Fatal("Requester should wait")
+	}
+
+	packets, _ := g.procs[1].Receive(g.links[[2]int{0, 1}][0])
+	if len(packets) != 1 || packets[0].Kind != Reply || packets[0].To != 0 {
+		t.Fatalf("Expected a reply to 0, got %v", packets)
+	}
+	if _, ok := g.procs[0].Receive(packets[0]); ok {
+		t.Error("Requester should still wait for process 2")
+	}
+
+	packets, _ = g.procs[2].Receive(g.links[[2]int{0, 2}][0])
+	if _, ok := g.procs[0].Receive(packets[0]); !ok {
+		t.Error("Requester should enter after both replies")
+	}
+}
+
+// verifies the earlier request wins and ties go to the smaller process ID
+// with both timestamp-based algorithms.
+func TestTimestampPriority(t *testing.T) {
+	for _, alg := range algorithms[:2] {
+		g := newGroup(2, alg.new, true, 1)
+		g.request(1, t)
+		g.request(0, t) // same Lamport time, smaller process ID
+		g.settle(t)
+		if len(g.entries) != 1 || g.entries[0] != 0 {
+			t.Fatalf("%s: expected process 0 to enter first, got %v", alg.name, g.entries)
+		}
+
+		g.send(g.procs[0].Release())
+		g.settle(t)
+		if len(g.entries) != 2 || g.entries[1] != 1 {
+			t.Errorf("%s: expected process 1 to enter after the release, got %v", alg.name, g.entries)
+		}
+	}
+}
+
+// verifies Ricart–Agrawala defers its reply while holding the section and
+// sends it on release.
+func TestRicartAgrawalaDefers(t *testing.T) {
+	ra := NewRicartAgrawala(0, 2)
+	ra.Request()
+	if _, ok := ra.Receive(Packet{Kind: Reply, From: 1}); !ok {
+		t.Fatal("Expected to enter after the only reply")
+	}
+
+	packets, _ := ra.Receive(Packet{Kind: Request, From: 1, Stamp: ra.own})
+	if len(packets) != 0 {
+		t.Fatalf("Reply should be deferred while holding, got %v", packets)
+	}
+	if packets := ra.Release(); len(packets) != 1 || packets[0].Kind != Reply || packets[0].To != 1 {
+		t.Errorf("Expected the deferred reply on release, got %v", packets)
+	}
+}
+
+// verifies Suzuki–Kasami enters without messages while holding the token
+// and hands the token to a waiting process on release.
+func TestSuzukiKasamiToken(t *testing.T) {
+	g := newGroup(3, algorithms[2].new, false, 1)
+	g.request(0, t)
+	if g.sent != 0 || len(g.entries) != 1 {
+		t.Fatalf("Token holder should enter without messages, sent %d", g.sent)
+	}
+
+	g.request(2, t)
+	g.settle(t)
+	if g.sent != 2 || len(g.entries) != 1 {
+		t.Fatalf("Request should reach both others and wait for the token, sent %d", g.sent)
+	}
+
+	packets := g.procs[0].Release()
+	if len(packets) != 1 || packets[0].Kind != Grant || packets[0].To != 2 {
+		t.Fatalf("Expected the token to go to process 2, got %v", packets)
+	}
+	g.send(packets)
+	g.settle(t)
+	if len(g.entries) != 2 || g.entries[1] != 2 {
+		t.Errorf("Expected process 2 to enter with the token, got %v", g.entries)
+	}
+}
+
+// verifies grid quorums contain their process, stay below 2√n members and
+// intersect pairwise, also for incomplete grids.
+func TestGridQuorum(t *testing.T) {
+	for n := 1; n <= 30; n++ {
+		quorums := make([]map[int]bool, n)
+		for i := 0; i < n; i++ {
+			quorums[i] = make(map[int]bool)
+			for _, id := range GridQuorum(i, n) {
+				quorums[i][id] = true
+			}
+			if !quorums[i][i] {
+				t.Fatalf("n=%d: quorum of %d misses itself", n, i)
+			}
+			if len(quorums[i])*len(quorums[i]) >= 4*n && n > 1 {
+				t.Errorf("n=%d: quorum of %d has %d members", n, i, len(quorums[i]))
+			}
+		}
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				shared := false
+				for id := range quorums[i] {
+					shared = shared || quorums[j][id]
+				}
+				if !shared {
+					t.Fatalf("n=%d: quorums of %d and %d are disjoint", n, i, j)
+				}
+			}
+		}
+	}
+
+	expected := []int{1, 3, 4, 5, 7}
+	if got := GridQuorum(4, 9); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected the middle row and column %v, got %v", expected, got)
+	}
+}
+
+// verifies two requests that each hold their own vote deadlock without
+// resolution, and that INQUIRE, RELINQUISH and FAILED resolve it.
+func TestMaekawaDeadlock(t *testing.T) {
+	// in a group of 3, process 0 needs {0, 1, 2} and process 1 needs {0, 1}
+	run := func(new func(self, n int) Protocol) *group {
+		g := newGroup(3, new, true, 1)
+		g.request(0, t) // votes for itself
+		g.request(1, t) // votes for itself
+		g.settle(t)
+		return g
+	}
+
+	g := run(func(self, n int) Protocol { return NewNaiveMaekawa(self, n) })
+	if len(g.entries) != 0 {
+		t.Fatalf("Expected a deadlock, got entries %v", g.entries)
+	}
+	for i, waiting := range []int{1, 0} {
+		if voter := g.procs[waiting].(Voter); voter.VotedFor() != waiting {
+			t.Errorf("Process %d should keep its vote, voted for %d", waiting, voter.VotedFor())
+		}
+		if votes := g.procs[i].(Voter).Votes(); len(votes) == len(g.procs[i].(Voter).Quorum()) {
+			t.Errorf("Process %d should miss a vote, has %v", i, votes)
+		}
+	}
+
+	g = run(func(self, n int) Protocol { return NewMaekawa(self, n) })
+	if len(g.entries) != 1 || g.entries[0] != 0 {
+		t.Fatalf("Expected the earlier request of process 0 to enter, got %v", g.entries)
+	}
+	g.send(g.procs[0].Release())
+	g.settle(t)
+	if len(g.entries) != 2 {
+		t.Errorf("Expected process 1 to enter after the release, got %v", g.entries)
+	}
+}
+
+// verifies misuse and unknown packets panic.
+func TestPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"member out of bounds", func() { NewLamport(2, 2) }},
+		{"negative member", func() { NewSuzukiKasami(-1, 2) }},
+		{"request twice", func() {
+			l := NewLamport(0, 2)
+			l.Request()
+			l.Request()
+		}},
+		{"release not held", func() { NewRicartAgrawala(0, 2).Release() }},
+		{"lamport unknown kind", func() { NewLamport(0, 2).Receive(Packet{Kind: Grant, From: 1}) }},
+		{"ricart-agrawala unknown kind", func() { NewRicartAgrawala(0, 2).Receive(Packet{Kind: Release, From: 1}) }},
+		{"suzuki-kasami unknown kind", func() { NewSuzukiKasami(0, 2).Receive(Packet{Kind: Reply, From: 1}) }},
+		{"token without request", func() { NewSuzukiKasami(1, 2).Receive(Packet{Kind: Grant, From: 0}) }},
+		{"maekawa unknown kind", func() { NewMaekawa(0, 2).Receive(Packet{Kind: Grant, From: 1}) }},
+		{"packet from self", func() { NewLamport(0, 2).Receive(Packet{Kind: Reply, From: 0}) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic")
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
+
+// Scenario-based tests
+
+// verifies at most one process holds the section at a time and every
+// request is granted when requests and packets interleave at random.
+func TestMutualExclusion(t *testing.T) {
+	for _, alg := range algorithms {
+		t.Run(alg.name, func(t *testing.T) {
+			for seed := int64(1); seed <= 20; seed++ {
+				const n, rounds = 5, 200
+				g := newGroup(n, alg.new, alg.fifo, seed)
+				requests := 0
+				for i := 0; i < rounds; i++ {
+					self := g.rng.Intn(n)
+					switch p := g.procs[self]; {
+					case p.Holding():
+						g.send(p.Release())
+					case !p.Waiting():
+						g.request(self, t)
+						requests++
+					default:
+						g.step(t)
+					}
+				}
+				for {
+					for _, p := range g.procs {
+						if p.Holding() {
+							g.send(p.Release())
+						}
+					}
+					if !g.step(t) {
+						break
+					}
+				}
+
+				if len(g.entries) != requests {
+					t.Fatalf("Seed %d: %d of %d requests granted", seed, len(g.entries), requests)
+				}
+			}
+		})
+	}
+}
+
+// verifies the message cost per entry under contention: 3(n-1) for
+// Lamport, 2(n-1) for Ricart–Agrawala, at most n for Suzuki–Kasami and at
+// most 5 per other quorum member for Maekawa.
+func TestMessagesPerEntry(t *testing.T) {
+	const n = 5 // quorums of at most 5 in a 3-wide grid
+	bounds := map[string]struct {
+		perEntry int
+		exact    bool
+	}{
+		"lamport":         {3 * (n - 1), true},
+		"ricart-agrawala": {2 * (n - 1), true},
+		"suzuki-kasami":   {n, false},
+		"maekawa":         {5 * 4, false},
+	}
+
+	for _, alg := range algorithms {
+		g := newGroup(n, alg.new, alg.fifo, 3)
+		for i := 0; i < n; i++ {
+			g.request(i, t)
+		}
+		for len(g.entries) < n {
+			g.settle(t)
+			for _, p := range g.procs {
+				if p.Holding() {
+					g.send(p.Release())
+				}
+			}
+		}
+		g.settle(t)
+
+		bound := bounds[alg.name]
+		if expected := n * bound.perEntry; g.sent > expected || (bound.exact && g.sent != expected) {
+			t.Errorf("%s: expected %d messages (exact: %v), got %d", alg.name, expected, bound.exact, g.sent)
+		}
+	}
+}
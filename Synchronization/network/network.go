@@ -0,0 +1,237 @@
+package network
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Network decides when the copies of a message arrive.
+// implementations must be thread-safe for concurrent use.
+type Network interface {
+	// returns the arrival times of the copies of a message sent from one
+	// process to another at time now; no times if the message is lost.
+	Transmit(from, to int, now time.Duration) []time.Duration
+}
+
+// Latency is a distribution of message delays.
+type Latency interface {
+	// draws a delay.
+	Sample(rng *rand.Rand) time.Duration
+	// returns the mean delay.
+	Mean() time.Duration
+}
+
+// Link describes how a directed link between two processes behaves.
+// messages on a link arrive in the order they were sent unless they are
+// reordered.
+type Link struct {
+	Latency      Latency       // delay of each message
+	Drop         float64       // probability that a message is lost
+	Duplicate    float64       // probability that a message arrives twice
+	Reorder      float64       // probability that a message is held back and may be overtaken
+	ReorderDelay time.Duration // extra delay of a reordered message
+}
+
+// link between two processes
+type linkKey struct{ from, to int }
+
+// network model with a default link and per-link overrides
+// every message draws its latency from its link's distribution; in-order
+// messages are additionally held until the previous in-order message on
+// the link has arrived. duplicates draw their own latency and ignore order.
+// thread-safe for concurrent use.
+type Model struct {
+	defaultLink Link
+	links       map[linkKey]Link
+	last        map[linkKey]time.Duration // arrival of the last in-order message per link
+	rng         *rand.Rand
+	sent        int
+	dropped     int
+	duplicated  int
+	reordered   int
+	mu          sync.Mutex
+}
+
+// creates a network model where every link behaves like link.
+// random decisions are drawn from a generator seeded with seed.
+// panics if link is invalid.
+func NewModel(link Link, seed int64) *Model {
+	checkLink(link)
+	return &Model{
+		defaultLink: link,
+		links:       make(map[linkKey]Link),
+		last:        make(map[linkKey]time.Duration),
+		rng:         rand.New(rand.NewSource(seed)),
+	}
+}
+
+// creates a network model that delivers every message once, in order,
+// after the given delay.
+func Reliable(delay time.Duration) *Model {
+	return NewModel(Link{Latency: Constant(delay)}, 0)
+}
+
+// overrides the behaviour of the link from one process to another.
+// panics if link is invalid.
+func (m *Model) SetLink(from, to int, link Link) {
+	checkLink(link)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.links[linkKey{from, to}] = link
+}
+
+// returns the behaviour of the link from one process to another.
+func (m *Model) Link(from, to int) Link {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.link(linkKey{from, to})
+}
+
+// returns the arrival times of the copies of a message sent at time now.
+func (m *Model) Transmit(from, to int, now time.Duration) []time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := linkKey{from, to}
+	link := m.link(key)
+	m.sent++
+
+	if m.rng.Float64() < link.Drop {
+		m.dropped++
+		return nil
+	}
+
+	arrival := now + link.Latency.Sample(m.rng)
+	if m.rng.Float64() < link.Reorder {
+		m.reordered++
+		arrival += link.ReorderDelay
+	} else {
+		arrival = max(arrival, m.last[key])
+		m.last[key] = arrival
+	}
+	arrivals := []time.Duration{arrival}
+
+	if m.rng.Float64() < link.Duplicate {
+		m.duplicated++
+		arrivals = append(arrivals, now+link.Latency.Sample(m.rng))
+	}
+	return arrivals
+}
+
+// returns how many messages the model transmitted, dropped, duplicated
+// and reordered.
+func (m *Model) Statistics() map[string]interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return map[string]interface{}{
+		"sent":       m.sent,
+		"dropped":    m.dropped,
+		"duplicated": m.duplicated,
+		"reordered":  m.reordered,
+	}
+}
+
+// returns the behaviour of a link.
+// must be called with lock held.
+func (m *Model) link(key linkKey) Link {
+	if link, ok := m.links[key]; ok {
+		return link
+	}
+	return m.defaultLink
+}
+
+// panics if a link has no latency, a probability outside [0, 1] or a
+// negative reorder delay.
+func checkLink(link Link) {
+	if link.Latency == nil {
+		panic("network: link latency must not be nil")
+	}
+	for _, p := range []float64{link.Drop, link.Duplicate, link.Reorder} {
+		if p < 0 || p > 1 {
+			panic("network: probabilities must be between 0 and 1")
+		}
+	}
+	if link.ReorderDelay < 0 {
+		panic("network: reorder delay must not be negative")
+	}
+}
+
+// constant delay
+type constant struct{ d time.Duration }
+
+// returns a latency that is always d.
+// panics if d is negative.
+func Constant(d time.Duration) Latency {
+	if d < 0 {
+		panic("network: latency must not be negative")
+	}
+	return constant{d}
+}
+
+func (c constant) Sample(rng *rand.Rand) time.Duration { return c.d }
+
+func (c constant) Mean() time.Duration { return c.d }
+
+// delay uniformly distributed in [lo, hi]
+type uniform struct{ lo, hi time.Duration }
+
+// returns a latency drawn uniformly from [lo, hi].
+// panics if lo is negative or hi is less than lo.
+func Uniform(lo, hi time.Duration) Latency {
+	if lo < 0 || hi < lo {
+		panic("network: uniform latency needs 0 <= lo <= hi")
+	}
+	return uniform{lo, hi}
+}
+
+func (u uniform) Sample(rng *rand.Rand) time.Duration {
+	return u.lo + time.Duration(rng.Int63n(int64(u.hi-u.lo)+1))
+}
+
+func (u uniform) Mean() time.Duration { return (u.lo + u.hi) / 2 }
+
+// exponentially distributed delay
+type exponential struct{ mean time.Duration }
+
+// returns an exponentially distributed latency with the given mean,
+// the delay of a memoryless queue.
+// panics if mean is negative.
+func Exponential(mean time.Duration) Latency {
+	if mean < 0 {
+		panic("network: latency must not be negative")
+	}
+	return exponential{mean}
+}
+
+func (e exponential) Sample(rng *rand.Rand) time.Duration {
+	return time.Duration(rng.ExpFloat64() * float64(e.mean))
+}
+
+func (e exponential) Mean() time.Duration { return e.mean }
+
+// log-normally distributed delay
+type logNormal struct {
+	median time.Duration
+	sigma  float64
+}
+
+// returns a log-normally distributed latency with the given median and
+// shape sigma, the heavy-tailed delay measured on real networks.
+// panics if median or sigma is negative.
+func LogNormal(median time.Duration, sigma float64) Latency {
+	if median < 0 || sigma < 0 {
+		panic("network: log-normal latency needs non-negative median and sigma")
+	}
+	return logNormal{median, sigma}
+}
+
+func (l logNormal) Sample(rng *rand.Rand) time.Duration {
+	return time.Duration(float64(l.median) * math.Exp(l.sigma*rng.NormFloat64()))
+}
+
+func (l logNormal) Mean() time.Duration {
+	return time.Duration(float64(l.median) * math.Exp(l.sigma*l.sigma/2))
+}
@@ -0,0 +1,178 @@
+package network
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Basic functionality tests
+
+// verifies a reliable model delivers once after the constant delay.
+func TestReliable(t *testing.T) {
+	m := Reliable(2 * time.Millisecond)
+
+	got := m.Transmit(0, 1, 10*time.Millisecond)
+	expected := []time.Duration{12 * time.Millisecond}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+// verifies each distribution stays in range and has roughly its mean.
+func TestLatencyDistributions(t *testing.T) {
+	tests := []struct {
+		name    string
+		latency Latency
+		lo, hi  time.Duration
+	}{
+		{"constant", Constant(time.Millisecond), time.Millisecond, time.Millisecond},
+		{"uniform", Uniform(time.Millisecond, 3*time.Millisecond), time.Millisecond, 3 * time.Millisecond},
+		{"exponential", Exponential(time.Millisecond), 0, time.Hour},
+		{"log-normal", LogNormal(time.Millisecond, 0.5), 0, time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			const samples = 20000
+			var total time.Duration
+			for i := 0; i < samples; i++ {
+				d := tt.latency.Sample(rng)
+				if d < tt.lo || d > tt.hi {
+					t.Fatalf("Sample %v outside [%v, %v]", d, tt.lo, tt.hi)
+				}
+				total += d
+			}
+
+			mean := float64(total / samples)
+			want := float64(tt.latency.Mean())
+			if mean < 0.95*want || mean > 1.05*want {
+				t.Errorf("Sample mean %v, expected about %v", time.Duration(mean), tt.latency.Mean())
+			}
+		})
+	}
+}
+
+// verifies a link that drops everything delivers nothing.
+func TestDrop(t *testing.T) {
+	m := NewModel(Link{Latency: Constant(0), Drop: 1}, 1)
+
+	for i := 0; i < 10; i++ {
+		if got := m.Transmit(0, 1, 0); len(got) != 0 {
+			t.Fatalf("Expected message to be dropped, got %v", got)
+		}
+	}
+	if m.Statistics()["dropped"].(int) != 10 {
+		t.Errorf("Expected 10 drops, got %v", m.Statistics()["dropped"])
+	}
+}
+
+// verifies a link that duplicates everything delivers two copies.
+func TestDuplicate(t *testing.T) {
+	m := NewModel(Link{Latency: Constant(time.Millisecond), Duplicate: 1}, 1)
+
+	if got := m.Transmit(0, 1, 0); len(got) != 2 {
+		t.Errorf("Expected 2 copies, got %v", got)
+	}
+	if m.Statistics()["duplicated"].(int) != 1 {
+		t.Errorf("Expected 1 duplicate, got %v", m.Statistics()["duplicated"])
+	}
+}
+
+// verifies per-link overrides only affect their link.
+func TestSetLink(t *testing.T) {
+	m := Reliable(time.Millisecond)
+	m.SetLink(0, 1, Link{Latency: Constant(time.Millisecond), Drop: 1})
+
+	if got := m.Transmit(0, 1, 0); len(got) != 0 {
+		t.Errorf("Overridden link should drop, got %v", got)
+	}
+	if got := m.Transmit(1, 0, 0); len(got) != 1 {
+		t.Errorf("Reverse link should deliver, got %v", got)
+	}
+	if m.Link(0, 1).Drop != 1 || m.Link(1, 0).Drop != 0 {
+		t.Error("Link should return the override and the default")
+	}
+}
+
+// verifies invalid links and latencies panic.
+func TestInvalidPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"nil latency", func() { NewModel(Link{}, 1) }},
+		{"drop above 1", func() { NewModel(Link{Latency: Constant(0), Drop: 1.5}, 1) }},
+		{"negative reorder", func() { NewModel(Link{Latency: Constant(0), Reorder: -0.1}, 1) }},
+		{"negative reorder delay", func() { NewModel(Link{Latency: Constant(0), ReorderDelay: -1}, 1) }},
+		{"negative constant", func() { Constant(-1) }},
+		{"uniform bounds", func() { Uniform(2, 1) }},
+		{"negative exponential", func() { Exponential(-1) }},
+		{"negative sigma", func() { LogNormal(1, -1) }},
+		{"invalid override", func() { Reliable(0).SetLink(0, 1, Link{}) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic")
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
+
+// Scenario-based tests
+
+// verifies messages on a link arrive in send order even when their
+// latencies vary, while different links are independent.
+func TestFIFOWithVariableLatency(t *testing.T) {
+	m := NewModel(Link{Latency: Exponential(5 * time.Millisecond)}, 3)
+
+	var last time.Duration
+	for i := 0; i < 200; i++ {
+		now := time.Duration(i) * time.Millisecond
+		arrival := m.Transmit(0, 1, now)[0]
+		if arrival < last {
+			t.Fatalf("Message %d arrived at %v before previous at %v", i, arrival, last)
+		}
+		if arrival < now {
+			t.Fatalf("Message %d arrived at %v before it was sent at %v", i, arrival, now)
+		}
+		last = arrival
+	}
+}
+
+// verifies reordered messages are held back and overtaken.
+func TestReorder(t *testing.T) {
+	m := NewModel(Link{Latency: Constant(time.Millisecond), Reorder: 1, ReorderDelay: 10 * time.Millisecond}, 1)
+	m.SetLink(0, 2, Link{Latency: Constant(time.Millisecond)})
+
+	held := m.Transmit(0, 1, 0)[0]
+	m.SetLink(0, 1, Link{Latency: Constant(time.Millisecond)})
+	next := m.Transmit(0, 1, time.Millisecond)[0]
+
+	if next >= held {
+		t.Errorf("Later message at %v should overtake held one at %v", next, held)
+	}
+	if m.Statistics()["reordered"].(int) != 1 {
+		t.Errorf("Expected 1 reordered message, got %v", m.Statistics()["reordered"])
+	}
+}
+
+// verifies the same seed gives the same deliveries.
+func TestModelDeterministic(t *testing.T) {
+	link := Link{Latency: LogNormal(time.Millisecond, 1), Drop: 0.2, Duplicate: 0.2, Reorder: 0.2, ReorderDelay: time.Millisecond}
+	m1, m2 := NewModel(link, 7), NewModel(link, 7)
+
+	for i := 0; i < 100; i++ {
+		now := time.Duration(i) * time.Millisecond
+		if a, b := m1.Transmit(i%3, 3, now), m2.Transmit(i%3, 3, now); !reflect.DeepEqual(a, b) {
+			t.Fatalf("Message %d: %v vs %v", i, a, b)
+		}
+	}
+}
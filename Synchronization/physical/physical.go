@@ -0,0 +1,162 @@
+package physical
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// simulated physical clock
+// reads a reference source of true time and distorts it the way a quartz
+// oscillator strays: an initial offset, a constant drift rate and random
+// jitter on every reading. synchronization algorithms correct it by
+// stepping the offset with Adjust.
+// thread-safe for concurrent use.
+type Clock struct {
+	reference func() int64 // true time in nanoseconds
+	start     int64        // reference time when the clock was created
+	drift     float64      // rate error, e.g. 1e-4 gains 100µs per second
+	offset    int64        // initial offset plus all adjustments, in nanoseconds
+	jitter    int64        // largest error of a single reading, in nanoseconds
+	rng       *rand.Rand
+	mu        sync.Mutex
+}
+
+// creates a new clock that reads true time from reference.
+// jitter readings are drawn from a generator seeded with seed.
+// panics if reference is nil, drift is -1 or less, or jitter is negative.
+func NewClock(reference func() int64, drift float64, offset, jitter time.Duration, seed int64) *Clock {
+	if reference == nil {
+		panic("physical: reference must not be nil")
+	}
+	if drift <= -1 {
+		panic("physical: drift must be greater than -1")
+	}
+	if jitter < 0 {
+		panic("physical: jitter must not be negative")
+	}
+	return &Clock{
+		reference: reference,
+		start:     reference(),
+		drift:     drift,
+		offset:    int64(offset),
+		jitter:    int64(jitter),
+		rng:       rand.New(rand.NewSource(seed)),
+	}
+}
+
+// creates a clock that always reads the host wall clock exactly.
+func NewPerfectClock() *Clock {
+	return NewClock(HostTime, 0, 0, 0, 0)
+}
+
+// returns the host wall clock in nanoseconds.
+func HostTime() int64 {
+	return time.Now().UnixNano()
+}
+
+// returns the current reading of the clock in nanoseconds.
+func (c *Clock) Now() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.reference()
+	reading := now + c.errorAt(now)
+	if c.jitter > 0 {
+		reading += c.rng.Int63n(2*c.jitter+1) - c.jitter
+	}
+	return reading
+}
+
+// returns how far the clock is ahead of true time, ignoring jitter.
+// negative values mean the clock is behind.
+func (c *Clock) Error() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.errorAt(c.reference())
+}
+
+// steps the clock forward by delta nanoseconds, or back if delta is negative.
+func (c *Clock) Adjust(delta int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.offset += delta
+}
+
+// returns the drift rate of the clock.
+func (c *Clock) Drift() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.drift
+}
+
+// returns the error at reference time now.
+// must be called with lock held.
+func (c *Clock) errorAt(now int64) int64 {
+	return c.offset + int64(float64(now-c.start)*c.drift)
+}
+
+// returns the adjustment Cristian's algorithm applies to a client that sent
+// its request at local time requestTime and received the server's time
+// serverTime at local time replyTime. the server's reading is assumed to be
+// taken halfway through the round trip.
+func CristianAdjustment(requestTime, serverTime, replyTime int64) int64 {
+	return serverTime + (replyTime-requestTime)/2 - replyTime
+}
+
+// returns how far a polled clock is ahead of the master in the Berkeley
+// algorithm: the master sent the poll at its local time pollTime, the slave
+// answered with slaveTime and the answer arrived at the master's local time
+// replyTime.
+func BerkeleyOffset(pollTime, slaveTime, replyTime int64) int64 {
+	return CristianAdjustment(pollTime, slaveTime, replyTime)
+}
+
+// returns the adjustment of each clock in the Berkeley algorithm, given how
+// far each clock is ahead of the master (the master's own offset is 0).
+// offsets further than tolerance from the master are left out of the
+// average but still adjusted; a tolerance of 0 averages all clocks.
+// panics if offsets is empty or tolerance is negative.
+func BerkeleyAdjustments(offsets []int64, tolerance int64) []int64 {
+	if len(offsets) == 0 {
+		panic("physical: offsets must not be empty")
+	}
+	if tolerance < 0 {
+		panic("physical: tolerance must not be negative")
+	}
+
+	var sum int64
+	count := 0
+	for _, o := range offsets {
+		if tolerance == 0 || (o <= tolerance && o >= -tolerance) {
+			sum += o
+			count++
+		}
+	}
+
+	var avg int64
+	if count > 0 {
+		avg = sum / int64(count)
+	}
+
+	adjustments := make([]int64, len(offsets))
+	for i, o := range offsets {
+		adjustments[i] = avg - o
+	}
+	return adjustments
+}
+
+// returns the largest difference between any two values, e.g. the skew
+// between clock errors sampled at the same instant.
+// returns 0 for fewer than two values.
+func Spread(values []int64) int64 {
+	if len(values) < 2 {
+		return 0
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values[1:] {
+		lo = min(lo, v)
+		hi = max(hi, v)
+	}
+	return hi - lo
+}
@@ -0,0 +1,229 @@
+package physical
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// returns a reference source that reads *now.
+func fakeTime(now *int64) func() int64 {
+	return func() int64 { return *now }
+}
+
+// Basic functionality tests
+
+// verifies a clock without drift, offset or jitter reads true time.
+func TestPerfectReading(t *testing.T) {
+	now := int64(1000)
+	c := NewClock(fakeTime(&now), 0, 0, 0, 1)
+
+	now = 5000
+	if got := c.Now(); got != 5000 {
+		t.Errorf("Expected 5000, got %d", got)
+	}
+	if c.Error() != 0 {
+		t.Errorf("Expected no error, got %d", c.Error())
+	}
+}
+
+// verifies the initial offset is added to every reading.
+func TestOffset(t *testing.T) {
+	now := int64(0)
+	c := NewClock(fakeTime(&now), 0, 3*time.Millisecond, 0, 1)
+
+	now = int64(time.Second)
+	if got := c.Now(); got != int64(time.Second+3*time.Millisecond) {
+		t.Errorf("Expected 1.003s, got %d", got)
+	}
+}
+
+// verifies drift accumulates with elapsed true time.
+func TestDrift(t *testing.T) {
+	now := int64(0)
+	c := NewClock(fakeTime(&now), 1e-3, 0, 0, 1)
+
+	now = int64(time.Second)
+	if got := c.Error(); got != int64(time.Millisecond) {
+		t.Errorf("Expected 1ms gained after 1s, got %d", got)
+	}
+
+	slow := NewClock(fakeTime(&now), -1e-3, 0, 0, 1)
+	now += int64(2 * time.Second)
+	if got := slow.Error(); got != -int64(2*time.Millisecond) {
+		t.Errorf("Expected 2ms lost after 2s, got %d", got)
+	}
+}
+
+// verifies jitter stays within its bound and does not affect Error.
+func TestJitter(t *testing.T) {
+	now := int64(0)
+	jitter := 50 * time.Microsecond
+	c := NewClock(fakeTime(&now), 0, 0, jitter, 7)
+
+	varied := false
+	for i := 0; i < 100; i++ {
+		got := c.Now()
+		if got < -int64(jitter) || got > int64(jitter) {
+			t.Fatalf("Reading %d outside jitter bound %d", got, jitter)
+		}
+		if got != 0 {
+			varied = true
+		}
+	}
+	if !varied {
+		t.Error("Expected jitter to vary readings")
+	}
+	if c.Error() != 0 {
+		t.Errorf("Error should ignore jitter, got %d", c.Error())
+	}
+}
+
+// verifies Adjust steps the clock.
+func TestAdjust(t *testing.T) {
+	now := int64(0)
+	c := NewClock(fakeTime(&now), 0, time.Millisecond, 0, 1)
+
+	c.Adjust(-int64(time.Millisecond))
+	if c.Error() != 0 {
+		t.Errorf("Expected error 0 after adjust, got %d", c.Error())
+	}
+}
+
+// verifies the same seed gives the same jitter sequence.
+func TestJitterDeterministic(t *testing.T) {
+	now := int64(0)
+	c1 := NewClock(fakeTime(&now), 0, 0, time.Millisecond, 42)
+	c2 := NewClock(fakeTime(&now), 0, 0, time.Millisecond, 42)
+
+	for i := 0; i < 10; i++ {
+		if r1, r2 := c1.Now(), c2.Now(); r1 != r2 {
+			t.Fatalf("Reading %d differs: %d vs %d", i, r1, r2)
+		}
+	}
+}
+
+// verifies invalid parameters panic.
+func TestNewClockPanics(t *testing.T) {
+	now := int64(0)
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"nil reference", func() { NewClock(nil, 0, 0, 0, 1) }},
+		{"drift -1", func() { NewClock(fakeTime(&now), -1, 0, 0, 1) }},
+		{"negative jitter", func() { NewClock(fakeTime(&now), 0, 0, -1, 1) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic")
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
+
+// verifies Cristian's adjustment assumes a symmetric round trip.
+func TestCristianAdjustment(t *testing.T) {
+	// request at 100, server read 200 halfway, reply at 120: true time is 210
+	if got := CristianAdjustment(100, 200, 120); got != 90 {
+		t.Errorf("Expected adjustment 90, got %d", got)
+	}
+	if got := CristianAdjustment(100, 105, 110); got != 0 {
+		t.Errorf("Synchronized clocks should need no adjustment, got %d", got)
+	}
+}
+
+// verifies Berkeley adjustments move every clock to the average.
+func TestBerkeleyAdjustments(t *testing.T) {
+	got := BerkeleyAdjustments([]int64{0, 30, -60}, 0)
+	expected := []int64{-10, -40, 50}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+// verifies outliers are left out of the average but still corrected.
+func TestBerkeleyTolerance(t *testing.T) {
+	got := BerkeleyAdjustments([]int64{0, 20, 1000}, 100)
+	expected := []int64{10, -10, -990}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+// verifies spread returns max minus min.
+func TestSpread(t *testing.T) {
+	if got := Spread([]int64{3, -2, 7}); got != 9 {
+		t.Errorf("Expected 9, got %d", got)
+	}
+	if got := Spread([]int64{5}); got != 0 {
+		t.Errorf("Expected 0 for a single value, got %d", got)
+	}
+}
+
+// Scenario-based tests
+
+// verifies Cristian's algorithm removes the offset of a drifting clock
+// up to half the round trip.
+func TestCristianSynchronizes(t *testing.T) {
+	now := int64(0)
+	server := NewClock(fakeTime(&now), 0, 0, 0, 1)
+	client := NewClock(fakeTime(&now), 1e-3, 20*time.Millisecond, 0, 2)
+
+	now = int64(10 * time.Second)
+	if client.Error() < int64(20*time.Millisecond) {
+		t.Fatalf("Client should have drifted ahead, error %d", client.Error())
+	}
+
+	// 2ms there, 6ms back: asymmetric delay adds a 2ms error
+	t0 := client.Now()
+	now += int64(2 * time.Millisecond)
+	ts := server.Now()
+	now += int64(6 * time.Millisecond)
+	t1 := client.Now()
+	client.Adjust(CristianAdjustment(t0, ts, t1))
+
+	rtt := t1 - t0
+	if err := client.Error() - server.Error(); err < -rtt/2 || err > rtt/2 {
+		t.Errorf("Residual error %d exceeds half the round trip %d", err, rtt/2)
+	}
+}
+
+// verifies the Berkeley algorithm brings three clocks to a common time.
+func TestBerkeleySynchronizes(t *testing.T) {
+	now := int64(0)
+	clocks := []*Clock{
+		NewClock(fakeTime(&now), 0, 0, 0, 1),
+		NewClock(fakeTime(&now), 0, 40*time.Millisecond, 0, 2),
+		NewClock(fakeTime(&now), 0, -10*time.Millisecond, 0, 3),
+	}
+
+	offsets := []int64{0}
+	for _, c := range clocks[1:] {
+		t0 := clocks[0].Now()
+		ts := c.Now()
+		t1 := clocks[0].Now()
+		offsets = append(offsets, BerkeleyOffset(t0, ts, t1))
+	}
+	for i, adj := range BerkeleyAdjustments(offsets, 0) {
+		clocks[i].Adjust(adj)
+	}
+
+	errors := make([]int64, len(clocks))
+	for i, c := range clocks {
+		errors[i] = c.Error()
+	}
+	if Spread(errors) > 1 {
+		t.Errorf("Expected clocks to agree, errors %v", errors)
+	}
+	if errors[0] != int64(10*time.Millisecond) {
+		t.Errorf("Expected common time 10ms ahead, got %d", errors[0])
+	}
+}
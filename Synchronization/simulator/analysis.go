@@ -1,6 +1,12 @@
 package simulator
 
-import vector "github.com/simonnyman/DISY_Projects/Synchronization/vector"
+import (
+	bloom "github.com/simonnyman/DISY_Projects/Synchronization/bloom"
+	clock "github.com/simonnyman/DISY_Projects/Synchronization/clock"
+	hlc "github.com/simonnyman/DISY_Projects/Synchronization/hlc"
+	matrix "github.com/simonnyman/DISY_Projects/Synchronization/matrix"
+	vector "github.com/simonnyman/DISY_Projects/Synchronization/vector"
+)
 
 // returns detailed statistics for each process
 func (s *Simulator) GetProcessStatistics() []map[string]interface{} {
@@ -57,20 +63,127 @@ func (s *Simulator) GetStatistics() map[string]interface{} {
 	}
 }
 
-// counts how many event pairs are concurrent
+// counts how many event pairs have concurrent vector timestamps. when the
+// timestamps grow along every process and each one has seen everything
+// the latest event it knows of had seen, as vector clocks guarantee, the
+// concurrent events of each other process form one stretch of its chain,
+// found by binary search in O(n log E) per event. otherwise, after crashes
+// rolled clocks back or differential vectors lost entries, every pair is
+// compared. either way the work is spread across all cores.
+// GetConcurrencyStatistics reports the count together with how often the
+// Bloom clocks disagree with it.
 func (s *Simulator) CountConcurrentEvents() int {
-	concurrent := 0
 	events := s.Events
+	if chains, ok := eventChains(events); ok {
+		return countConcurrentChains(events, chains)
+	}
+	return countConcurrentPairwise(events)
+}
+
+// returns the concurrent event pairs counted by CountConcurrentEvents, all
+// event pairs and the concurrency rate. with the Bloom clock configured it
+// adds how many concurrent pairs the Bloom clocks order by mistake, and
+// their share of the concurrent pairs.
+func (s *Simulator) GetConcurrencyStatistics() map[string]interface{} {
+	events := s.Events
+	concurrent := s.CountConcurrentEvents()
+	pairs := len(events) * (len(events) - 1) / 2
+
+	rate := 0.0
+	if pairs > 0 {
+		rate = float64(concurrent) / float64(pairs)
+	}
+	stats := map[string]interface{}{
+		"concurrent_pairs": concurrent,
+		"pairs":            pairs,
+		"concurrency_rate": rate,
+	}
+
+	if s.HasClock(clock.Bloom) {
+		disagreements := countBloomDisagreements(events)
+		disagreementRate := 0.0
+		if concurrent > 0 {
+			disagreementRate = float64(disagreements) / float64(concurrent)
+		}
+		stats["bloom_disagreements"] = disagreements
+		stats["bloom_disagreement_rate"] = disagreementRate
+	}
+	return stats
+}
+
+// counts the event pairs with concurrent vector timestamps that the Bloom
+// clocks order, spread across all cores.
+func countBloomDisagreements(events []Event) int {
+	return parallelSum(len(events), func(i int) int {
+		n := 0
+		for j := i + 1; j < len(events); j++ {
+			if vector.CompareClocks(events[i].VectorTime, events[j].VectorTime) != vector.Concurrent {
+				continue
+			}
+			if likely, _ := bloom.Compare(events[i].Clocks[clock.Bloom].([]int64), events[j].Clocks[clock.Bloom].([]int64)); likely != vector.Concurrent {
+				n++
+			}
+		}
+		return n
+	})
+}
+
+// compares the probabilistic Bloom clock verdict with the exact vector
+// clock ordering for every event pair of the trace. concurrent is the
+// count of CountConcurrentEvents.
+// a disagreement is a pair the Bloom clocks order although it is concurrent;
+// the reverse cannot happen. avg_false_positive is the mean probability
+// the Bloom clocks themselves estimated for their ordered verdicts.
+// panics if the Bloom clock is not configured.
+func (s *Simulator) GetBloomStatistics() map[string]interface{} {
+	if !s.HasClock(clock.Bloom) {
+		panic("simulator: Bloom clock is not configured")
+	}
+
+	events := s.Events
+	pairs, concurrent, bloomConcurrent, disagreements := 0, 0, 0, 0
+	ordered := 0
+	falsePositive := 0.0
 
 	for i := 0; i < len(events); i++ {
 		for j := i + 1; j < len(events); j++ {
-			if areConcurrent(events[i].VectorTime, events[j].VectorTime) {
+			exact := vector.CompareClocks(events[i].VectorTime, events[j].VectorTime)
+			likely, fp := bloom.Compare(events[i].Clocks[clock.Bloom].([]int64), events[j].Clocks[clock.Bloom].([]int64))
+
+			pairs++
+			if exact == vector.Concurrent {
 				concurrent++
 			}
+			if likely == vector.Concurrent {
+				bloomConcurrent++
+			} else {
+				ordered++
+				falsePositive += fp
+			}
+			if likely != exact {
+				disagreements++
+			}
 		}
 	}
 
-	return concurrent
+	disagreementRate, avgFalsePositive := 0.0, 0.0
+	if concurrent > 0 {
+		disagreementRate = float64(disagreements) / float64(concurrent)
+	}
+	if ordered > 0 {
+		avgFalsePositive = falsePositive / float64(ordered)
+	}
+
+	return map[string]interface{}{
+		"filter_size":        s.BloomSize,
+		"hashes":             s.BloomHashes,
+		"pairs":              pairs,
+		"concurrent":         concurrent,
+		"bloom_concurrent":   bloomConcurrent,
+		"disagreements":      disagreements,
+		"disagreement_rate":  disagreementRate, // share of concurrent pairs reported as ordered
+		"avg_false_positive": avgFalsePositive,
+	}
 }
 
 // checks if two vector clock timestamps are concurrent
@@ -88,7 +201,9 @@ func AreEqual(v1, v2 []int64) bool {
 	return vector.CompareClocks(v1, v2) == vector.Equal
 }
 
-// returns who communicated with whom
+// returns who communicated with whom: messages sent from row to column,
+// including those a partition blocked. GetBlockedMatrix counts the blocked
+// ones, and GetPartitionStatistics counts them per partition.
 func (s *Simulator) GetCommunicationMatrix() [][]int {
 	matrix := make([][]int, s.NumProcesses)
 	for i := range matrix {
@@ -103,3 +218,127 @@ func (s *Simulator) GetCommunicationMatrix() [][]int {
 
 	return matrix
 }
+
+// counts happened-before edges that HLC timestamps fail to respect.
+// checks program order on each process and every send→receive pair,
+// which together imply the full happened-before relation.
+// returns 0 if the HLC is not configured.
+func (s *Simulator) CountHLCViolations() int {
+	if !s.HasClock(clock.HLC) {
+		return 0
+	}
+
+	before := func(e, f Event) bool {
+		return e.Clocks[clock.HLC].(hlc.Timestamp).Before(f.Clocks[clock.HLC].(hlc.Timestamp))
+	}
+	violations := 0
+
+	for _, p := range s.Processes {
+		for i := 1; i < len(p.Events); i++ {
+			if !before(p.Events[i-1], p.Events[i]) {
+				violations++
+			}
+		}
+	}
+
+	sends := make(map[int]Event)
+	for _, e := range s.Events {
+		if e.EventType == "send" {
+			sends[e.MessageID] = e
+		}
+	}
+	for _, e := range s.Events {
+		if e.EventType != "receive" {
+			continue
+		}
+		if send, ok := sends[e.MessageID]; ok && !before(send, e) {
+			violations++
+		}
+	}
+
+	return violations
+}
+
+// returns the events that processID knows have been seen by every process,
+// based on its current matrix clock. these can be truncated from logs.
+// returns no events if the matrix clock is not configured.
+// panics if processID is out of bounds.
+func (s *Simulator) StableEvents(processID int) []Event {
+	if processID < 0 || processID >= s.NumProcesses {
+		panic("simulator: processID out of bounds")
+	}
+
+	stable := make([]Event, 0)
+	if !s.HasClock(clock.Matrix) {
+		return stable
+	}
+	m := s.Processes[processID].Clocks[clock.Matrix].Snapshot().([][]int64)
+
+	for _, e := range s.Events {
+		if matrix.KnownByAll(m, e.ProcessID, e.VectorTime[e.ProcessID]) {
+			stable = append(stable, e)
+		}
+	}
+
+	return stable
+}
+
+// counts events that at least one process knows are seen by every process.
+// returns 0 if the matrix clock is not configured.
+func (s *Simulator) CountStableEvents() int {
+	if !s.HasClock(clock.Matrix) {
+		return 0
+	}
+
+	known := make([]int64, s.NumProcesses)
+	for _, p := range s.Processes {
+		m := p.Clocks[clock.Matrix].Snapshot().([][]int64)
+		for k := range known {
+			known[k] = max(known[k], matrix.MinKnown(m, k))
+		}
+	}
+
+	stable := 0
+	for _, e := range s.Events {
+		if e.VectorTime[e.ProcessID] <= known[e.ProcessID] {
+			stable++
+		}
+	}
+
+	return stable
+}
+
+// counts event pairs where the interval tree clock ordering differs
+// from the vector clock ordering. should always be zero.
+// returns 0 if the interval tree clock is not configured.
+func (s *Simulator) CountITCMismatches() int {
+	if !s.HasClock(clock.ITC) {
+		return 0
+	}
+	return s.CountClockMismatches(clock.ITC)
+}
+
+// counts event pairs where the named clock's ordering differs from the
+// vector clock ordering. exact clocks should report zero; scalar and
+// probabilistic clocks report how often their verdict is wrong.
+// panics if the clock is not configured.
+func (s *Simulator) CountClockMismatches(name string) int {
+	if !s.HasClock(name) {
+		panic("simulator: clock " + name + " is not configured")
+	}
+
+	c := s.Processes[0].Clocks[name]
+	mismatches := 0
+	events := s.Events
+
+	for i := 0; i < len(events); i++ {
+		for j := i + 1; j < len(events); j++ {
+			want := vector.CompareClocks(events[i].VectorTime, events[j].VectorTime)
+			if c.Compare(events[i].Clocks[name], events[j].Clocks[name]) != want {
+				mismatches++
+			}
+		}
+	}
+
+	return mismatches
+}
@@ -44,6 +44,24 @@ func BenchmarkConcurrencyDetection(b *testing.B) {
 	}
 }
 
+// benchmarks concurrent pair counting on a one-second run of 30 processes,
+// along process chains and by comparing every pair
+func BenchmarkConcurrencyDetection_LargeTrace(b *testing.B) {
+	sim := NewSimulator(30)
+	sim.RunDiscreteEvent(time.Second, 0.3, 0.4, 1)
+
+	b.Run("Chains", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			sim.CountConcurrentEvents()
+		}
+	})
+	b.Run("Pairwise", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			countConcurrentPairwise(sim.Events)
+		}
+	})
+}
+
 // benchmarks simulation performance with small number of processes
 func BenchmarkSimulation_SmallScale(b *testing.B) {
 	for i := 0; i < b.N; i++ {
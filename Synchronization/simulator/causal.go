@@ -0,0 +1,244 @@
+package simulator
+
+import (
+	"time"
+
+	causal "github.com/simonnyman/DISY_Projects/Synchronization/causal"
+	vector "github.com/simonnyman/DISY_Projects/Synchronization/vector"
+)
+
+// counters of causal delivery
+type causalStats struct {
+	received   int
+	delivered  int
+	held       int // broadcasts that waited in a hold-back queue
+	depthSum   int // hold-back queue depth after each arrival, summed
+	maxDepth   int
+	totalDelay time.Duration // time from arrival to delivery, summed
+	maxDelay   time.Duration
+}
+
+// a broadcast in a hold-back queue and when it arrived
+type heldBroadcast struct {
+	msg     *Message
+	arrived time.Duration
+}
+
+// broadcasts a message from one process to every other live process.
+// retired and crashed senders do nothing.
+// panics if fromID is out of bounds.
+func (s *Simulator) broadcast(fromID int) {
+	sender, ok := s.lookup(fromID)
+	if !ok {
+		panic("simulator: fromID out of bounds")
+	}
+
+	sender.mu.Lock()
+	if sender.Retired() || sender.Crashed() {
+		sender.mu.Unlock()
+		return
+	}
+	lt := sender.LamportClock.Send()
+	vt := sender.VectorClock.Send()
+	times := sender.sendClocks()
+	msgID := s.nextMessageID()
+	counts := s.holdBackQueue(sender).Broadcast()
+
+	e := Event{
+		ProcessID:    fromID,
+		EventType:    "broadcast",
+		Timestamp:    lt,
+		VectorTime:   vt,
+		Clocks:       times,
+		PhysicalTime: sender.PhysicalClock.Now(),
+		TargetID:     -1,
+		MessageID:    msgID,
+	}
+	s.recordEvent(sender, e)
+
+	s.procMu.RLock()
+	receivers := append([]*Process(nil), s.Processes...)
+	s.procMu.RUnlock()
+
+	var messages []*Message
+	for _, receiver := range receivers {
+		if receiver.ID == fromID || receiver.Retired() {
+			continue
+		}
+		messages = append(messages, &Message{
+			From:        fromID,
+			To:          receiver.ID,
+			LamportTime: lt,
+			VectorTime:  vt,
+			Clocks:      times,
+			Replica:     sender.Replica,
+			MessageID:   msgID,
+			Broadcasts:  counts,
+			LinkSeq:     sender.nextLinkSeq(receiver.ID),
+		})
+	}
+	sender.mu.Unlock()
+
+	for _, msg := range messages {
+		receiver, _ := s.lookup(msg.To)
+		s.recordPiggyback(8 * (len(vt) + len(counts)))
+		s.deliver(receiver, msg, true)
+	}
+}
+
+// returns the hold-back queue of p, creating it on first use.
+// must be called with p.mu held.
+func (s *Simulator) holdBackQueue(p *Process) *causal.Queue {
+	if p.holdBack == nil {
+		p.holdBack = causal.NewQueue(p.ID, s.MaxProcesses)
+	}
+	return p.holdBack
+}
+
+// records the arrival of a broadcast as a "received" event and delivers
+// every broadcast in the hold-back queue whose causal predecessors have
+// all been delivered. predecessors are tracked by the broadcast counts
+// the message carries, not its vector timestamp, so point-to-point
+// messages of other protocols never hold a broadcast back. arrival only
+// notes the message in the queue; its timestamps take effect at the
+// "delivered" event.
+// must be called with p.mu held.
+func (s *Simulator) receiveBroadcast(p *Process, msg *Message) {
+	now := time.Duration(s.trueTime())
+
+	e := Event{
+		ProcessID:    p.ID,
+		EventType:    "received",
+		Timestamp:    p.LamportClock.Tick(),
+		VectorTime:   p.VectorClock.Tick(),
+		Clocks:       p.tickClocks(),
+		PhysicalTime: p.PhysicalClock.Now(),
+		TargetID:     msg.From,
+		MessageID:    msg.MessageID,
+	}
+	s.recordEvent(p, e)
+
+	ready := s.holdBackQueue(p).Receive(causal.Message{
+		From:       msg.From,
+		Broadcasts: msg.Broadcasts,
+		Payload:    heldBroadcast{msg, now},
+	})
+	depth := p.holdBack.Len()
+
+	held := 0
+	var totalDelay, maxDelay time.Duration
+	for _, m := range ready {
+		hb := m.Payload.(heldBroadcast)
+		s.receiveLocked(p, hb.msg, "delivered")
+		if hb.msg != msg {
+			held++
+		}
+		delay := now - hb.arrived
+		totalDelay += delay
+		maxDelay = max(maxDelay, delay)
+	}
+
+	s.causalMu.Lock()
+	defer s.causalMu.Unlock()
+	st := &s.causal
+	st.received++
+	st.delivered += len(ready)
+	st.held += held
+	st.depthSum += depth
+	st.maxDepth = max(st.maxDepth, depth)
+	st.totalDelay += totalDelay
+	st.maxDelay = max(st.maxDelay, maxDelay)
+}
+
+// counts, over all processes, pairs of broadcasts that arrived or were
+// delivered in an order contradicting happened-before. the hold-back
+// queues keep delivery inversions at zero however often arrivals invert.
+func (s *Simulator) CountCausalInversions() (arrivals, deliveries int) {
+	stamps := make(map[int][]int64) // vector timestamp of each broadcast
+	arrived := make(map[int][]int)  // broadcasts in arrival order per process
+	delivered := make(map[int][]int)
+	for _, e := range s.Events {
+		switch e.EventType {
+		case "broadcast":
+			stamps[e.MessageID] = e.VectorTime
+		case "received":
+			arrived[e.ProcessID] = append(arrived[e.ProcessID], e.MessageID)
+		case "delivered":
+			// total-order deliveries have no broadcast stamp
+			if _, ok := stamps[e.MessageID]; ok {
+				delivered[e.ProcessID] = append(delivered[e.ProcessID], e.MessageID)
+			}
+		}
+	}
+
+	inversions := func(order []int) int {
+		n := 0
+		for i := range order {
+			for j := i + 1; j < len(order); j++ {
+				if vector.CompareClocks(stamps[order[j]], stamps[order[i]]) == vector.Before {
+					n++
+				}
+			}
+		}
+		return n
+	}
+	for _, order := range arrived {
+		arrivals += inversions(order)
+	}
+	for _, order := range delivered {
+		deliveries += inversions(order)
+	}
+	return arrivals, deliveries
+}
+
+// returns broadcast, arrival and delivery counts, how many broadcasts had
+// to wait and how many still wait, the hold-back queue depth after each
+// arrival, the delay from arrival to delivery, and the causal inversions
+// of arrivals and deliveries.
+func (s *Simulator) GetCausalDeliveryStatistics() map[string]interface{} {
+	s.causalMu.Lock()
+	st := s.causal
+	s.causalMu.Unlock()
+
+	broadcasts := 0
+	for _, e := range s.Events {
+		if e.EventType == "broadcast" {
+			broadcasts++
+		}
+	}
+
+	pending := 0
+	s.procMu.RLock()
+	for _, p := range s.Processes {
+		p.mu.Lock()
+		if p.holdBack != nil {
+			pending += p.holdBack.Len()
+		}
+		p.mu.Unlock()
+	}
+	s.procMu.RUnlock()
+
+	avgDepth := 0.0
+	if st.received > 0 {
+		avgDepth = float64(st.depthSum) / float64(st.received)
+	}
+	avgDelay := time.Duration(0)
+	if st.delivered > 0 {
+		avgDelay = st.totalDelay / time.Duration(st.delivered)
+	}
+
+	arrivals, deliveries := s.CountCausalInversions()
+	return map[string]interface{}{
+		"broadcasts":          broadcasts,
+		"received":            st.received,
+		"delivered":           st.delivered,
+		"held":                st.held,
+		"pending":             pending,
+		"avg_queue_depth":     avgDepth,
+		"max_queue_depth":     st.maxDepth,
+		"avg_delivery_delay":  avgDelay,
+		"max_delivery_delay":  st.maxDelay,
+		"arrival_inversions":  arrivals,
+		"delivery_inversions": deliveries,
+	}
+}
@@ -0,0 +1,145 @@
+package simulator
+
+import (
+	"testing"
+	"time"
+
+	network "github.com/simonnyman/DISY_Projects/Synchronization/network"
+)
+
+// verifies a reply that overtakes the broadcast it answers is held back
+// until the broadcast has been delivered.
+func TestCausalHoldBack(t *testing.T) {
+	sim := NewSimulator(3)
+	sim.CausalDelivery = true
+
+	sim.broadcast(0)
+	sim.receiveMessage(1, <-sim.Processes[1].inbox)
+	sim.broadcast(1)
+
+	question := <-sim.Processes[2].inbox
+	reply := <-sim.Processes[2].inbox
+	sim.receiveMessage(2, reply)
+	sim.receiveMessage(2, question)
+
+	var got []string
+	for _, e := range sim.Processes[2].Events {
+		got = append(got, e.EventType)
+	}
+	expected := []string{"received", "received", "delivered", "delivered"}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, got)
+		}
+	}
+	if events := sim.Processes[2].Events; events[2].MessageID != question.MessageID || events[3].MessageID != reply.MessageID {
+		t.Error("Question should be delivered before the reply")
+	}
+
+	stats := sim.GetCausalDeliveryStatistics()
+	if stats["held"].(int) != 1 || stats["max_queue_depth"].(int) != 1 || stats["pending"].(int) != 0 {
+		t.Errorf("Expected one broadcast held at depth 1, got %v", stats)
+	}
+	if stats["arrival_inversions"].(int) != 1 || stats["delivery_inversions"].(int) != 0 {
+		t.Errorf("Expected the arrival inversion to be repaired, got %v", stats)
+	}
+}
+
+// verifies causal delivery over a reordering network in a discrete-event
+// run: every broadcast is delivered everywhere, in causal order, and the
+// clocks agree with the causal order of the trace.
+func TestCausalDeliveryDiscrete(t *testing.T) {
+	sim := NewSimulator(5)
+	sim.CausalDelivery = true
+	sim.SetNetwork(network.NewModel(network.Link{
+		Latency:      network.Exponential(3 * time.Millisecond),
+		Reorder:      0.2,
+		ReorderDelay: 10 * time.Millisecond,
+	}, 2))
+	sim.RunDiscreteEvent(300*time.Millisecond, 0.3, 0.4, 2)
+
+	stats := sim.GetCausalDeliveryStatistics()
+	if broadcasts := stats["broadcasts"].(int); broadcasts == 0 || stats["delivered"].(int) != 4*broadcasts {
+		t.Errorf("Expected every broadcast delivered to 4 processes, got %v", stats)
+	}
+	if stats["received"] != stats["delivered"] || stats["pending"].(int) != 0 {
+		t.Errorf("Expected nothing left in the hold-back queues, got %v", stats)
+	}
+	if stats["arrival_inversions"].(int) == 0 || stats["held"].(int) == 0 {
+		t.Errorf("Expected the network to invert arrivals, got %v", stats)
+	}
+	if stats["delivery_inversions"].(int) != 0 {
+		t.Errorf("Expected causal delivery order, got %d inversions", stats["delivery_inversions"])
+	}
+	if stats["avg_delivery_delay"].(time.Duration) <= 0 {
+		t.Errorf("Held broadcasts should add delay, got %v", stats["avg_delivery_delay"])
+	}
+	if missed, spurious := sim.CountCausalityViolations(); missed+spurious != 0 {
+		t.Errorf("Expected clocks to match causality, got %d missed and %d spurious", missed, spurious)
+	}
+}
+
+// verifies mutual exclusion requests and replies, which merge vector
+// clocks point to point, do not hold broadcasts back.
+func TestCausalDeliveryWithMutex(t *testing.T) {
+	sim := NewSimulator(5)
+	sim.CausalDelivery = true
+	sim.SetMutualExclusion(MutexRicartAgrawala, 0.3, 20*time.Millisecond)
+	sim.RunDiscreteEvent(300*time.Millisecond, 0.2, 0.3, 1)
+
+	stats := sim.GetCausalDeliveryStatistics()
+	if broadcasts := stats["broadcasts"].(int); broadcasts == 0 || stats["delivered"].(int) != 4*broadcasts {
+		t.Errorf("Expected every broadcast delivered to 4 processes, got %v", stats)
+	}
+	if stats["received"] != stats["delivered"] || stats["pending"].(int) != 0 {
+		t.Errorf("Expected nothing left in the hold-back queues, got %v", stats)
+	}
+	if stats := sim.GetMutexStatistics(); stats["entries"].(int) == 0 || stats["violations"].(int) != 0 {
+		t.Errorf("Expected safe critical section entries, got %v", stats)
+	}
+}
+
+// verifies causal delivery in a real-time run.
+func TestCausalDeliveryRealTime(t *testing.T) {
+	sim := NewSimulator(4)
+	sim.CausalDelivery = true
+	sim.SetNetwork(network.NewModel(network.Link{Latency: network.Uniform(0, 4*time.Millisecond)}, 1))
+	sim.RunSimulation(100*time.Millisecond, 0.2, 0.5)
+
+	stats := sim.GetCausalDeliveryStatistics()
+	if stats["delivered"].(int) == 0 || stats["delivery_inversions"].(int) != 0 {
+		t.Errorf("Expected deliveries in causal order, got %v", stats)
+	}
+}
+
+// verifies causal delivery refuses a changing process group.
+func TestCausalDeliveryPanics(t *testing.T) {
+	causalSimulator := func() *Simulator {
+		sim := NewSimulatorWithCapacity(2, 4)
+		sim.CausalDelivery = true
+		return sim
+	}
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"spawn", func() { causalSimulator().SpawnProcess(0) }},
+		{"retire", func() { causalSimulator().RetireProcess(0, 1) }},
+		{"churn run", func() { causalSimulator().RunSimulationWithChurn(time.Millisecond, 0, 0, 0.1, 0) }},
+		{"discrete churn run", func() { causalSimulator().RunDiscreteEventWithChurn(time.Millisecond, 0, 0, 0, 0.1, 1) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic")
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
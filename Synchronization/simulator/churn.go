@@ -0,0 +1,298 @@
+package simulator
+
+import (
+	"math/rand"
+	"time"
+
+	clock "github.com/simonnyman/DISY_Projects/Synchronization/clock"
+	itc "github.com/simonnyman/DISY_Projects/Synchronization/itc"
+)
+
+// spawns a new process forked from parentID and returns its ID.
+// the parent records a "spawn" event and the child a "start" event that is
+// causally after it; clocks that implement clock.Forker, such as interval
+// tree clocks, hand half of the parent's identity to the child.
+// panics if parentID is out of bounds or retired, if no slot is left, or
+// with CausalDelivery, total-order multicast or mutual exclusion, which
+// need a fixed process group.
+func (s *Simulator) SpawnProcess(parentID int) int {
+	s.churnMu.Lock()
+	defer s.churnMu.Unlock()
+
+	s.checkFixedGroup()
+
+	parent, ok := s.lookup(parentID)
+	if !ok {
+		panic("simulator: parentID out of bounds")
+	}
+	if parent.Retired() {
+		panic("simulator: cannot spawn from a retired process")
+	}
+
+	s.procMu.RLock()
+	childID := s.NumProcesses
+	s.procMu.RUnlock()
+	if childID >= s.MaxProcesses {
+		panic("simulator: no process slots left")
+	}
+
+	msgID := s.nextMessageID()
+
+	// record the spawn on the parent and hand half of its identity to the child
+	parent.mu.Lock()
+	lt := parent.LamportClock.Tick()
+	vt := parent.VectorClock.Tick()
+	times := parent.tickClocks()
+	forked := make(map[string]clock.Clock)
+	for name, c := range parent.Clocks {
+		if f, ok := c.(clock.Forker); ok {
+			forked[name] = f.Fork()
+		}
+	}
+
+	e := Event{
+		ProcessID:    parentID,
+		EventType:    "spawn",
+		Timestamp:    lt,
+		VectorTime:   vt,
+		Clocks:       times,
+		PhysicalTime: parent.PhysicalClock.Now(),
+		TargetID:     childID,
+		MessageID:    msgID,
+	}
+	s.recordEvent(parent, e)
+	replica := parent.Replica
+	parent.mu.Unlock()
+
+	// the child starts with the parent's knowledge, like receiving a message
+	child := s.newProcess(childID, forked)
+
+	child.mu.Lock()
+	e = Event{
+		ProcessID:    childID,
+		EventType:    "start",
+		Timestamp:    child.LamportClock.Receive(lt),
+		VectorTime:   child.VectorClock.Receive(vt),
+		Clocks:       child.receiveClocks(parentID, times),
+		PhysicalTime: child.PhysicalClock.Now(),
+		TargetID:     parentID,
+		MessageID:    msgID,
+	}
+	s.recordEvent(child, e)
+	s.registerSync(child, replica)
+	child.clientContext = child.Replica.Join()
+	child.mu.Unlock()
+
+	s.procMu.Lock()
+	s.Processes = append(s.Processes, child)
+	s.NumProcesses++
+	s.procMu.Unlock()
+
+	return childID
+}
+
+// retires processID and hands the identity of its clock.Forker clocks,
+// such as interval tree clocks, to heirID.
+// the retiring process records a "retire" event and the heir a "join" event
+// that is causally after it. a retired process stops generating events and
+// drops any messages that still reach it.
+// panics if either ID is out of bounds or retired, if they are equal, or
+// with CausalDelivery, total-order multicast or mutual exclusion, which
+// need a fixed process group.
+func (s *Simulator) RetireProcess(processID, heirID int) {
+	s.churnMu.Lock()
+	defer s.churnMu.Unlock()
+
+	s.checkFixedGroup()
+
+	p, ok := s.lookup(processID)
+	if !ok {
+		panic("simulator: processID out of bounds")
+	}
+	heir, ok := s.lookup(heirID)
+	if !ok {
+		panic("simulator: heirID out of bounds")
+	}
+	if processID == heirID {
+		panic("simulator: process cannot retire into itself")
+	}
+	if p.Retired() || heir.Retired() {
+		panic("simulator: process already retired")
+	}
+
+	msgID := s.nextMessageID()
+
+	p.mu.Lock()
+	lt := p.LamportClock.Send()
+	vt := p.VectorClock.Send()
+	times := p.sendClocks()
+
+	e := Event{
+		ProcessID:    processID,
+		EventType:    "retire",
+		Timestamp:    lt,
+		VectorTime:   vt,
+		Clocks:       times,
+		PhysicalTime: p.PhysicalClock.Now(),
+		TargetID:     heirID,
+		MessageID:    msgID,
+	}
+	s.recordEvent(p, e)
+	replica := p.Replica
+	p.retired.Store(true)
+	close(p.stop)
+	p.mu.Unlock()
+
+	heir.mu.Lock()
+	joined := make(map[string]clock.Timestamp, len(heir.Clocks))
+	for name, c := range heir.Clocks {
+		if f, ok := c.(clock.Forker); ok {
+			joined[name] = f.Absorb(p.Clocks[name])
+		} else {
+			joined[name] = c.Receive(processID, times[name])
+		}
+	}
+	e = Event{
+		ProcessID:    heirID,
+		EventType:    "join",
+		Timestamp:    heir.LamportClock.Receive(lt),
+		VectorTime:   heir.VectorClock.Receive(vt),
+		Clocks:       joined,
+		PhysicalTime: heir.PhysicalClock.Now(),
+		TargetID:     processID,
+		MessageID:    msgID,
+	}
+	s.recordEvent(heir, e)
+	s.registerSync(heir, replica)
+	heir.mu.Unlock()
+}
+
+// runs the simulation like RunSimulation while spawning and retiring
+// processes. every 10ms a new process is forked from a random live process
+// with spawnProb, or a random live process retires into another with
+// retireProb. at least one process always stays live.
+// panics on invalid parameters, with CausalDelivery, total-order multicast
+// or mutual exclusion.
+func (s *Simulator) RunSimulationWithChurn(duration time.Duration, localEventProb, sendEventProb, spawnProb, retireProb float64) {
+	checkRunParameters(duration, localEventProb, sendEventProb, spawnProb, retireProb)
+	s.checkChurn(spawnProb, retireProb)
+	s.run(duration, localEventProb, sendEventProb, spawnProb, retireProb)
+}
+
+// panics if processes may spawn or retire while CausalDelivery,
+// total-order multicast or mutual exclusion is set, or snapshots are used.
+func (s *Simulator) checkChurn(spawnProb, retireProb float64) {
+	if spawnProb > 0 || retireProb > 0 {
+		s.checkFixedGroup()
+	}
+}
+
+// panics if CausalDelivery, total-order multicast or mutual exclusion is
+// set, or if snapshots were scheduled or taken.
+func (s *Simulator) checkFixedGroup() {
+	if s.CausalDelivery {
+		panic("simulator: causal delivery needs a fixed process group")
+	}
+	if s.totalOrder != TotalOrderNone {
+		panic("simulator: total-order multicast needs a fixed process group")
+	}
+	if s.exclusionAlgorithm() != MutexNone {
+		panic("simulator: mutual exclusion needs a fixed process group")
+	}
+	if s.snapshotsUsed() {
+		panic("simulator: snapshots need a fixed process group")
+	}
+}
+
+// returns the IDs of processes that have not retired.
+func (s *Simulator) LiveProcesses() []int {
+	s.procMu.RLock()
+	defer s.procMu.RUnlock()
+
+	live := make([]int, 0, s.NumProcesses)
+	for _, p := range s.Processes {
+		if !p.Retired() {
+			live = append(live, p.ID)
+		}
+	}
+	return live
+}
+
+// spawns a new process with spawnProb or retires one with retireProb.
+// returns the ID of the spawned process, or false if none was spawned.
+func (s *Simulator) churn(rng *rand.Rand, spawnProb, retireProb float64) (int, bool) {
+	r := rng.Float64()
+	if r < spawnProb {
+		return s.randomSpawn(rng)
+	}
+	if r < spawnProb+retireProb {
+		s.randomRetire(rng)
+	}
+	return 0, false
+}
+
+// spawns a child of a random running process if a slot is free.
+func (s *Simulator) randomSpawn(rng *rand.Rand) (int, bool) {
+	s.procMu.RLock()
+	full := s.NumProcesses >= s.MaxProcesses
+	s.procMu.RUnlock()
+	if full {
+		return 0, false
+	}
+
+	running := s.runningProcesses()
+	if len(running) == 0 {
+		return 0, false
+	}
+	return s.SpawnProcess(running[rng.Intn(len(running))]), true
+}
+
+// retires a random running process into another running process.
+func (s *Simulator) randomRetire(rng *rand.Rand) {
+	running := s.runningProcesses()
+	if len(running) < 2 {
+		return
+	}
+
+	i := rng.Intn(len(running))
+	j := rng.Intn(len(running) - 1)
+	if j >= i {
+		j++
+	}
+	s.RetireProcess(running[i], running[j])
+}
+
+// returns process lifecycle counts and interval tree clock stamp sizes.
+func (s *Simulator) GetChurnStatistics() map[string]interface{} {
+	spawned, retired := 0, 0
+	totalSize, maxSize := 0, 0
+
+	for _, e := range s.Events {
+		switch e.EventType {
+		case "spawn":
+			spawned++
+		case "retire":
+			retired++
+		}
+		if stamp, ok := e.Clocks[clock.ITC].(itc.Stamp); ok {
+			size := stamp.Size()
+			totalSize += size
+			maxSize = max(maxSize, size)
+		}
+	}
+
+	avgSize := 0.0
+	if len(s.Events) > 0 {
+		avgSize = float64(totalSize) / float64(len(s.Events))
+	}
+
+	return map[string]interface{}{
+		"spawned":        spawned,
+		"retired":        retired,
+		"live":           len(s.LiveProcesses()),
+		"vector_slots":   s.MaxProcesses,
+		"avg_itc_nodes":  avgSize,
+		"max_itc_nodes":  maxSize,
+		"itc_mismatches": s.CountITCMismatches(),
+	}
+}
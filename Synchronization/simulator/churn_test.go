@@ -0,0 +1,164 @@
+package simulator
+
+import (
+	"testing"
+	"time"
+
+	clock "github.com/simonnyman/DISY_Projects/Synchronization/clock"
+	itc "github.com/simonnyman/DISY_Projects/Synchronization/itc"
+	vector "github.com/simonnyman/DISY_Projects/Synchronization/vector"
+)
+
+// verifies the capacity constructor reserves vector slots.
+func TestNewSimulatorWithCapacity(t *testing.T) {
+	sim := NewSimulatorWithCapacity(2, 5)
+
+	if sim.NumProcesses != 2 || len(sim.Processes) != 2 {
+		t.Errorf("Expected 2 processes, got %d", sim.NumProcesses)
+	}
+	if len(sim.Processes[0].VectorClock.Clock()) != 5 {
+		t.Errorf("Vector clock should have 5 slots, got %d", len(sim.Processes[0].VectorClock.Clock()))
+	}
+}
+
+// verifies panic when capacity is smaller than the initial process count.
+func TestNewSimulatorWithCapacityPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic when maxProcesses < numProcesses")
+		}
+	}()
+
+	NewSimulatorWithCapacity(3, 2)
+}
+
+// verifies a spawned process starts causally after its parent's spawn event.
+func TestSpawnProcess(t *testing.T) {
+	sim := NewSimulatorWithCapacity(2, 3)
+	sim.generateLocalEvent(0)
+
+	childID := sim.SpawnProcess(0)
+	if childID != 2 || sim.NumProcesses != 3 {
+		t.Fatalf("Expected child 2 in 3 processes, got child %d of %d", childID, sim.NumProcesses)
+	}
+
+	spawn := sim.Processes[0].Events[1]
+	start := sim.Processes[2].Events[0]
+
+	if spawn.EventType != "spawn" || start.EventType != "start" {
+		t.Fatalf("Expected spawn/start events, got %s/%s", spawn.EventType, start.EventType)
+	}
+	if spawn.MessageID != start.MessageID {
+		t.Error("Spawn and start should share a message ID")
+	}
+	if !HappenedBefore(spawn.VectorTime, start.VectorTime) {
+		t.Error("Spawn should happen before start in vector time")
+	}
+	if itc.Compare(spawn.Clocks[clock.ITC].(itc.Stamp), start.Clocks[clock.ITC].(itc.Stamp)) != vector.Before {
+		t.Error("Spawn should happen before start in ITC")
+	}
+
+	// child can now take part in message passing
+	sim.sendMessage(2, 1)
+	msg := <-sim.Processes[1].inbox
+	sim.receiveMessage(1, msg)
+
+	if sim.CountITCMismatches() != 0 {
+		t.Error("ITC and vector orderings should agree")
+	}
+}
+
+// verifies panic when spawning beyond capacity.
+func TestSpawnProcessCapacityPanic(t *testing.T) {
+	sim := NewSimulator(2)
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic when no slots are left")
+		}
+	}()
+
+	sim.SpawnProcess(0)
+}
+
+// verifies a retired process hands its identity to the heir and goes quiet.
+func TestRetireProcess(t *testing.T) {
+	sim := NewSimulator(3)
+	sim.generateLocalEvent(2)
+
+	sim.RetireProcess(2, 0)
+
+	if !sim.Processes[2].Retired() {
+		t.Fatal("Process 2 should be retired")
+	}
+	if !sim.Processes[2].Clocks[clock.ITC].Snapshot().(itc.Stamp).IsAnonymous() {
+		t.Error("Retired process should no longer own an ITC identity")
+	}
+
+	retire := sim.Processes[2].Events[1]
+	join := sim.Processes[0].Events[0]
+	if retire.EventType != "retire" || join.EventType != "join" {
+		t.Fatalf("Expected retire/join events, got %s/%s", retire.EventType, join.EventType)
+	}
+	if itc.Compare(retire.Clocks[clock.ITC].(itc.Stamp), join.Clocks[clock.ITC].(itc.Stamp)) != vector.Before {
+		t.Error("Retire should happen before join in ITC")
+	}
+
+	// retired processes generate nothing and drop incoming messages
+	before := len(sim.Events)
+	sim.generateLocalEvent(2)
+	sim.sendMessage(1, 2)
+	msg := <-sim.Processes[2].inbox
+	sim.receiveMessage(2, msg)
+	if len(sim.Events) != before+1 {
+		t.Errorf("Only the send should be recorded, got %d new events", len(sim.Events)-before)
+	}
+
+	live := sim.LiveProcesses()
+	if len(live) != 2 {
+		t.Errorf("Expected 2 live processes, got %v", live)
+	}
+}
+
+// verifies panic when retiring a process into itself.
+func TestRetireProcessSelfPanic(t *testing.T) {
+	sim := NewSimulator(2)
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic when retiring into itself")
+		}
+	}()
+
+	sim.RetireProcess(1, 1)
+}
+
+// verifies a churning simulation keeps ITC and vector orderings in agreement.
+func TestRunSimulationWithChurn(t *testing.T) {
+	sim := NewSimulatorWithCapacity(3, 8)
+	sim.RunSimulationWithChurn(150*time.Millisecond, 0.3, 0.4, 0.2, 0.1)
+
+	stats := sim.GetChurnStatistics()
+	if stats["live"].(int) < 1 {
+		t.Error("At least one process should stay live")
+	}
+	if sim.NumProcesses != 3+stats["spawned"].(int) {
+		t.Errorf("Process count %d should match spawns %d", sim.NumProcesses, stats["spawned"])
+	}
+	if stats["itc_mismatches"].(int) != 0 {
+		t.Errorf("Expected 0 ITC mismatches, got %d", stats["itc_mismatches"])
+	}
+}
+
+// verifies panic on invalid churn probabilities.
+func TestRunSimulationWithChurnInvalidProb(t *testing.T) {
+	sim := NewSimulator(2)
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic for invalid churn probabilities")
+		}
+	}()
+
+	sim.RunSimulationWithChurn(50*time.Millisecond, 0.3, 0.3, 0.8, 0.5)
+}
@@ -1,14 +1,29 @@
 package simulator
 
-import "fmt"
+import (
+	"fmt"
+
+	clock "github.com/simonnyman/DISY_Projects/Synchronization/clock"
+	encoded "github.com/simonnyman/DISY_Projects/Synchronization/encoded"
+	hlc "github.com/simonnyman/DISY_Projects/Synchronization/hlc"
+	sparse "github.com/simonnyman/DISY_Projects/Synchronization/sparse"
+)
 
 // holds overhead measurements
 type ComplexityMetrics struct {
 	// Space complexity
-	LamportClockSize   int // bytes per process
-	VectorClockSize    int // bytes per process
-	AverageMessageSize int // bytes
-	TotalMemoryUsage   int // bytes
+	LamportClockSize   int     // bytes per process
+	VectorClockSize    int     // bytes per process
+	VectorMessageSize  float64 // measured vector bytes piggybacked per message
+	HLCClockSize       int     // bytes per process
+	MatrixClockSize    int     // bytes per process
+	SparseClockSize    int     // measured heap bytes per process, string-keyed map
+	SparseMessageSize  float64 // measured wire bytes per message for the sparse clock
+	EncodedClockBits   float64 // measured average bits of the prime-encoded vector clock
+	EncodedMaxBits     int     // largest prime-encoded timestamp in the trace
+	EncodedSmallerRate float64 // share of events whose encoding is smaller than the dense vector
+	AverageMessageSize int     // bytes
+	TotalMemoryUsage   int     // bytes
 
 	// Message complexity
 	TotalMessages         int
@@ -24,17 +39,32 @@ func (s *Simulator) AnalyzeComplexity() ComplexityMetrics {
 	// Lamport: 8 bytes (int64)
 	metrics.LamportClockSize = 8
 
-	// Vector: 8 bytes * number of processes
-	metrics.VectorClockSize = 8 * s.NumProcesses
+	// Vector: 8 bytes * number of process slots
+	metrics.VectorClockSize = 8 * s.MaxProcesses
+
+	// Vector piggyback: measured, since differential transmission sends fewer entries
+	metrics.VectorMessageSize = s.measureVectorPiggyback()
+
+	// HLC: wall time (int64) + logical counter (int64)
+	metrics.HLCClockSize = 16
+
+	// Matrix: 8 bytes * n * n process slots
+	metrics.MatrixClockSize = 8 * s.MaxProcesses * s.MaxProcesses
+
+	// Sparse: measured from the final clocks and from every message of the trace
+	metrics.SparseClockSize, metrics.SparseMessageSize = s.measureSparseClocks()
 
-	// Message size: From(8) + To(8) + LamportTime(8) + VectorTime(8*n) + MessageID(8)
-	metrics.AverageMessageSize = 32 + (8 * s.NumProcesses)
+	// Encoded: measured from the encoding of every event's vector time
+	metrics.EncodedClockBits, metrics.EncodedMaxBits, metrics.EncodedSmallerRate = s.measureEncodedClocks()
 
-	// Total memory: (Lamport + Vector) * processes + all events
-	clockMemory := (metrics.LamportClockSize + metrics.VectorClockSize) * s.NumProcesses
+	// Message size: From(8) + To(8) + LamportTime(8) + VectorTime(8*n) + HLCTime(16) + MatrixTime(8*n*n) + MessageID(8)
+	metrics.AverageMessageSize = 48 + metrics.VectorClockSize + metrics.MatrixClockSize
 
-	// Each event: ProcessID(8) + EventType(16) + Timestamp(8) + VectorTime(8*n) + TargetID(8) + MessageID(8)
-	eventSize := 48 + (8 * s.NumProcesses)
+	// Total memory: (Lamport + Vector + HLC + Matrix) * processes + all events
+	clockMemory := (metrics.LamportClockSize + metrics.VectorClockSize + metrics.HLCClockSize + metrics.MatrixClockSize) * s.NumProcesses
+
+	// Each event: ProcessID(8) + EventType(16) + Timestamp(8) + VectorTime(8*n) + HLCTime(16) + MatrixTime(8*n*n) + TargetID(8) + MessageID(8)
+	eventSize := 64 + metrics.VectorClockSize + metrics.MatrixClockSize
 	eventsMemory := eventSize * len(s.Events)
 
 	metrics.TotalMemoryUsage = clockMemory + eventsMemory
@@ -47,17 +77,92 @@ func (s *Simulator) AnalyzeComplexity() ComplexityMetrics {
 	}
 
 	// Message overhead: vector clock adds (n-1)*8 bytes vs Lamport
-	vectorOverhead := float64((s.NumProcesses - 1) * 8)
+	vectorOverhead := float64(metrics.VectorClockSize - 8)
 	lamportSize := float64(8)
 	metrics.MessageOverhead = vectorOverhead / lamportSize
 
 	return metrics
 }
 
-// compares Lamport vs Vector clock overhead
+// returns the average number of vector clock bytes carried per message.
+// full transmission always carries 8n bytes; differential transmission
+// carries 12 bytes per changed entry.
+func (s *Simulator) measureVectorPiggyback() float64 {
+	s.counterMu.Lock()
+	defer s.counterMu.Unlock()
+
+	if s.vectorMessages == 0 {
+		return 0
+	}
+	return float64(s.vectorBytes) / float64(s.vectorMessages)
+}
+
+// returns the average heap size of the sparse clocks, measured by
+// rebuilding the final clocks, and the average wire size of the sparse
+// timestamps carried by messages.
+// returns zeros if the sparse clock is not configured.
+func (s *Simulator) measureSparseClocks() (int, float64) {
+	if !s.HasClock(clock.Sparse) {
+		return 0, 0
+	}
+
+	clocks := make([]map[string]int64, len(s.Processes))
+	for i, p := range s.Processes {
+		clocks[i] = p.Clocks[clock.Sparse].Snapshot().(map[string]int64)
+	}
+	memory := sparse.MeasureMemory(clocks)
+
+	wire, messages := 0, 0
+	for _, e := range s.Events {
+		if e.EventType == "send" {
+			wire += sparse.EncodedSize(e.Clocks[clock.Sparse].(map[string]int64), sparse.StringKeySize)
+			messages++
+		}
+	}
+
+	avgWire := 0.0
+	if messages > 0 {
+		avgWire = float64(wire) / float64(messages)
+	}
+	return memory / len(s.Processes), avgWire
+}
+
+// returns the average and largest bit length of the prime-encoded vector
+// clock over all events, and the share of events where it is smaller than
+// the dense 8n-byte vector. an encoded clock run on the same trace holds
+// exactly these values, so they are computed from the vector timestamps.
+func (s *Simulator) measureEncodedClocks() (float64, int, float64) {
+	if len(s.Events) == 0 {
+		return 0, 0, 0
+	}
+
+	denseBits := 64 * s.MaxProcesses
+	total, maxBits, smaller := 0, 0, 0
+	for _, e := range s.Events {
+		bits := encoded.Encode(e.VectorTime).BitLen()
+		total += bits
+		maxBits = max(maxBits, bits)
+		if bits < denseBits {
+			smaller++
+		}
+	}
+
+	n := float64(len(s.Events))
+	return float64(total) / n, maxBits, float64(smaller) / n
+}
+
+// compares Lamport vs Vector vs HLC vs Matrix vs Sparse vs Encoded overhead
 func (s *Simulator) CompareAlgorithms() map[string]interface{} {
 	metrics := s.AnalyzeComplexity()
 
+	// largest logical counter shows how far HLC had to run ahead of physical time
+	var maxLogical int64
+	for _, e := range s.Events {
+		if t, ok := e.Clocks[clock.HLC].(hlc.Timestamp); ok {
+			maxLogical = max(maxLogical, t.Logical)
+		}
+	}
+
 	return map[string]interface{}{
 		"lamport": map[string]interface{}{
 			"space_per_process":     metrics.LamportClockSize,
@@ -66,9 +171,42 @@ func (s *Simulator) CompareAlgorithms() map[string]interface{} {
 		},
 		"vector": map[string]interface{}{
 			"space_per_process":     metrics.VectorClockSize,
-			"message_overhead":      8 * s.NumProcesses, // full vector
+			"message_overhead":      metrics.VectorClockSize, // full vector
 			"can_detect_concurrent": true,
 			"overhead_ratio":        float64(metrics.VectorClockSize) / float64(metrics.LamportClockSize),
+			"differential":          s.DifferentialVectors,
+			"measured_overhead":     metrics.VectorMessageSize, // bytes actually piggybacked
+			"piggyback_ratio":       metrics.VectorMessageSize / float64(metrics.VectorClockSize),
+		},
+		"hlc": map[string]interface{}{
+			"space_per_process":     metrics.HLCClockSize,
+			"message_overhead":      16, // wall time + logical counter
+			"can_detect_concurrent": false,
+			"tracks_physical_time":  true,
+			"max_logical":           maxLogical,
+			"overhead_ratio":        float64(metrics.HLCClockSize) / float64(metrics.LamportClockSize),
+		},
+		"matrix": map[string]interface{}{
+			"space_per_process":      metrics.MatrixClockSize,
+			"message_overhead":       metrics.MatrixClockSize, // full n×n matrix
+			"can_detect_concurrent":  true,
+			"knows_what_others_know": true,
+			"stable_events":          s.CountStableEvents(),
+			"overhead_ratio":         float64(metrics.MatrixClockSize) / float64(metrics.LamportClockSize),
+		},
+		"sparse": map[string]interface{}{
+			"space_per_process":     metrics.SparseClockSize,
+			"message_overhead":      metrics.SparseMessageSize, // measured on the wire
+			"can_detect_concurrent": true,
+			"dense_message_size":    metrics.VectorClockSize,
+			"wire_ratio":            metrics.SparseMessageSize / float64(metrics.VectorClockSize),
+		},
+		"encoded": map[string]interface{}{
+			"space_per_process":     metrics.EncodedClockBits / 8, // measured average
+			"max_size":              (metrics.EncodedMaxBits + 7) / 8,
+			"can_detect_concurrent": true,
+			"dense_size":            metrics.VectorClockSize,
+			"smaller_than_dense":    metrics.EncodedSmallerRate,
 		},
 		"tradeoff": map[string]interface{}{
 			"space_increase":       fmt.Sprintf("%.1fx", float64(metrics.VectorClockSize)/float64(metrics.LamportClockSize)),
@@ -0,0 +1,139 @@
+package simulator
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// counts concurrent pairs by comparing every pair of vector timestamps.
+func countConcurrentPairwise(events []Event) int {
+	return parallelSum(len(events), func(i int) int {
+		concurrent := 0
+		for j := i + 1; j < len(events); j++ {
+			if areConcurrent(events[i].VectorTime, events[j].VectorTime) {
+				concurrent++
+			}
+		}
+		return concurrent
+	})
+}
+
+// counts concurrent pairs along the chains of vector timestamps of each
+// process. an event f of process j is concurrent with an event e of
+// process i when e has not seen f, f's own entry being beyond e's entry
+// for j, and f has not seen e, f's entry for i being below e's own. both
+// entries grow along j's chain, so the events concurrent with e lie
+// between two boundaries found by binary search. every pair is found
+// from both of its events.
+func countConcurrentChains(events []Event, chains [][][]int64) int {
+	pairs := parallelSum(len(events), func(k int) int {
+		e := events[k].VectorTime
+		i := events[k].ProcessID
+		concurrent := 0
+		for j, chain := range chains {
+			if j == i {
+				continue
+			}
+			unseen := sort.Search(len(chain), func(c int) bool { return chain[c][j] > e[j] })
+			seeing := sort.Search(len(chain), func(c int) bool { return chain[c][i] >= e[i] })
+			concurrent += max(seeing-unseen, 0)
+		}
+		return concurrent
+	})
+	return pairs / 2
+}
+
+// returns the vector timestamps of each process's events, indexed by
+// process ID, and reports whether they let a timestamp's entry for a
+// process alone decide whether it has seen an event of that process. that
+// holds when all timestamps have the same length, every process's own
+// entry is positive and strictly increasing, its timestamps never
+// decrease, and every timestamp dominates that of the latest event of each
+// other process it has seen. the last condition only needs checking
+// where an entry changed since the process's previous event.
+func eventChains(events []Event) ([][][]int64, bool) {
+	if len(events) == 0 {
+		return nil, true
+	}
+	n := len(events[0].VectorTime)
+	chains := make([][][]int64, n)
+	for _, e := range events {
+		v := e.VectorTime
+		if len(v) != n || e.ProcessID < 0 || e.ProcessID >= n {
+			return nil, false
+		}
+		chain := chains[e.ProcessID]
+		if len(chain) == 0 && v[e.ProcessID] <= 0 {
+			return nil, false
+		}
+		if len(chain) > 0 {
+			prev := chain[len(chain)-1]
+			if v[e.ProcessID] <= prev[e.ProcessID] || !dominates(v, prev) {
+				return nil, false
+			}
+		}
+		chains[e.ProcessID] = append(chain, v)
+	}
+
+	invalid := parallelSum(len(events), func(k int) int {
+		i := events[k].ProcessID
+		v := events[k].VectorTime
+		var prev []int64
+		if c := chainPosition(chains[i], i, v[i]); c > 0 {
+			prev = chains[i][c-1]
+		}
+		for j, chain := range chains {
+			if j == i || v[j] == 0 || (prev != nil && v[j] == prev[j]) {
+				continue
+			}
+			// the latest event of j that v has seen
+			c := chainPosition(chain, j, v[j]+1) - 1
+			if c < 0 || !dominates(v, chain[c]) {
+				return 1
+			}
+		}
+		return 0
+	})
+	return chains, invalid == 0
+}
+
+// returns the index of the first timestamp in a chain of process j whose
+// own entry is at least own.
+func chainPosition(chain [][]int64, j int, own int64) int {
+	return sort.Search(len(chain), func(c int) bool { return chain[c][j] >= own })
+}
+
+// reports whether every entry of v is at least that of w.
+func dominates(v, w []int64) bool {
+	for i := range v {
+		if v[i] < w[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// sums count over 0..n-1, spread over one goroutine per core. items are
+// dealt out in turn so that costs falling with the index even out.
+func parallelSum(n int, count func(i int) int) int {
+	workers := min(runtime.GOMAXPROCS(0), n)
+	sums := make([]int, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < n; i += workers {
+				sums[w] += count(i)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	total := 0
+	for _, sum := range sums {
+		total += sum
+	}
+	return total
+}
@@ -0,0 +1,129 @@
+package simulator
+
+import (
+	"testing"
+	"time"
+
+	network "github.com/simonnyman/DISY_Projects/Synchronization/network"
+)
+
+// verifies the chain-based count equals the pairwise comparison of every
+// event pair across the kinds of run the simulator produces, and that
+// runs whose clocks were rolled back by crashes fall back to it.
+func TestConcurrentChainsMatchPairwise(t *testing.T) {
+	tests := []struct {
+		name   string
+		chains bool // timestamps qualify for the chain-based count
+		run    func() *Simulator
+	}{
+		{"messages", true, func() *Simulator {
+			sim := NewSimulator(6)
+			sim.RunDiscreteEvent(300*time.Millisecond, 0.3, 0.4, 3)
+			return sim
+		}},
+		{"churn", true, func() *Simulator {
+			sim := NewSimulatorWithCapacity(3, 6)
+			sim.RunDiscreteEventWithChurn(400*time.Millisecond, 0.2, 0.4, 0.02, 0.02, 4)
+			return sim
+		}},
+		{"causal delivery", true, func() *Simulator {
+			sim := NewSimulator(4)
+			sim.CausalDelivery = true
+			sim.RunDiscreteEvent(200*time.Millisecond, 0.2, 0.4, 5)
+			return sim
+		}},
+		{"mutual exclusion", true, func() *Simulator {
+			sim := NewSimulator(4)
+			sim.SetMutualExclusion(MutexRicartAgrawala, 0.2, 10*time.Millisecond)
+			sim.RunDiscreteEvent(200*time.Millisecond, 0.2, 0.4, 6)
+			return sim
+		}},
+		{"differential vectors over reordering links", true, func() *Simulator {
+			sim := NewSimulator(4)
+			sim.DifferentialVectors = true
+			sim.SetNetwork(network.NewModel(network.Link{
+				Latency:      network.Constant(time.Millisecond),
+				Reorder:      0.5,
+				ReorderDelay: 10 * time.Millisecond,
+			}, 7))
+			sim.RunDiscreteEvent(300*time.Millisecond, 0.2, 0.5, 7)
+			return sim
+		}},
+		{"crashes", false, func() *Simulator {
+			sim := NewSimulator(4)
+			sim.SetDurability(DurabilityNone, 0)
+			sim.SetRandomCrashes(0.02, 0.2)
+			sim.RunDiscreteEvent(300*time.Millisecond, 0.2, 0.4, 8)
+			return sim
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := tt.run()
+			pairwise := countConcurrentPairwise(sim.Events)
+			if pairwise == 0 {
+				t.Fatalf("Expected concurrent pairs in %d events", len(sim.Events))
+			}
+			if got := sim.CountConcurrentEvents(); got != pairwise {
+				t.Errorf("Expected %d concurrent pairs, got %d", pairwise, got)
+			}
+
+			chains, ok := eventChains(sim.Events)
+			if ok != tt.chains {
+				t.Fatalf("Expected qualifying for the chain-based count to be %v", tt.chains)
+			}
+			if ok {
+				if got := countConcurrentChains(sim.Events, chains); got != pairwise {
+					t.Errorf("Expected %d concurrent pairs along the chains, got %d", pairwise, got)
+				}
+			}
+		})
+	}
+}
+
+// verifies timestamps the chains would misjudge are rejected: P2 has seen
+// P1's event but not the P0 event before it, so only comparing whole
+// timestamps finds P1's and P2's events concurrent.
+func TestEventChainsRejectLostEntries(t *testing.T) {
+	sim := NewSimulator(3)
+	sim.Events = []Event{
+		{ProcessID: 0, VectorTime: []int64{1, 0, 0}},
+		{ProcessID: 1, VectorTime: []int64{1, 1, 0}},
+		{ProcessID: 2, VectorTime: []int64{0, 1, 1}},
+	}
+	if _, ok := eventChains(sim.Events); ok {
+		t.Error("Expected timestamps with a lost entry to be rejected")
+	}
+	if got := sim.CountConcurrentEvents(); got != 2 {
+		t.Errorf("Expected 2 concurrent pairs, got %d", got)
+	}
+
+	tests := []struct {
+		name   string
+		events []Event
+	}{
+		{"own entry rolled back", []Event{
+			{ProcessID: 0, VectorTime: []int64{2, 0}},
+			{ProcessID: 0, VectorTime: []int64{1, 0}},
+		}},
+		{"other entry shrinks", []Event{
+			{ProcessID: 1, VectorTime: []int64{0, 1}},
+			{ProcessID: 0, VectorTime: []int64{1, 1}},
+			{ProcessID: 0, VectorTime: []int64{2, 0}},
+		}},
+		{"unknown event seen", []Event{
+			{ProcessID: 0, VectorTime: []int64{1, 3}},
+			{ProcessID: 1, VectorTime: []int64{0, 4}},
+		}},
+		{"lengths differ", []Event{
+			{ProcessID: 0, VectorTime: []int64{1, 0}},
+			{ProcessID: 1, VectorTime: []int64{0, 1, 0}},
+		}},
+	}
+	for _, tt := range tests {
+		if _, ok := eventChains(tt.events); ok {
+			t.Errorf("%s: expected the timestamps to be rejected", tt.name)
+		}
+	}
+}
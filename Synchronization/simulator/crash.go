@@ -0,0 +1,445 @@
+package simulator
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// what survives a crash of the Lamport and vector clocks
+const (
+	DurabilityNone       = "none"       // clocks restart from zero
+	DurabilityCheckpoint = "checkpoint" // clocks restart from the last checkpoint
+	DurabilityWAL        = "wal"        // every clock update is logged, so nothing is lost
+)
+
+// a scheduled crash or recovery of a process
+type faultChange struct {
+	at      time.Duration
+	process int
+	recover bool
+}
+
+// state and counters of crash faults
+type faultState struct {
+	schedule    []faultChange
+	crashProb   float64
+	recoverProb float64
+	crashes     int
+	recoveries  int
+	rolledBack  int64 // own vector entries forgotten on recovery
+}
+
+// Lamport and vector clock state saved by the durability policy
+type stableClocks struct {
+	lamport int64
+	vector  []int64 // nil until the first save
+}
+
+// selects what survives a crash of a process's Lamport and vector clocks:
+// DurabilityNone, DurabilityCheckpoint with a checkpoint every
+// checkpointEvery events, or DurabilityWAL. the other clocks, the register
+// replica and the inbox are kept. set it before running the simulation.
+// panics if the policy is unknown or checkpointEvery is less than 1 for
+// DurabilityCheckpoint.
+func (s *Simulator) SetDurability(policy string, checkpointEvery int) {
+	switch policy {
+	case DurabilityNone, DurabilityWAL:
+	case DurabilityCheckpoint:
+		if checkpointEvery < 1 {
+			panic("simulator: checkpointEvery must be at least 1")
+		}
+	default:
+		panic("simulator: unknown durability policy " + policy)
+	}
+	s.durability = policy
+	s.checkpointEvery = checkpointEvery
+}
+
+// saves the process's clocks if the durability policy asks for it after
+// the event just recorded.
+// must be called with p.mu held.
+func (s *Simulator) persist(p *Process) {
+	switch s.durability {
+	case DurabilityWAL:
+		p.stable = stableClocks{p.LamportClock.Time(), p.VectorClock.Clock()}
+	case DurabilityCheckpoint:
+		p.unsaved++
+		if p.unsaved >= s.checkpointEvery {
+			p.stable = stableClocks{p.LamportClock.Time(), p.VectorClock.Clock()}
+			p.unsaved = 0
+		}
+	}
+}
+
+// reports whether the process has crashed and not recovered yet.
+func (p *Process) Crashed() bool {
+	return p.crashed.Load()
+}
+
+// crashes processID: it records a "crash" event, then stops generating
+// events and draining its inbox, leaving the messages that reach it
+// waiting there. once the inbox is full, senders in a real-time run block
+// until it recovers or the run ends, so no application or protocol message
+// is lost; clock synchronization messages, which are never waited for,
+// are dropped. without a later RecoverProcess this is a crash-stop
+// failure, and the messages still waiting at the end are never received.
+// panics if processID is out of bounds, retired or already crashed.
+func (s *Simulator) CrashProcess(processID int) {
+	p, ok := s.lookup(processID)
+	if !ok {
+		panic("simulator: processID out of bounds")
+	}
+	if !s.crash(p) {
+		panic("simulator: process is retired or already crashed")
+	}
+}
+
+// recovers a crashed process: its Lamport and vector clocks are restored
+// per the durability policy, it records a "recover" event and then
+// receives the messages that waited in its inbox.
+// panics if processID is out of bounds, retired or not crashed.
+func (s *Simulator) RecoverProcess(processID int) {
+	p, ok := s.lookup(processID)
+	if !ok {
+		panic("simulator: processID out of bounds")
+	}
+	if !s.recover(p) {
+		panic("simulator: process is retired or not crashed")
+	}
+}
+
+// crashes p unless it is retired or crashed. returns true if it crashed.
+func (s *Simulator) crash(p *Process) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Retired() || p.Crashed() {
+		return false
+	}
+
+	e := Event{
+		ProcessID:    p.ID,
+		EventType:    "crash",
+		Timestamp:    p.LamportClock.Tick(),
+		VectorTime:   p.VectorClock.Tick(),
+		Clocks:       p.tickClocks(),
+		PhysicalTime: p.PhysicalClock.Now(),
+		TargetID:     -1,
+		MessageID:    -1,
+	}
+	s.recordEvent(p, e)
+	p.crashed.Store(true)
+	p.resume = make(chan struct{})
+
+	s.faultMu.Lock()
+	defer s.faultMu.Unlock()
+	s.faults.crashes++
+	return true
+}
+
+// recovers p if it is crashed and not retired. returns true if it recovered.
+func (s *Simulator) recover(p *Process) bool {
+	p.mu.Lock()
+	if p.Retired() || !p.Crashed() {
+		p.mu.Unlock()
+		return false
+	}
+
+	// the volatile clocks are lost; restart from what was saved
+	before := p.VectorClock.Clock()[p.ID]
+	restored := int64(0)
+	if p.stable.vector == nil {
+		p.LamportClock.Reset()
+		p.VectorClock.Reset()
+	} else {
+		p.LamportClock.Restore(p.stable.lamport)
+		p.VectorClock.Restore(p.stable.vector)
+		restored = p.stable.vector[p.ID]
+	}
+	p.unsaved = 0
+	p.crashed.Store(false)
+	close(p.resume)
+	p.resume = nil
+
+	e := Event{
+		ProcessID:    p.ID,
+		EventType:    "recover",
+		Timestamp:    p.LamportClock.Tick(),
+		VectorTime:   p.VectorClock.Tick(),
+		Clocks:       p.tickClocks(),
+		PhysicalTime: p.PhysicalClock.Now(),
+		TargetID:     -1,
+		MessageID:    -1,
+	}
+	s.recordEvent(p, e)
+
+	backlog := p.backlog
+	p.backlog = nil
+	for _, msg := range backlog {
+		s.accept(p, msg)
+	}
+	p.mu.Unlock()
+	s.flush(p)
+
+	s.faultMu.Lock()
+	defer s.faultMu.Unlock()
+	s.faults.recoveries++
+	s.faults.rolledBack += before - restored
+	return true
+}
+
+// keeps a message that reached a crashed process until it recovers. in
+// real time the receiver stops draining the inbox of a crashed process, so
+// this only holds a message it was taking out as the process crashed; in
+// discrete-event runs, which have no inbox, it stands in for one.
+// must be called with p.mu held.
+func (s *Simulator) stash(p *Process, msg *Message) {
+	p.backlog = append(p.backlog, msg)
+}
+
+// returns a channel that is closed when p recovers, or nil if p is not
+// crashed.
+func (p *Process) recovery() <-chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.Crashed() {
+		return nil
+	}
+	return p.resume
+}
+
+// schedules processID to crash at time at after the run begins.
+// a crash that finds the process retired, crashed or not yet spawned has
+// no effect.
+// panics if at is negative or processID is out of bounds.
+func (s *Simulator) ScheduleCrash(at time.Duration, processID int) {
+	s.addFault(faultChange{at: at, process: processID})
+}
+
+// schedules processID to recover at time at after the run begins.
+// a recovery that finds the process running or retired has no effect.
+// panics if at is negative or processID is out of bounds.
+func (s *Simulator) ScheduleRecovery(at time.Duration, processID int) {
+	s.addFault(faultChange{at: at, process: processID, recover: true})
+}
+
+// inserts a fault into the schedule, keeping it sorted by time.
+func (s *Simulator) addFault(change faultChange) {
+	if change.at < 0 {
+		panic("simulator: fault time must not be negative")
+	}
+	if change.process < 0 || change.process >= s.MaxProcesses {
+		panic("simulator: fault process ID out of bounds")
+	}
+
+	s.faultMu.Lock()
+	defer s.faultMu.Unlock()
+	s.faults.schedule = append(s.faults.schedule, change)
+	sort.SliceStable(s.faults.schedule, func(i, j int) bool {
+		return s.faults.schedule[i].at < s.faults.schedule[j].at
+	})
+}
+
+// makes every running process crash with crashProb and every crashed
+// process recover with recoverProb each time it would generate an event.
+// a recoverProb of 0 gives crash-stop failures.
+// panics if a probability is not between 0 and 1.
+func (s *Simulator) SetRandomCrashes(crashProb, recoverProb float64) {
+	if crashProb < 0 || crashProb > 1 || recoverProb < 0 || recoverProb > 1 {
+		panic("simulator: crash and recovery probabilities must be between 0 and 1")
+	}
+
+	s.faultMu.Lock()
+	defer s.faultMu.Unlock()
+	s.faults.crashProb = crashProb
+	s.faults.recoverProb = recoverProb
+}
+
+// lets a random fault crash or recover processID.
+// returns true if the process is crashed or just crashed, so it generates
+// no event this time.
+func (s *Simulator) randomFault(processID int, rng *rand.Rand) bool {
+	p, _ := s.lookup(processID)
+
+	s.faultMu.Lock()
+	crashProb, recoverProb := s.faults.crashProb, s.faults.recoverProb
+	s.faultMu.Unlock()
+
+	if p.Crashed() {
+		if recoverProb > 0 && rng.Float64() < recoverProb {
+			s.recover(p)
+		}
+		return true
+	}
+	return crashProb > 0 && rng.Float64() < crashProb && s.crash(p)
+}
+
+// applies a scheduled fault if the process exists.
+func (s *Simulator) applyFault(change faultChange) {
+	p, ok := s.lookup(change.process)
+	if !ok {
+		return
+	}
+	if change.recover {
+		s.recover(p)
+	} else {
+		s.crash(p)
+	}
+}
+
+// returns the scheduled faults that fall within duration.
+func (s *Simulator) faultSchedule(duration time.Duration) []faultChange {
+	s.faultMu.Lock()
+	defer s.faultMu.Unlock()
+
+	var changes []faultChange
+	for _, change := range s.faults.schedule {
+		if change.at <= duration {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// starts the goroutine that applies the fault schedule in real time until
+// stop is closed.
+func (s *Simulator) runFaults(wg *sync.WaitGroup, stop <-chan bool, duration time.Duration) {
+	changes := s.faultSchedule(duration)
+	if len(changes) == 0 {
+		return
+	}
+
+	start := time.Now()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for _, change := range changes {
+			timer := time.NewTimer(change.at - time.Since(start))
+			select {
+			case <-stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+				s.applyFault(change)
+			}
+		}
+	}()
+}
+
+// returns the IDs of processes that have neither retired nor crashed.
+func (s *Simulator) runningProcesses() []int {
+	s.procMu.RLock()
+	defer s.procMu.RUnlock()
+
+	running := make([]int, 0, s.NumProcesses)
+	for _, p := range s.Processes {
+		if !p.Retired() && !p.Crashed() {
+			running = append(running, p.ID)
+		}
+	}
+	return running
+}
+
+// returns the events whose vector timestamp is not after every earlier
+// event of the same process. a correct clock never produces one; after a
+// crash they show where a process restarted with a rolled-back clock and
+// reused timestamps it had already issued.
+func (s *Simulator) RollbackEvents() []Event {
+	var rolledBack []Event
+	seen := make(map[int][]int64) // component-wise max of each process's events so far
+
+	for _, e := range s.Events {
+		high, ok := seen[e.ProcessID]
+		if !ok {
+			seen[e.ProcessID] = append([]int64(nil), e.VectorTime...)
+			continue
+		}
+		if !HappenedBefore(high, e.VectorTime) {
+			rolledBack = append(rolledBack, e)
+		}
+		for i, v := range e.VectorTime {
+			high[i] = max(high[i], v)
+		}
+	}
+	return rolledBack
+}
+
+// compares the vector clock ordering of every event pair with the actual
+// causal order of the trace, built from process order and the messages,
+// spawns and retirements linking processes. missed counts causally ordered
+// pairs the vector timestamps call concurrent or reverse, spurious counts
+// concurrent pairs the vector timestamps order. both stay zero unless
+// clocks were rolled back or differential vectors lost entries.
+// the comparison takes time quadratic in the number of events, so it is
+// not part of GetCrashStatistics.
+func (s *Simulator) CountCausalityViolations() (missed, spurious int) {
+	events := s.Events
+	actual := actualVectors(events)
+
+	for j := range events {
+		for i := 0; i < j; i++ {
+			p := events[i].ProcessID
+			before := actual[j][p] >= actual[i][p]
+			claimed := HappenedBefore(events[i].VectorTime, events[j].VectorTime)
+			switch {
+			case before && !claimed:
+				missed++
+			case !before && claimed, HappenedBefore(events[j].VectorTime, events[i].VectorTime):
+				spurious++
+			}
+		}
+	}
+	return missed, spurious
+}
+
+// returns the vector timestamp each event would carry in a trace without
+// faults, counting the events of each process that causally precede it.
+// event i happened before event j iff i's own entry in j's vector reaches
+// i's own entry.
+func actualVectors(events []Event) [][]int32 {
+	n := 0
+	for _, e := range events {
+		n = max(n, e.ProcessID+1)
+	}
+
+	actual := make([][]int32, len(events))
+	for j, edges := range causalEdges(events) {
+		actual[j] = make([]int32, n)
+		for _, edge := range edges {
+			for k, v := range actual[edge.From] {
+				actual[j][k] = max(actual[j][k], v)
+			}
+		}
+		actual[j][events[j].ProcessID]++
+	}
+	return actual
+}
+
+// returns crash and recovery counts, the own vector entries forgotten on
+// recovery, and how many events the rolled-back clocks got wrong.
+// CountCausalityViolations reports the event pairs they got wrong.
+func (s *Simulator) GetCrashStatistics() map[string]interface{} {
+	s.faultMu.Lock()
+	st := s.faults
+	s.faultMu.Unlock()
+
+	crashed := 0
+	s.procMu.RLock()
+	for _, p := range s.Processes {
+		if p.Crashed() && !p.Retired() {
+			crashed++
+		}
+	}
+	s.procMu.RUnlock()
+
+	return map[string]interface{}{
+		"durability":      s.durability,
+		"crashes":         st.crashes,
+		"recoveries":      st.recoveries,
+		"crashed":         crashed,
+		"rolled_back":     st.rolledBack,
+		"rollback_events": len(s.RollbackEvents()),
+	}
+}
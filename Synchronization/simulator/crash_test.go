@@ -0,0 +1,293 @@
+package simulator
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// verifies a crashed process generates nothing and receives the messages
+// that waited in its inbox once it recovers.
+func TestCrashHoldsMessages(t *testing.T) {
+	sim := NewSimulator(2)
+	sim.SetDurability(DurabilityWAL, 0)
+	sim.CrashProcess(1)
+
+	sim.generateLocalEvent(1)
+	sim.sendMessage(1, 0)
+	sim.sendMessage(0, 1)
+	sim.receiveMessage(1, <-sim.Processes[1].inbox)
+	if n := len(sim.Processes[1].Events); n != 1 {
+		t.Fatalf("Crashed process should only record its crash, got %d events", n)
+	}
+
+	sim.RecoverProcess(1)
+	events := sim.Processes[1].Events
+	if len(events) != 3 || events[1].EventType != "recover" || events[2].EventType != "receive" {
+		t.Fatalf("Expected crash, recover and the held receive, got %v", events)
+	}
+	if missed, spurious := sim.CountCausalityViolations(); missed+spurious != 0 {
+		t.Errorf("Held message should not violate causality, got %d missed and %d spurious", missed, spurious)
+	}
+}
+
+// verifies a crashed process keeps messages beyond its inbox capacity
+// until it recovers.
+func TestCrashKeepsOverflow(t *testing.T) {
+	sim := NewSimulator(2)
+	sim.CrashProcess(1)
+
+	sent := cap(sim.Processes[1].inbox) + 1
+	for i := 0; i < sent; i++ {
+		sim.sendMessage(0, 1)
+		sim.receiveMessage(1, <-sim.Processes[1].inbox)
+	}
+	sim.RecoverProcess(1)
+	if got := sim.GetStatistics()["receive_events"].(int); got != sent {
+		t.Errorf("Expected all %d messages received after recovery, got %d", sent, got)
+	}
+}
+
+// verifies a crashed process stops draining its inbox in a real-time run
+// and receives every message sent to it before its recovery.
+func TestCrashStopsDraining(t *testing.T) {
+	sim := NewSimulator(3)
+	sim.ScheduleCrash(30*time.Millisecond, 1)
+	sim.ScheduleRecovery(80*time.Millisecond, 1)
+	sim.RunSimulation(150*time.Millisecond, 0.2, 0.6)
+
+	crashed, recovered := -1, -1
+	for i, e := range sim.Events {
+		if e.ProcessID == 1 && e.EventType == "crash" {
+			crashed = i
+		}
+		if e.ProcessID == 1 && e.EventType == "recover" {
+			recovered = i
+		}
+	}
+	if crashed < 0 || recovered < crashed {
+		t.Fatalf("Expected a crash and a recovery, got %d and %d", crashed, recovered)
+	}
+
+	sent := make(map[int]bool) // messages sent to P1 before it recovered
+	for _, e := range sim.Events[:recovered] {
+		if e.EventType == "send" && e.TargetID == 1 {
+			sent[e.MessageID] = true
+		}
+	}
+	for i, e := range sim.Events {
+		if e.ProcessID != 1 || e.EventType != "receive" {
+			continue
+		}
+		if i > crashed && i < recovered {
+			t.Errorf("Crashed process received message %d", e.MessageID)
+		}
+		delete(sent, e.MessageID)
+	}
+	if len(sent) != 0 {
+		t.Errorf("Expected every message sent before the recovery to be received, %d were not", len(sent))
+	}
+}
+
+// verifies each durability policy restores the clocks it saved.
+func TestDurabilityPolicies(t *testing.T) {
+	tests := []struct {
+		policy     string
+		lamport    int64 // at the recover event
+		rolledBack int64
+		rollbacks  int
+	}{
+		{DurabilityNone, 1, 4, 1},
+		{DurabilityCheckpoint, 4, 1, 1},
+		{DurabilityWAL, 5, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			sim := NewSimulator(2)
+			sim.SetDurability(tt.policy, 3)
+			for i := 0; i < 3; i++ {
+				sim.generateLocalEvent(0)
+			}
+			sim.CrashProcess(0)
+			sim.RecoverProcess(0)
+
+			recovered := sim.Events[len(sim.Events)-1]
+			if recovered.Timestamp != tt.lamport || recovered.VectorTime[0] != tt.lamport {
+				t.Errorf("Expected clocks at %d after recovery, got %d and %v",
+					tt.lamport, recovered.Timestamp, recovered.VectorTime)
+			}
+
+			stats := sim.GetCrashStatistics()
+			if stats["rolled_back"].(int64) != tt.rolledBack || stats["rollback_events"].(int) != tt.rollbacks {
+				t.Errorf("Expected %d rolled back and %d rollback events, got %v", tt.rolledBack, tt.rollbacks, stats)
+			}
+		})
+	}
+}
+
+// verifies a rolled-back clock is caught ordering events it never saw and
+// missing the order of its own events.
+func TestCrashCausalityViolations(t *testing.T) {
+	run := func(policy string) (int, int) {
+		sim := NewSimulator(2)
+		sim.SetDurability(policy, 1)
+		sim.generateLocalEvent(0)
+		sim.sendMessage(0, 1)
+		sim.receiveMessage(1, <-sim.Processes[1].inbox)
+		sim.CrashProcess(0)
+		sim.RecoverProcess(0)
+		sim.generateLocalEvent(0)
+		return sim.CountCausalityViolations()
+	}
+
+	if missed, spurious := run(DurabilityNone); missed == 0 || spurious == 0 {
+		t.Errorf("Restarting from zero should cause both kinds of violations, got %d missed and %d spurious", missed, spurious)
+	}
+	if missed, spurious := run(DurabilityWAL); missed+spurious != 0 {
+		t.Errorf("A write-ahead log should prevent violations, got %d missed and %d spurious", missed, spurious)
+	}
+}
+
+// verifies the fault-free vector timestamps order events exactly like the
+// happened-before graph, across spawns, retirements and crashes.
+func TestActualVectorsMatchGraph(t *testing.T) {
+	sim := NewSimulatorWithCapacity(3, 6)
+	sim.SetDurability(DurabilityNone, 0)
+	sim.ScheduleCrash(100*time.Millisecond, 1)
+	sim.ScheduleRecovery(150*time.Millisecond, 1)
+	sim.RunDiscreteEventWithChurn(300*time.Millisecond, 0.2, 0.5, 0.05, 0.03, 2)
+
+	graph := sim.HappenedBeforeGraph()
+	actual := actualVectors(sim.Events)
+	for j, b := range sim.Events {
+		for i, a := range sim.Events {
+			if before := actual[j][a.ProcessID] >= actual[i][a.ProcessID] && i != j; before != graph.Precedes(i, j) {
+				t.Fatalf("Events %d (P%d) and %d (P%d): vectors say %v, graph disagrees", i, a.ProcessID, j, b.ProcessID, before)
+			}
+		}
+	}
+}
+
+// verifies invalid faults and durability settings panic.
+func TestCrashPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"crash out of bounds", func() { NewSimulator(2).CrashProcess(2) }},
+		{"crash twice", func() {
+			sim := NewSimulator(2)
+			sim.CrashProcess(0)
+			sim.CrashProcess(0)
+		}},
+		{"recover running", func() { NewSimulator(2).RecoverProcess(0) }},
+		{"negative time", func() { NewSimulator(2).ScheduleCrash(-1, 0) }},
+		{"schedule out of bounds", func() { NewSimulator(2).ScheduleRecovery(0, 2) }},
+		{"unknown policy", func() { NewSimulator(2).SetDurability("journal", 1) }},
+		{"no checkpoint interval", func() { NewSimulator(2).SetDurability(DurabilityCheckpoint, 0) }},
+		{"invalid probability", func() { NewSimulator(2).SetRandomCrashes(0.1, 1.5) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic")
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
+
+// verifies scheduled faults in a discrete-event run: the process is silent
+// while crashed, and only a lossy durability policy breaks causality.
+func TestCrashScheduleDiscrete(t *testing.T) {
+	run := func(policy string) *Simulator {
+		sim := NewSimulator(4)
+		sim.SetDurability(policy, 5)
+		sim.ScheduleCrash(100*time.Millisecond, 1)
+		sim.ScheduleRecovery(200*time.Millisecond, 1)
+		sim.ScheduleCrash(250*time.Millisecond, 2)
+		sim.RunDiscreteEvent(400*time.Millisecond, 0.3, 0.6, 8)
+		return sim
+	}
+
+	sim := run(DurabilityWAL)
+	stats := sim.GetCrashStatistics()
+	if stats["crashes"].(int) != 2 || stats["recoveries"].(int) != 1 || stats["crashed"].(int) != 1 {
+		t.Errorf("Expected 2 crashes, 1 recovery and 1 crashed process, got %v", stats)
+	}
+	if stats["rollback_events"].(int) != 0 {
+		t.Errorf("A write-ahead log should prevent rollbacks, got %v", stats)
+	}
+	if missed, spurious := sim.CountCausalityViolations(); missed+spurious != 0 {
+		t.Errorf("A write-ahead log should prevent violations, got %d missed and %d spurious", missed, spurious)
+	}
+
+	var crashedAt, recoveredAt int64
+	for _, e := range sim.Processes[1].Events {
+		switch e.EventType {
+		case "crash":
+			crashedAt = e.PhysicalTime
+		case "recover":
+			recoveredAt = e.PhysicalTime
+		default:
+			if crashedAt > 0 && recoveredAt == 0 {
+				t.Fatalf("Process 1 recorded a %s event while crashed", e.EventType)
+			}
+		}
+	}
+	if time.Duration(crashedAt) != 100*time.Millisecond || time.Duration(recoveredAt) != 200*time.Millisecond {
+		t.Errorf("Expected crash at 100ms and recovery at 200ms, got %v and %v",
+			time.Duration(crashedAt), time.Duration(recoveredAt))
+	}
+
+	lossy := run(DurabilityNone)
+	if stats := lossy.GetCrashStatistics(); stats["rollback_events"].(int) == 0 {
+		t.Errorf("Restarting from zero should be flagged, got %v", stats)
+	}
+	if _, spurious := lossy.CountCausalityViolations(); spurious == 0 {
+		t.Error("Restarting from zero should order concurrent events")
+	}
+}
+
+// verifies random crashes and recoveries are reproducible in discrete-event
+// runs.
+func TestRandomCrashesDeterministic(t *testing.T) {
+	trace := func() []byte {
+		sim := NewSimulator(4)
+		sim.SetDurability(DurabilityCheckpoint, 4)
+		sim.SetRandomCrashes(0.05, 0.2)
+		sim.RunDiscreteEvent(300*time.Millisecond, 0.3, 0.5, 6)
+
+		if sim.GetCrashStatistics()["crashes"].(int) == 0 {
+			t.Fatal("Expected random crashes")
+		}
+		var buf bytes.Buffer
+		sim.WriteTrace(&buf)
+		return buf.Bytes()
+	}
+
+	if !bytes.Equal(trace(), trace()) {
+		t.Error("Same seed should give the same trace")
+	}
+}
+
+// verifies the schedule is applied in a real-time run.
+func TestCrashScheduleRealTime(t *testing.T) {
+	sim := NewSimulator(3)
+	sim.SetDurability(DurabilityWAL, 0)
+	sim.ScheduleCrash(30*time.Millisecond, 1)
+	sim.ScheduleRecovery(80*time.Millisecond, 1)
+	sim.RunSimulation(120*time.Millisecond, 0.2, 0.6)
+
+	stats := sim.GetCrashStatistics()
+	if stats["crashes"].(int) != 1 || stats["recoveries"].(int) != 1 {
+		t.Errorf("Expected 1 crash and 1 recovery, got %v", stats)
+	}
+	if missed, spurious := sim.CountCausalityViolations(); missed+spurious != 0 {
+		t.Errorf("A write-ahead log should prevent violations, got %d missed and %d spurious", missed, spurious)
+	}
+}
@@ -0,0 +1,221 @@
+package simulator
+
+import (
+	"container/heap"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// virtual time a message spends in transit in discrete-event runs
+// without a network model
+const DiscreteLatency = time.Millisecond
+
+// kinds of discrete-event steps
+const (
+	stepGenerate  = iota // a process may generate an event
+	stepDeliver          // a message reaches its receiver
+	stepChurn            // a process may spawn or retire
+	stepSample           // physical clock skew is sampled
+	stepSync             // a clock synchronization round starts
+	stepPartition        // a scheduled partition starts or heals
+	stepFault            // a scheduled crash or recovery happens
+	stepSnapshot         // a scheduled snapshot starts
+)
+
+// a step scheduled at a point in virtual time
+type step struct {
+	at      time.Duration
+	seq     int // scheduling order, breaks ties between equal times
+	kind    int
+	process int // process concerned, or index of a partition change, fault or snapshot
+	msg     *Message
+}
+
+// priority queue of steps ordered by time, then scheduling order
+type stepQueue []*step
+
+func (q stepQueue) Len() int { return len(q) }
+
+func (q stepQueue) Less(i, j int) bool {
+	if q[i].at != q[j].at {
+		return q[i].at < q[j].at
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q stepQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *stepQueue) Push(x interface{}) { *q = append(*q, x.(*step)) }
+
+func (q *stepQueue) Pop() interface{} {
+	old := *q
+	st := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return st
+}
+
+// virtual clock and pending steps of a discrete-event run
+type scheduler struct {
+	now   time.Duration
+	seq   int
+	queue stepQueue
+	rng   *rand.Rand
+}
+
+// adds a step at virtual time at.
+func (sc *scheduler) schedule(at time.Duration, kind, process int, msg *Message) {
+	heap.Push(&sc.queue, &step{at: at, seq: sc.seq, kind: kind, process: process, msg: msg})
+	sc.seq++
+}
+
+// runs the simulation like RunSimulation, but in virtual time on a single
+// goroutine: every 10ms of virtual time each process generates an event,
+// messages arrive when the network model delivers them, and all randomness
+// comes from seed and the network model's seed.
+// the same seed and configuration record the same events in the same order,
+// so WriteTrace output is byte-identical. physical clocks read virtual time.
+// panics on invalid parameters or if the simulator already recorded events
+// or ran in discrete-event mode.
+func (s *Simulator) RunDiscreteEvent(duration time.Duration, localEventProb, sendEventProb float64, seed int64) {
+	checkRunParameters(duration, localEventProb, sendEventProb, 0, 0)
+	s.runDiscrete(duration, localEventProb, sendEventProb, 0, 0, seed)
+}
+
+// runs the simulation like RunSimulationWithChurn in discrete-event mode.
+// panics under the same conditions as RunDiscreteEvent, with CausalDelivery,
+// total-order multicast and mutual exclusion.
+func (s *Simulator) RunDiscreteEventWithChurn(duration time.Duration, localEventProb, sendEventProb, spawnProb, retireProb float64, seed int64) {
+	checkRunParameters(duration, localEventProb, sendEventProb, spawnProb, retireProb)
+	s.checkChurn(spawnProb, retireProb)
+	s.runDiscrete(duration, localEventProb, sendEventProb, spawnProb, retireProb, seed)
+}
+
+// processes steps in virtual time until duration, then delivers the
+// messages still in transit.
+func (s *Simulator) runDiscrete(duration time.Duration, localEventProb, sendEventProb, spawnProb, retireProb float64, seed int64) {
+	s.eventsMu.Lock()
+	recorded := len(s.Events)
+	s.eventsMu.Unlock()
+	if recorded > 0 || s.sched != nil {
+		panic("simulator: discrete-event runs need a fresh simulator")
+	}
+
+	sc := &scheduler{rng: rand.New(rand.NewSource(seed))}
+	s.procMu.Lock()
+	s.sched = sc
+	// physical clocks were created against the host clock
+	s.resetClocks()
+	s.procMu.Unlock()
+
+	// each process ticks with its own random phase, like independent tickers
+	start := func(processID int) {
+		phase := time.Duration(1 + sc.rng.Int63n(int64(tickInterval)))
+		sc.schedule(sc.now+phase, stepGenerate, processID, nil)
+	}
+	for i := 0; i < s.NumProcesses; i++ {
+		start(i)
+	}
+
+	if spawnProb > 0 || retireProb > 0 {
+		sc.schedule(tickInterval, stepChurn, -1, nil)
+	}
+
+	s.syncMu.Lock()
+	algorithm, interval := s.clockSync.algorithm, s.clockSync.interval
+	s.syncMu.Unlock()
+	s.sampleSkew(0)
+	sc.schedule(tickInterval, stepSample, -1, nil)
+	if algorithm != SyncNone {
+		sc.schedule(interval, stepSync, -1, nil)
+	}
+
+	s.startPartitions()
+	changes := s.partitionSchedule(duration)
+	for i, change := range changes {
+		sc.schedule(change.at, stepPartition, i, nil)
+	}
+
+	faults := s.faultSchedule(duration)
+	for i, fault := range faults {
+		sc.schedule(fault.at, stepFault, i, nil)
+	}
+
+	snapshots := s.snapshotSchedule(duration)
+	for i, snap := range snapshots {
+		sc.schedule(snap.at, stepSnapshot, i, nil)
+	}
+
+	for sc.queue.Len() > 0 {
+		st := heap.Pop(&sc.queue).(*step)
+		// only messages in transit are processed after the end
+		if st.at > duration && st.kind != stepDeliver {
+			continue
+		}
+		sc.now = st.at
+
+		switch st.kind {
+		case stepGenerate:
+			if p, _ := s.lookup(st.process); p.Retired() {
+				continue
+			}
+			s.generateEvent(st.process, sc.rng, localEventProb, sendEventProb)
+			sc.schedule(sc.now+tickInterval, stepGenerate, st.process, nil)
+		case stepDeliver:
+			s.receiveMessage(st.process, st.msg)
+		case stepChurn:
+			if childID, ok := s.churn(sc.rng, spawnProb, retireProb); ok {
+				start(childID)
+			}
+			sc.schedule(sc.now+tickInterval, stepChurn, -1, nil)
+		case stepSample:
+			s.sampleSkew(sc.now)
+			sc.schedule(sc.now+tickInterval, stepSample, -1, nil)
+		case stepSync:
+			s.startSyncRound(algorithm)
+			sc.schedule(sc.now+interval, stepSync, -1, nil)
+		case stepPartition:
+			s.applyPartitionChange(changes[st.process])
+		case stepFault:
+			s.applyFault(faults[st.process])
+		case stepSnapshot:
+			s.applySnapshot(snapshots[st.process])
+		}
+	}
+
+	s.checkTotalOrder()
+}
+
+// writes one line per recorded event, in recording order, with the
+// timestamps of all clocks. after discrete-event runs with the same seed
+// and configuration the output is byte-identical.
+func (s *Simulator) WriteTrace(w io.Writer) error {
+	s.eventsMu.Lock()
+	defer s.eventsMu.Unlock()
+
+	for _, e := range s.Events {
+		if _, err := fmt.Fprintln(w, traceLine(e)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formats an event for the trace, listing clocks by name.
+func traceLine(e Event) string {
+	line := fmt.Sprintf("P%d %s target=%d msg=%d lamport=%d vector=%v physical=%d",
+		e.ProcessID, e.EventType, e.TargetID, e.MessageID, e.Timestamp, e.VectorTime, e.PhysicalTime)
+
+	names := make([]string, 0, len(e.Clocks))
+	for name := range e.Clocks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		line += fmt.Sprintf(" %s=%v", name, e.Clocks[name])
+	}
+	return line
+}
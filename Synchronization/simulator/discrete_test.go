@@ -0,0 +1,147 @@
+package simulator
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	clock "github.com/simonnyman/DISY_Projects/Synchronization/clock"
+)
+
+// returns the trace of a discrete-event run with churn and drifting clocks.
+func discreteTrace(t *testing.T, seed int64) []byte {
+	sim := NewSimulatorWithCapacity(4, 8)
+	sim.SetPhysicalClocks(PhysicalConfig{MaxDrift: 1e-3, MaxOffset: time.Millisecond, Jitter: time.Microsecond, Seed: 9})
+	sim.SetClockSync(SyncBerkeley, 50*time.Millisecond)
+	sim.RunDiscreteEventWithChurn(300*time.Millisecond, 0.3, 0.5, 0.05, 0.03, seed)
+
+	var buf bytes.Buffer
+	if err := sim.WriteTrace(&buf); err != nil {
+		t.Fatalf("WriteTrace failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// verifies the same seed gives a byte-identical trace and another seed
+// a different one.
+func TestDiscreteEventDeterministic(t *testing.T) {
+	first := discreteTrace(t, 1)
+	if len(first) == 0 {
+		t.Fatal("Expected a non-empty trace")
+	}
+	for i := 0; i < 3; i++ {
+		if again := discreteTrace(t, 1); !bytes.Equal(first, again) {
+			t.Fatalf("Run %d with the same seed produced a different trace", i+2)
+		}
+	}
+	if other := discreteTrace(t, 2); bytes.Equal(first, other) {
+		t.Error("A different seed should produce a different trace")
+	}
+}
+
+// verifies every message sent is delivered once the run drains.
+func TestDiscreteEventDeliversAll(t *testing.T) {
+	sim := NewSimulator(5)
+	sim.RunDiscreteEvent(200*time.Millisecond, 0.2, 0.7, 42)
+
+	stats := sim.GetStatistics()
+	if stats["send_events"].(int) == 0 {
+		t.Fatal("Expected messages to be sent")
+	}
+	if stats["send_events"] != stats["receive_events"] {
+		t.Errorf("Expected every send received, got %d sends and %d receives",
+			stats["send_events"], stats["receive_events"])
+	}
+	if v := sim.CountTotalOrderViolations(); v != 0 {
+		t.Errorf("Expected no total order violations, got %d", v)
+	}
+}
+
+// verifies physical clocks follow virtual time, so long runs finish
+// without waiting for them.
+func TestDiscreteEventVirtualTime(t *testing.T) {
+	sim := NewSimulatorWithClocks(2, 2)
+	sim.RunDiscreteEvent(10*time.Second, 1, 0, 1)
+
+	if n := len(sim.Events); n != 2000 {
+		t.Errorf("Expected 2000 local events in 10s of virtual time, got %d", n)
+	}
+	first, last := sim.Events[0].PhysicalTime, sim.Events[len(sim.Events)-1].PhysicalTime
+	if span := time.Duration(last - first); span < 9900*time.Millisecond || span > 10*time.Second {
+		t.Errorf("Expected events to span about 10s of virtual time, got %v", span)
+	}
+}
+
+// verifies clock synchronization runs in virtual time, where the symmetric
+// latency makes Cristian's estimate exact.
+func TestDiscreteEventClockSync(t *testing.T) {
+	sim := NewSimulator(4)
+	sim.SetPhysicalClocks(PhysicalConfig{MaxOffset: 20 * time.Millisecond, Seed: 4})
+	sim.SetClockSync(SyncCristian, 50*time.Millisecond)
+	sim.RunDiscreteEvent(100*time.Millisecond, 0.3, 0.3, 1)
+
+	stats := sim.GetPhysicalClockStatistics()
+	if stats["sync_rounds"].(int) != 2 {
+		t.Errorf("Expected 2 sync rounds, got %d", stats["sync_rounds"])
+	}
+	if skew := stats["final_skew"].(time.Duration); skew != 0 {
+		t.Errorf("Expected clocks to agree exactly, skew %v", skew)
+	}
+}
+
+// verifies a thousand processes run with only the ground-truth clocks.
+func TestDiscreteEventThousandProcesses(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping large simulation in short mode")
+	}
+
+	sim := NewSimulatorWithClocks(1000, 1000)
+	sim.RunDiscreteEvent(30*time.Millisecond, 0.2, 0.2, 7)
+
+	stats := sim.GetStatistics()
+	if stats["local_events"].(int) < 300 {
+		t.Errorf("Expected about 600 local events, got %d", stats["local_events"])
+	}
+	if stats["send_events"] != stats["receive_events"] {
+		t.Errorf("Expected every send received, got %d sends and %d receives",
+			stats["send_events"], stats["receive_events"])
+	}
+}
+
+// verifies discrete-event runs need a fresh simulator and cannot be mixed
+// with real-time runs.
+func TestDiscreteEventPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"events recorded", func() {
+			sim := NewSimulator(2)
+			sim.generateLocalEvent(0)
+			sim.RunDiscreteEvent(time.Second, 0.5, 0.5, 1)
+		}},
+		{"second discrete run", func() {
+			sim := NewSimulatorWithClocks(2, 2, clock.HLC)
+			sim.RunDiscreteEvent(time.Second, 0, 0, 1)
+			sim.RunDiscreteEvent(time.Second, 0, 0, 1)
+		}},
+		{"real-time after discrete", func() {
+			sim := NewSimulator(2)
+			sim.RunDiscreteEvent(time.Second, 0, 0, 1)
+			sim.RunSimulation(time.Millisecond, 0, 0)
+		}},
+		{"invalid probability", func() { NewSimulator(2).RunDiscreteEvent(time.Second, 2, 0, 1) }},
+		{"zero duration", func() { NewSimulator(2).RunDiscreteEvent(0, 0.5, 0.5, 1) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic")
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
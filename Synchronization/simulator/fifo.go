@@ -0,0 +1,85 @@
+package simulator
+
+// counters of FIFO channels
+type fifoStats struct {
+	held      int // messages that overtook an earlier one and waited for it
+	discarded int // copies of messages already received
+}
+
+// numbers a message on the link from p to another process, counting from 1.
+// must be called with p.mu held.
+func (p *Process) nextLinkSeq(to int) int {
+	if p.linkSent == nil {
+		p.linkSent = make(map[int]int)
+	}
+	p.linkSent[to]++
+	return p.linkSent[to]
+}
+
+// returns the messages p may receive now that msg arrived, in sending
+// order. with FIFOChannels a message that overtook an earlier one on its
+// link is held until the earlier one arrives, and copies of messages
+// already received are discarded; otherwise, and for unnumbered clock
+// synchronization messages, msg is returned as it is.
+// must be called with p.mu held.
+func (s *Simulator) resequence(p *Process, msg *Message) []*Message {
+	if !s.FIFOChannels || msg.LinkSeq == 0 {
+		return []*Message{msg}
+	}
+	if p.linkNext == nil {
+		p.linkNext = make(map[int]int)
+		p.overtaken = make(map[int]map[int]*Message)
+	}
+	next := max(p.linkNext[msg.From], 1)
+	early := p.overtaken[msg.From]
+
+	s.fifoMu.Lock()
+	defer s.fifoMu.Unlock()
+	if _, ok := early[msg.LinkSeq]; ok || msg.LinkSeq < next {
+		s.fifo.discarded++
+		return nil
+	}
+	if msg.LinkSeq > next {
+		if early == nil {
+			early = make(map[int]*Message)
+			p.overtaken[msg.From] = early
+		}
+		early[msg.LinkSeq] = msg
+		s.fifo.held++
+		return nil
+	}
+
+	ready := []*Message{msg}
+	for next++; early[next] != nil; next++ {
+		ready = append(ready, early[next])
+		delete(early, next)
+	}
+	p.linkNext[msg.From] = next
+	return ready
+}
+
+// returns how many messages waited for an earlier one on their link, how
+// many duplicate copies were discarded, and how many messages are still
+// waiting because an earlier one was lost or is in transit.
+func (s *Simulator) GetFIFOStatistics() map[string]interface{} {
+	s.fifoMu.Lock()
+	st := s.fifo
+	s.fifoMu.Unlock()
+
+	pending := 0
+	s.procMu.RLock()
+	for _, p := range s.Processes {
+		p.mu.Lock()
+		for _, early := range p.overtaken {
+			pending += len(early)
+		}
+		p.mu.Unlock()
+	}
+	s.procMu.RUnlock()
+
+	return map[string]interface{}{
+		"held":      st.held,
+		"discarded": st.discarded,
+		"pending":   pending,
+	}
+}
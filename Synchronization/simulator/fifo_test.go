@@ -0,0 +1,70 @@
+package simulator
+
+import (
+	"testing"
+	"time"
+
+	network "github.com/simonnyman/DISY_Projects/Synchronization/network"
+)
+
+// verifies messages that overtake an earlier one on their link wait for it
+// and duplicate copies are discarded.
+func TestFIFOResequencing(t *testing.T) {
+	sim := NewSimulator(2)
+	sim.FIFOChannels = true
+	for i := 0; i < 3; i++ {
+		sim.sendMessage(0, 1)
+	}
+	inbox := sim.Processes[1].inbox
+	first, second, third := <-inbox, <-inbox, <-inbox
+
+	for _, msg := range []*Message{third, second, third, first, second} {
+		sim.receiveMessage(1, msg)
+	}
+
+	events := sim.Processes[1].Events
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 receive events, got %d", len(events))
+	}
+	for i, msg := range []*Message{first, second, third} {
+		if events[i].MessageID != msg.MessageID {
+			t.Errorf("Expected message %d at position %d, got %d", msg.MessageID, i, events[i].MessageID)
+		}
+	}
+	stats := sim.GetFIFOStatistics()
+	if stats["held"].(int) != 2 || stats["discarded"].(int) != 2 || stats["pending"].(int) != 0 {
+		t.Errorf("Expected 2 held and 2 discarded messages, got %v", stats)
+	}
+}
+
+// verifies FIFOChannels restores the order a reordering, duplicating
+// network breaks, and keeps the clocks consistent.
+func TestFIFOChannelsDiscrete(t *testing.T) {
+	run := func(fifo bool) *Simulator {
+		sim := NewSimulator(4)
+		sim.FIFOChannels = fifo
+		sim.SetNetwork(network.NewModel(network.Link{
+			Latency:      network.Exponential(2 * time.Millisecond),
+			Duplicate:    0.1,
+			Reorder:      0.3,
+			ReorderDelay: 10 * time.Millisecond,
+		}, 4))
+		sim.RunDiscreteEvent(300*time.Millisecond, 0.2, 0.6, 4)
+		return sim
+	}
+
+	if stats := run(false).GetNetworkStatistics(); stats["out_of_order"].(int) == 0 || stats["duplicates"].(int) == 0 {
+		t.Fatalf("Expected the network to reorder and duplicate, got %v", stats)
+	}
+
+	sim := run(true)
+	if stats := sim.GetNetworkStatistics(); stats["out_of_order"].(int) != 0 || stats["duplicates"].(int) != 0 || stats["lost"].(int) != 0 {
+		t.Errorf("Expected every message once and in order, got %v", stats)
+	}
+	if stats := sim.GetFIFOStatistics(); stats["held"].(int) == 0 || stats["discarded"].(int) == 0 || stats["pending"].(int) != 0 {
+		t.Errorf("Expected held and discarded messages, got %v", stats)
+	}
+	if missed, spurious := sim.CountCausalityViolations(); missed+spurious != 0 {
+		t.Errorf("Expected clocks to match causality, got %d missed and %d spurious", missed, spurious)
+	}
+}
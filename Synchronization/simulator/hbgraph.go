@@ -0,0 +1,237 @@
+package simulator
+
+import "fmt"
+
+// Edge is a direct happened-before dependency between two events of a
+// recorded run, given as indexes into the graph's events.
+type Edge struct {
+	From, To  int
+	MessageID int // message, spawn or retirement linking two processes, -1 for program order
+}
+
+// CausalGraph is the happened-before relation of a recorded run as a
+// directed acyclic graph: the events are its nodes, in recording order,
+// and every event depends on its process's previous event and on the
+// event that sent the message it received. built from the trace itself,
+// it stays exact where vector clocks were rolled back by crashes.
+type CausalGraph struct {
+	Events   []Event
+	preds    [][]Edge // incoming edges of each event
+	past     [][]uint64
+	position []int         // index of each event among its process's events
+	byProc   map[int][]int // events of each process, in order
+}
+
+// builds the happened-before graph of the events recorded so far.
+func (s *Simulator) HappenedBeforeGraph() *CausalGraph {
+	s.eventsMu.Lock()
+	events := make([]Event, len(s.Events))
+	copy(events, s.Events)
+	s.eventsMu.Unlock()
+
+	g := &CausalGraph{
+		Events:   events,
+		preds:    causalEdges(events),
+		position: make([]int, len(events)),
+		byProc:   make(map[int][]int),
+	}
+	for j, e := range events {
+		g.position[j] = len(g.byProc[e.ProcessID])
+		g.byProc[e.ProcessID] = append(g.byProc[e.ProcessID], j)
+	}
+	g.past = pastSets(g.preds)
+	return g
+}
+
+// returns, for each event, its direct dependencies: the previous event of
+// its process and the event that sent what it received. events are
+// recorded after everything they depend on, so one pass in recording
+// order suffices. a broadcast or multicast takes effect where it is
+// delivered, not where it is received into a hold-back queue, and a
+// process delivering its own multicast already depends on it by program
+// order.
+func causalEdges(events []Event) [][]Edge {
+	preds := make([][]Edge, len(events))
+	last := make(map[int]int)    // latest event of each process
+	sources := make(map[int]int) // event that sent each message, spawn or retirement
+
+	for j, e := range events {
+		if i, ok := last[e.ProcessID]; ok {
+			preds[j] = append(preds[j], Edge{From: i, To: j, MessageID: -1})
+		}
+		last[e.ProcessID] = j
+
+		switch e.EventType {
+		case "send", "spawn", "retire", "broadcast", "multicast":
+			sources[e.MessageID] = j
+		case "receive", "start", "join", "delivered":
+			if i, ok := sources[e.MessageID]; ok && events[i].ProcessID != e.ProcessID {
+				preds[j] = append(preds[j], Edge{From: i, To: j, MessageID: e.MessageID})
+			}
+		}
+	}
+	return preds
+}
+
+// returns, for each event, a bit set of the events that causally precede
+// it, given the direct dependencies of events in recording order.
+func pastSets(preds [][]Edge) [][]uint64 {
+	words := (len(preds) + 63) / 64
+	past := make([][]uint64, len(preds))
+	for j := range preds {
+		past[j] = make([]uint64, words)
+		for _, edge := range preds[j] {
+			i := edge.From
+			for w := range past[j] {
+				past[j][w] |= past[i][w]
+			}
+			past[j][i/64] |= 1 << (i % 64)
+		}
+	}
+	return past
+}
+
+// returns the index of the position-th event of a process, counting from 0.
+// panics if the process recorded no such event.
+func (g *CausalGraph) Index(processID, position int) int {
+	events := g.byProc[processID]
+	if position < 0 || position >= len(events) {
+		panic(fmt.Sprintf("simulator: P%d has no event %d", processID, position))
+	}
+	return events[position]
+}
+
+// returns the position of event e among its process's events, counting
+// from 0, the inverse of Index.
+// panics if e is out of range.
+func (g *CausalGraph) Position(e int) int {
+	g.check(e)
+	return g.position[e]
+}
+
+// returns every edge of the graph, ordered by the event they lead to.
+func (g *CausalGraph) Edges() []Edge {
+	var edges []Edge
+	for _, in := range g.preds {
+		edges = append(edges, in...)
+	}
+	return edges
+}
+
+// reports whether event a happened before event b.
+// panics if either index is out of range.
+func (g *CausalGraph) Precedes(a, b int) bool {
+	g.check(a)
+	g.check(b)
+	return g.precedes(a, b)
+}
+
+// reports whether event a happened before event b, without range checks.
+func (g *CausalGraph) precedes(a, b int) bool {
+	return g.past[b][a/64]&(1<<(a%64)) != 0
+}
+
+// returns the events that happened before e, in recording order.
+// panics if e is out of range.
+func (g *CausalGraph) Past(e int) []int {
+	g.check(e)
+	return g.filter(func(i int) bool { return g.precedes(i, e) })
+}
+
+// returns the events that e happened before, in recording order.
+// panics if e is out of range.
+func (g *CausalGraph) Future(e int) []int {
+	g.check(e)
+	return g.filter(func(i int) bool { return g.precedes(e, i) })
+}
+
+// returns the events concurrent with e: neither before nor after it.
+// panics if e is out of range.
+func (g *CausalGraph) Concurrent(e int) []int {
+	g.check(e)
+	return g.filter(func(i int) bool { return i != e && !g.precedes(i, e) && !g.precedes(e, i) })
+}
+
+// returns the edges no longer path between their ends implies, the
+// smallest graph with the same happened-before relation. a program order
+// edge drops out when the events are also linked by a round trip of
+// messages, a message edge when the receiver had already heard of the
+// send.
+func (g *CausalGraph) TransitiveReduction() []Edge {
+	var reduced []Edge
+	for _, in := range g.preds {
+		for _, edge := range in {
+			implied := false
+			for _, other := range in {
+				if other.From != edge.From && g.precedes(edge.From, other.From) {
+					implied = true
+					break
+				}
+			}
+			if !implied {
+				reduced = append(reduced, edge)
+			}
+		}
+	}
+	return reduced
+}
+
+// explains why a happened before b with the chain of message edges along
+// a path from a to b that crosses as few messages as possible, in the
+// order they were sent. the chain is empty when a precedes b on the same
+// process; returns false if a did not happen before b.
+// panics if either index is out of range.
+func (g *CausalGraph) Explain(a, b int) ([]Edge, bool) {
+	if !g.Precedes(a, b) {
+		return nil, false
+	}
+
+	// events are in topological order, so the fewest messages to reach
+	// each event between a and b follow from those of its dependencies.
+	hops := map[int]int{a: 0}
+	via := make(map[int]Edge)
+	for j := a + 1; j <= b; j++ {
+		if !g.precedes(a, j) || (j != b && !g.precedes(j, b)) {
+			continue
+		}
+		for _, edge := range g.preds[j] {
+			h, ok := hops[edge.From]
+			if !ok {
+				continue
+			}
+			if edge.MessageID >= 0 {
+				h++
+			}
+			if best, ok := hops[j]; !ok || h < best {
+				hops[j] = h
+				via[j] = edge
+			}
+		}
+	}
+
+	var chain []Edge
+	for i := b; i != a; i = via[i].From {
+		if edge := via[i]; edge.MessageID >= 0 {
+			chain = append([]Edge{edge}, chain...)
+		}
+	}
+	return chain, true
+}
+
+// returns the events satisfying keep, in recording order.
+func (g *CausalGraph) filter(keep func(i int) bool) []int {
+	var events []int
+	for i := range g.Events {
+		if keep(i) {
+			events = append(events, i)
+		}
+	}
+	return events
+}
+
+// panics unless e is an event of the graph.
+func (g *CausalGraph) check(e int) {
+	if e < 0 || e >= len(g.Events) {
+		panic(fmt.Sprintf("simulator: event %d out of range", e))
+	}
+}
@@ -0,0 +1,160 @@
+package simulator
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// verifies the graph of a message relayed from P0 through P1 to P2 and
+// answered back to P0: its edges, causal past, future and concurrent
+// events, the chain of messages explaining it, and the program order edge
+// the round trip makes redundant.
+func TestHappenedBeforeGraph(t *testing.T) {
+	sim := NewSimulator(3)
+	relay := func(from, to int) {
+		sim.sendMessage(from, to)
+		sim.receiveMessage(to, <-sim.Processes[to].inbox)
+	}
+	sim.generateLocalEvent(0) // 0
+	sim.generateLocalEvent(2) // 1
+	relay(0, 1)               // 2 send, 3 receive
+	relay(1, 2)               // 4 send, 5 receive
+	relay(2, 0)               // 6 send, 7 receive
+
+	g := sim.HappenedBeforeGraph()
+	if got, expected := fmt.Sprint(g.Edges()), "[{0 2 -1} {2 3 0} {3 4 -1} {1 5 -1} {4 5 1} {5 6 -1} {2 7 -1} {6 7 2}]"; got != expected {
+		t.Errorf("Expected edges %s, got %s", expected, got)
+	}
+	if g.Index(2, 1) != 5 || g.Position(5) != 1 {
+		t.Errorf("Expected P2's second event at index 5, got %d", g.Index(2, 1))
+	}
+
+	tests := []struct {
+		name     string
+		got      []int
+		expected string
+	}{
+		{"past of P2's receive", g.Past(5), "[0 1 2 3 4]"},
+		{"future of P0's local event", g.Future(0), "[2 3 4 5 6 7]"},
+		{"concurrent with P2's local event", g.Concurrent(1), "[0 2 3 4]"},
+		{"concurrent with P0's final receive", g.Concurrent(7), "[]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(tt.got); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+
+	chain, ok := g.Explain(0, 5)
+	if got := fmt.Sprint(chain); !ok || got != "[{2 3 0} {4 5 1}]" {
+		t.Errorf("Expected messages 0 and 1 to explain 0 → 5, got %s", got)
+	}
+	if chain, ok := g.Explain(0, 2); !ok || len(chain) != 0 {
+		t.Errorf("Expected program order to explain 0 → 2, got %v", chain)
+	}
+	if _, ok := g.Explain(1, 0); ok {
+		t.Error("Expected no explanation for concurrent events")
+	}
+
+	// the round trip 2 → 3 → … → 7 implies P0's own edge 2 → 7
+	if got := fmt.Sprint(g.TransitiveReduction()); got != "[{0 2 -1} {2 3 0} {3 4 -1} {1 5 -1} {4 5 1} {5 6 -1} {6 7 2}]" {
+		t.Errorf("Expected the edge 2 → 7 to be dropped, got %s", got)
+	}
+}
+
+// verifies the graph orders events exactly like vector clocks in runs
+// with point-to-point messages and churn, and with total-order multicast,
+// that past, future and concurrent events partition the rest of the run,
+// that the transitive reduction keeps the relation, and that every
+// explanation is a chain of messages leading from a to b.
+func TestHappenedBeforeGraphMatchesVectorClocks(t *testing.T) {
+	churn := NewSimulatorWithCapacity(3, 6)
+	churn.RunDiscreteEventWithChurn(400*time.Millisecond, 0.2, 0.4, 0.02, 0.02, 4)
+	multicast := NewSimulator(3)
+	multicast.SetTotalOrderMulticast(TotalOrderLamport)
+	multicast.RunDiscreteEvent(200*time.Millisecond, 0.2, 0.4, 4)
+
+	for name, sim := range map[string]*Simulator{"churn": churn, "multicast": multicast} {
+		t.Run(name, func(t *testing.T) {
+			g := sim.HappenedBeforeGraph()
+			n := len(g.Events)
+			if n < 50 {
+				t.Fatalf("Expected a longer run, got %d events", n)
+			}
+
+			for b := range g.Events {
+				for a := range g.Events {
+					if got, expected := g.Precedes(a, b), HappenedBefore(g.Events[a].VectorTime, g.Events[b].VectorTime); got != expected {
+						t.Fatalf("Events %d and %d: expected happened-before %v, got %v", a, b, expected, got)
+					}
+				}
+				if total := len(g.Past(b)) + len(g.Future(b)) + len(g.Concurrent(b)); total != n-1 {
+					t.Errorf("Event %d: expected %d other events, got %d", b, n-1, total)
+				}
+			}
+
+			preds := make([][]Edge, n)
+			for _, edge := range g.TransitiveReduction() {
+				preds[edge.To] = append(preds[edge.To], edge)
+			}
+			reduced := pastSets(preds)
+			for j := range g.past {
+				if fmt.Sprint(reduced[j]) != fmt.Sprint(g.past[j]) {
+					t.Fatalf("Event %d: reduction changed its causal past", j)
+				}
+			}
+
+			for b := 0; b < n; b += 7 {
+				for _, a := range g.Past(b) {
+					chain, ok := g.Explain(a, b)
+					if !ok {
+						t.Fatalf("Expected an explanation of %d → %d", a, b)
+					}
+					at := a
+					for _, edge := range chain {
+						if edge.MessageID < 0 || (edge.From != at && !g.Precedes(at, edge.From)) {
+							t.Fatalf("Explanation of %d → %d is not a chain of messages: %v", a, b, chain)
+						}
+						at = edge.To
+					}
+					if at != b && !g.Precedes(at, b) {
+						t.Fatalf("Explanation of %d → %d does not reach %d: %v", a, b, b, chain)
+					}
+					if len(chain) == 0 && g.Events[a].ProcessID != g.Events[b].ProcessID {
+						t.Fatalf("Explanation of %d → %d across processes needs a message", a, b)
+					}
+				}
+			}
+		})
+	}
+}
+
+// verifies indexes outside the run panic.
+func TestHappenedBeforeGraphPanics(t *testing.T) {
+	sim := NewSimulator(2)
+	sim.generateLocalEvent(0)
+	g := sim.HappenedBeforeGraph()
+
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"past", func() { g.Past(1) }},
+		{"precedes", func() { g.Precedes(0, -1) }},
+		{"explain", func() { g.Explain(3, 0) }},
+		{"position", func() { g.Position(1) }},
+		{"index", func() { g.Index(1, 0) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic")
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package simulator
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Cut is a global state of a recorded run given as a prefix of every
+// process's events: cut[i] events of process i are included, so cut[i] is
+// also the index of its first event beyond the cut.
+type Cut []int
+
+// LatticeBounds restricts the enumeration of consistent cuts.
+type LatticeBounds struct {
+	Lower   Cut // smallest cut, nil for the initial state
+	Upper   Cut // largest cut, nil for the final state
+	MaxCuts int // stop after this many cuts, 0 for no limit
+}
+
+// Lattice holds the consistent cuts between two bounds, ordered by level:
+// the cuts in level k contain k events beyond the lower bound. a run
+// moves from one level to the next by one event, so the width of a level
+// is how many global states a run could have passed through at that
+// point.
+type Lattice struct {
+	Levels    [][]Cut
+	Truncated bool // enumeration stopped at MaxCuts
+}
+
+// returns the number of consistent cuts enumerated.
+func (l *Lattice) Size() int {
+	size := 0
+	for _, level := range l.Levels {
+		size += len(level)
+	}
+	return size
+}
+
+// returns the number of cuts in the widest level.
+func (l *Lattice) Width() int {
+	width := 0
+	for _, level := range l.Levels {
+		width = max(width, len(level))
+	}
+	return width
+}
+
+// reports whether a cut of the recorded run is consistent: no process's
+// part has seen an event of another process beyond that process's part,
+// judged by the vector timestamps of the last event of each part, so no
+// receive is included without its send. assumes vector clocks were never
+// rolled back by a crash.
+// panics if the cut does not give one index per process or an index is
+// outside the process's events.
+func (s *Simulator) IsConsistentCut(cut Cut) bool {
+	trace := s.processEvents()
+	checkCut(trace, cut)
+	return consistentCut(trace, cut)
+}
+
+// enumerates the consistent cuts of the recorded run between the bounds,
+// level by level from the lower bound, stopping early once MaxCuts cuts
+// were found. assumes vector clocks were never rolled back by a crash.
+// panics if a bound does not give one index per process, is out of range
+// or inconsistent, or if Lower is not contained in Upper.
+func (s *Simulator) EnumerateLattice(bounds LatticeBounds) *Lattice {
+	trace := s.processEvents()
+	lower, upper := latticeBounds(trace, bounds)
+
+	lattice := &Lattice{}
+	lattice.Truncated = !walkLattice(trace, lower, upper, nil, func(level []Cut) bool {
+		if left := bounds.MaxCuts - lattice.Size(); bounds.MaxCuts > 0 && len(level) > left {
+			if left > 0 {
+				lattice.Levels = append(lattice.Levels, level[:left])
+			}
+			return false
+		}
+		lattice.Levels = append(lattice.Levels, level)
+		return true
+	})
+	return lattice
+}
+
+// returns the events of every process, in process ID order.
+func (s *Simulator) processEvents() [][]Event {
+	s.procMu.RLock()
+	defer s.procMu.RUnlock()
+
+	trace := make([][]Event, s.NumProcesses)
+	for i, p := range s.Processes {
+		p.mu.Lock()
+		trace[i] = p.Events
+		p.mu.Unlock()
+	}
+	return trace
+}
+
+// returns the lower and upper bound of an enumeration, defaulting to the
+// initial and final state.
+// panics if a bound is invalid or inconsistent, or Lower is not contained
+// in Upper.
+func latticeBounds(trace [][]Event, bounds LatticeBounds) (Cut, Cut) {
+	lower, upper := bounds.Lower, bounds.Upper
+	if lower == nil {
+		lower = make(Cut, len(trace))
+	}
+	if upper == nil {
+		upper = make(Cut, len(trace))
+		for i, events := range trace {
+			upper[i] = len(events)
+		}
+	}
+	checkCut(trace, lower)
+	checkCut(trace, upper)
+	if !consistentCut(trace, lower) || !consistentCut(trace, upper) {
+		panic("simulator: lattice bounds must be consistent cuts")
+	}
+	for i := range lower {
+		if lower[i] > upper[i] {
+			panic("simulator: lower bound must be contained in the upper bound")
+		}
+	}
+	return append(Cut(nil), lower...), upper
+}
+
+// panics unless cut gives one index per process within its events.
+func checkCut(trace [][]Event, cut Cut) {
+	if len(cut) != len(trace) {
+		panic("simulator: a cut needs one index per process")
+	}
+	for i, n := range cut {
+		if n < 0 || n > len(trace[i]) {
+			panic(fmt.Sprintf("simulator: cut index %d out of range for P%d", n, i))
+		}
+	}
+}
+
+// returns the own vector entry of process i's last event in the cut, or 0
+// if the cut contains none of its events.
+func frontier(trace [][]Event, cut Cut, i int) int64 {
+	if cut[i] == 0 {
+		return 0
+	}
+	return trace[i][cut[i]-1].VectorTime[i]
+}
+
+// reports whether no process's part of the cut has seen events of another
+// process beyond that process's part.
+func consistentCut(trace [][]Event, cut Cut) bool {
+	for j := range trace {
+		if cut[j] == 0 {
+			continue
+		}
+		seen := trace[j][cut[j]-1].VectorTime
+		for i := range trace {
+			if i != j && seen[i] > frontier(trace, cut, i) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// reports whether process i's next event after a consistent cut can be
+// added to it: everything the event has seen of other processes is in
+// the cut.
+func canAdvance(trace [][]Event, cut Cut, i int) bool {
+	seen := trace[i][cut[i]].VectorTime
+	for j := range trace {
+		if j != i && seen[j] > frontier(trace, cut, j) {
+			return false
+		}
+	}
+	return true
+}
+
+// visits the consistent cuts between lower and upper level by level,
+// breadth first. with keep set, only cuts it accepts are visited and
+// extended, which restricts the walk to paths through such cuts; lower
+// must be accepted to start. stops and returns false when visit does;
+// returns true once every level was visited.
+func walkLattice(trace [][]Event, lower, upper Cut, keep func(Cut) bool, visit func(level []Cut) bool) bool {
+	if keep != nil && !keep(lower) {
+		return true
+	}
+	level := []Cut{lower}
+	for len(level) > 0 {
+		if !visit(level) {
+			return false
+		}
+
+		var next []Cut
+		seen := make(map[string]bool)
+		for _, cut := range level {
+			for i := range cut {
+				if cut[i] == upper[i] || !canAdvance(trace, cut, i) {
+					continue
+				}
+				succ := append(Cut(nil), cut...)
+				succ[i]++
+				key := cutKey(succ)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				if keep == nil || keep(succ) {
+					next = append(next, succ)
+				}
+			}
+		}
+		level = next
+	}
+	return true
+}
+
+// encodes a cut as a map key.
+func cutKey(cut Cut) string {
+	buf := make([]byte, 0, len(cut)*binary.MaxVarintLen32)
+	for _, n := range cut {
+		buf = binary.AppendUvarint(buf, uint64(n))
+	}
+	return string(buf)
+}
@@ -0,0 +1,155 @@
+package simulator
+
+import (
+	"testing"
+	"time"
+)
+
+// verifies a cut with a receive but not its send is inconsistent.
+func TestConsistentCut(t *testing.T) {
+	sim := NewSimulator(2)
+	sim.sendMessage(0, 1)
+	sim.receiveMessage(1, <-sim.Processes[1].inbox)
+
+	tests := []struct {
+		cut        Cut
+		consistent bool
+	}{
+		{Cut{0, 0}, true},
+		{Cut{1, 0}, true},
+		{Cut{0, 1}, false},
+		{Cut{1, 1}, true},
+	}
+	for _, tt := range tests {
+		if got := sim.IsConsistentCut(tt.cut); got != tt.consistent {
+			t.Errorf("Cut %v: expected consistent %v, got %v", tt.cut, tt.consistent, got)
+		}
+	}
+}
+
+// verifies the lattice of two independent processes is the full grid of
+// their events, and a message removes the states where it was received
+// but not sent.
+func TestLatticeShape(t *testing.T) {
+	sim := NewSimulator(2)
+	for i := 0; i < 2; i++ {
+		sim.generateLocalEvent(0)
+		sim.generateLocalEvent(1)
+	}
+	lattice := sim.EnumerateLattice(LatticeBounds{})
+	if lattice.Size() != 9 || lattice.Width() != 3 || len(lattice.Levels) != 5 || lattice.Truncated {
+		t.Errorf("Expected a 3x3 grid in 5 levels, got %v", lattice.Levels)
+	}
+
+	sim = NewSimulator(2)
+	sim.generateLocalEvent(1)
+	sim.sendMessage(0, 1)
+	sim.receiveMessage(1, <-sim.Processes[1].inbox)
+	lattice = sim.EnumerateLattice(LatticeBounds{})
+	// states (0,0) (1,0) (0,1) (1,1) (1,2); (0,2) received before sending
+	if lattice.Size() != 5 || lattice.Width() != 2 {
+		t.Errorf("Expected 5 states at most 2 wide, got %v", lattice.Levels)
+	}
+}
+
+// verifies the bounds restrict and truncate the enumeration.
+func TestLatticeBounds(t *testing.T) {
+	sim := NewSimulator(3)
+	for i := 0; i < 3; i++ {
+		for id := 0; id < 3; id++ {
+			sim.generateLocalEvent(id)
+		}
+	}
+
+	between := sim.EnumerateLattice(LatticeBounds{Lower: Cut{1, 1, 1}, Upper: Cut{2, 2, 3}})
+	if between.Size() != 2*2*3 || between.Levels[0][0][0] != 1 || between.Truncated {
+		t.Errorf("Expected the 2x2x3 states between the bounds, got %v", between.Levels)
+	}
+
+	truncated := sim.EnumerateLattice(LatticeBounds{MaxCuts: 10})
+	if truncated.Size() != 10 || !truncated.Truncated {
+		t.Errorf("Expected 10 states and truncation, got %d and %v", truncated.Size(), truncated.Truncated)
+	}
+	if full := sim.EnumerateLattice(LatticeBounds{MaxCuts: 64}); full.Size() != 64 || full.Truncated {
+		t.Errorf("Expected all 64 states without truncation, got %d and %v", full.Size(), full.Truncated)
+	}
+}
+
+// verifies the enumeration of a random run finds exactly the consistent
+// cuts a brute-force search over all cuts finds, and that snapshots are
+// among them.
+func TestLatticeDiscrete(t *testing.T) {
+	sim := NewSimulator(3)
+	sim.FIFOChannels = true
+	sim.ScheduleSnapshot(15*time.Millisecond, 0)
+	sim.RunDiscreteEvent(40*time.Millisecond, 0.3, 0.5, 9)
+
+	lattice := sim.EnumerateLattice(LatticeBounds{})
+	found := make(map[string]bool)
+	for level, cuts := range lattice.Levels {
+		for _, cut := range cuts {
+			if cut[0]+cut[1]+cut[2] != level || !sim.IsConsistentCut(cut) {
+				t.Fatalf("Cut %v at level %d should be consistent and contain %d events", cut, level, level)
+			}
+			found[cutKey(cut)] = true
+		}
+	}
+
+	consistent := 0
+	n := Cut{len(sim.Processes[0].Events), len(sim.Processes[1].Events), len(sim.Processes[2].Events)}
+	for a := 0; a <= n[0]; a++ {
+		for b := 0; b <= n[1]; b++ {
+			for c := 0; c <= n[2]; c++ {
+				if cut := (Cut{a, b, c}); sim.IsConsistentCut(cut) {
+					consistent++
+					if !found[cutKey(cut)] {
+						t.Errorf("Consistent cut %v was not enumerated", cut)
+					}
+				}
+			}
+		}
+	}
+	if consistent != lattice.Size() || consistent == (n[0]+1)*(n[1]+1)*(n[2]+1) {
+		t.Errorf("Expected %d consistent cuts, fewer than all, got %d", consistent, lattice.Size())
+	}
+
+	snap := sim.Snapshots()[0]
+	var cut Cut
+	for _, state := range snap.States {
+		cut = append(cut, state.Events)
+	}
+	if !sim.IsConsistentCut(cut) {
+		t.Errorf("Snapshot cut %v should be consistent", cut)
+	}
+}
+
+// verifies invalid cuts and bounds panic.
+func TestLatticePanics(t *testing.T) {
+	traced := func() *Simulator {
+		sim := NewSimulator(2)
+		sim.sendMessage(0, 1)
+		sim.receiveMessage(1, <-sim.Processes[1].inbox)
+		return sim
+	}
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"short cut", func() { traced().IsConsistentCut(Cut{1}) }},
+		{"index out of range", func() { traced().IsConsistentCut(Cut{2, 0}) }},
+		{"negative index", func() { traced().IsConsistentCut(Cut{-1, 0}) }},
+		{"inconsistent bound", func() { traced().EnumerateLattice(LatticeBounds{Lower: Cut{0, 1}}) }},
+		{"crossed bounds", func() { traced().EnumerateLattice(LatticeBounds{Lower: Cut{1, 0}, Upper: Cut{0, 0}}) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic")
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
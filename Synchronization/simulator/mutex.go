@@ -0,0 +1,486 @@
+package simulator
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+
+	mutex "github.com/simonnyman/DISY_Projects/Synchronization/mutex"
+)
+
+// mutual exclusion algorithms
+const (
+	MutexNone           = ""                // processes never request the critical section
+	MutexLamport        = "lamport"         // Lamport's request queue ordered by Lamport timestamps
+	MutexRicartAgrawala = "ricart-agrawala" // permission from every other process
+	MutexSuzukiKasami   = "suzuki-kasami"   // a token passed on broadcast requests
+	MutexMaekawa        = "maekawa"         // votes from a grid quorum of about 2√n processes
+	MutexMaekawaNaive   = "maekawa-naive"   // Maekawa without deadlock resolution
+)
+
+// configuration and counters of the mutual exclusion workload
+type exclusionState struct {
+	algorithm   string
+	requestProb float64
+	hold        time.Duration
+	messages    int            // protocol messages sent
+	entries     []csEntry      // requests in the order they were made
+	inFlight    map[int][2]int // packets sent and not yet received, by message ID, with sender and receiver
+}
+
+// one request for the critical section, in true time
+type csEntry struct {
+	process   int
+	requested time.Duration
+	entered   time.Duration
+	exited    time.Duration
+	granted   bool
+	done      bool
+}
+
+// makes every process compete for one critical section with the given
+// algorithm (MutexLamport, MutexRicartAgrawala, MutexSuzukiKasami,
+// MutexMaekawa or MutexMaekawaNaive), or turns the workload off
+// (MutexNone). the workload is the same for every
+// algorithm: every 10ms a process outside the section requests it with
+// requestProb, and a process that has held it for at least hold leaves it;
+// the tick is then used up. processes record "enter" and "exit" events, and
+// protocol messages are application messages with send and receive events
+// that carry full vectors even with DifferentialVectors set. Lamport's and
+// Maekawa's algorithms need FIFO links, and the process group must stay fixed.
+// set it before running the simulation.
+// panics if the algorithm is unknown, requestProb is not between 0 and 1,
+// or hold is negative.
+func (s *Simulator) SetMutualExclusion(algorithm string, requestProb float64, hold time.Duration) {
+	switch algorithm {
+	case MutexNone, MutexLamport, MutexRicartAgrawala, MutexSuzukiKasami, MutexMaekawa, MutexMaekawaNaive:
+	default:
+		panic("simulator: unknown mutual exclusion algorithm " + algorithm)
+	}
+	if requestProb < 0 || requestProb > 1 {
+		panic("simulator: requestProb must be between 0 and 1")
+	}
+	if hold < 0 {
+		panic("simulator: hold must not be negative")
+	}
+
+	s.exclusionMu.Lock()
+	defer s.exclusionMu.Unlock()
+	s.exclusion.algorithm = algorithm
+	s.exclusion.requestProb = requestProb
+	s.exclusion.hold = hold
+}
+
+// returns the configured mutual exclusion algorithm.
+func (s *Simulator) exclusionAlgorithm() string {
+	s.exclusionMu.Lock()
+	defer s.exclusionMu.Unlock()
+	return s.exclusion.algorithm
+}
+
+// returns the mutual exclusion instance of p, creating it on first use.
+// must be called with p.mu held.
+func (s *Simulator) locker(p *Process) mutex.Protocol {
+	if p.locker == nil {
+		s.procMu.RLock()
+		n := s.NumProcesses
+		s.procMu.RUnlock()
+		switch s.exclusionAlgorithm() {
+		case MutexRicartAgrawala:
+			p.locker = mutex.NewRicartAgrawala(p.ID, n)
+		case MutexSuzukiKasami:
+			p.locker = mutex.NewSuzukiKasami(p.ID, n)
+		case MutexMaekawa:
+			p.locker = mutex.NewMaekawa(p.ID, n)
+		case MutexMaekawaNaive:
+			p.locker = mutex.NewNaiveMaekawa(p.ID, n)
+		default:
+			p.locker = mutex.NewLamport(p.ID, n)
+		}
+	}
+	return p.locker
+}
+
+// lets a process leave the critical section once it has held it long
+// enough, or request it with the configured probability. returns true if
+// the tick was used.
+func (s *Simulator) exclusionStep(processID int, rng *rand.Rand) bool {
+	s.exclusionMu.Lock()
+	algorithm, requestProb, hold := s.exclusion.algorithm, s.exclusion.requestProb, s.exclusion.hold
+	s.exclusionMu.Unlock()
+	if algorithm == MutexNone {
+		return false
+	}
+
+	p, _ := s.lookup(processID)
+	p.mu.Lock()
+	if p.Retired() || p.Crashed() {
+		p.mu.Unlock()
+		return false
+	}
+
+	now := time.Duration(s.trueTime())
+	l := s.locker(p)
+	used := false
+	switch {
+	case l.Holding():
+		if now-s.csEntryOf(p).entered >= hold {
+			s.exitCriticalSection(p, now)
+			used = true
+		}
+	case !l.Waiting() && rng.Float64() < requestProb:
+		s.exclusionMu.Lock()
+		p.csEntry = len(s.exclusion.entries)
+		s.exclusion.entries = append(s.exclusion.entries, csEntry{process: p.ID, requested: now})
+		s.exclusionMu.Unlock()
+
+		packets, entered := l.Request()
+		s.postMutex(p, packets)
+		if entered {
+			s.enterCriticalSection(p, now)
+		}
+		used = true
+	}
+	p.mu.Unlock()
+
+	s.flush(p)
+	return used
+}
+
+// returns a copy of the current request of p.
+// must be called with p.mu held.
+func (s *Simulator) csEntryOf(p *Process) csEntry {
+	s.exclusionMu.Lock()
+	defer s.exclusionMu.Unlock()
+	return s.exclusion.entries[p.csEntry]
+}
+
+// hands a mutual exclusion packet to p's instance, after its receive event
+// has been recorded, and enters the critical section if it may.
+// must be called with p.mu held.
+func (s *Simulator) receiveMutex(p *Process, msg *Message) {
+	s.exclusionMu.Lock()
+	delete(s.exclusion.inFlight, msg.MessageID)
+	s.exclusionMu.Unlock()
+
+	packets, entered := s.locker(p).Receive(*msg.Mutex)
+	s.postMutex(p, packets)
+	if entered {
+		s.enterCriticalSection(p, time.Duration(s.trueTime()))
+	}
+}
+
+// records a send event for each packet and queues it in p's outbox.
+// must be called with p.mu held.
+func (s *Simulator) postMutex(p *Process, packets []mutex.Packet) {
+	ids := make([]int, len(packets))
+	for i := range packets {
+		pkt := &packets[i]
+		lt := p.LamportClock.Send()
+		vt := p.VectorClock.Send()
+		times := p.sendClocks()
+		msgID := s.nextMessageID()
+		ids[i] = msgID
+
+		e := Event{
+			ProcessID:    p.ID,
+			EventType:    "send",
+			Timestamp:    lt,
+			VectorTime:   vt,
+			Clocks:       times,
+			PhysicalTime: p.PhysicalClock.Now(),
+			TargetID:     pkt.To,
+			MessageID:    msgID,
+		}
+		s.recordEvent(p, e)
+		s.recordPiggyback(8 * len(vt))
+
+		p.outbox = append(p.outbox, &Message{
+			From:        p.ID,
+			To:          pkt.To,
+			LamportTime: lt,
+			VectorTime:  vt,
+			Clocks:      times,
+			Replica:     p.Replica,
+			MessageID:   msgID,
+			Mutex:       pkt,
+			LinkSeq:     p.nextLinkSeq(pkt.To),
+		})
+	}
+
+	s.exclusionMu.Lock()
+	defer s.exclusionMu.Unlock()
+	s.exclusion.messages += len(packets)
+	if s.exclusion.inFlight == nil {
+		s.exclusion.inFlight = make(map[int][2]int)
+	}
+	for i, id := range ids {
+		s.exclusion.inFlight[id] = [2]int{p.ID, packets[i].To}
+	}
+}
+
+// records an "enter" event at p.
+// must be called with p.mu held.
+func (s *Simulator) enterCriticalSection(p *Process, now time.Duration) {
+	s.recordLocal(p, "enter")
+
+	s.exclusionMu.Lock()
+	defer s.exclusionMu.Unlock()
+	entry := &s.exclusion.entries[p.csEntry]
+	entry.entered = now
+	entry.granted = true
+}
+
+// records an "exit" event at p and releases the critical section.
+// must be called with p.mu held.
+func (s *Simulator) exitCriticalSection(p *Process, now time.Duration) {
+	s.recordLocal(p, "exit")
+	s.postMutex(p, p.locker.Release())
+
+	s.exclusionMu.Lock()
+	defer s.exclusionMu.Unlock()
+	entry := &s.exclusion.entries[p.csEntry]
+	entry.exited = now
+	entry.done = true
+}
+
+// records a local event of the given type at p.
+// must be called with p.mu held.
+func (s *Simulator) recordLocal(p *Process, eventType string) {
+	e := Event{
+		ProcessID:    p.ID,
+		EventType:    eventType,
+		Timestamp:    p.LamportClock.Tick(),
+		VectorTime:   p.VectorClock.Tick(),
+		Clocks:       p.tickClocks(),
+		PhysicalTime: p.PhysicalClock.Now(),
+		TargetID:     -1,
+		MessageID:    -1,
+	}
+	s.recordEvent(p, e)
+}
+
+// a critical section as recorded in the events; exit is nil while it
+// is still held
+type criticalSection struct {
+	enter *Event
+	exit  *Event
+}
+
+// returns the recorded critical sections in recording order of their
+// enter events.
+func (s *Simulator) criticalSections() []criticalSection {
+	var sections []criticalSection
+	open := make(map[int]int) // section each process is in
+	for i := range s.Events {
+		e := &s.Events[i]
+		switch e.EventType {
+		case "enter":
+			open[e.ProcessID] = len(sections)
+			sections = append(sections, criticalSection{enter: e})
+		case "exit":
+			if j, ok := open[e.ProcessID]; ok {
+				sections[j].exit = e
+				delete(open, e.ProcessID)
+			}
+		}
+	}
+	return sections
+}
+
+// counts pairs of critical sections that overlap in vector-clock time:
+// neither left before the other entered. stays zero unless mutual
+// exclusion failed or clocks were rolled back.
+func (s *Simulator) CountMutexViolations() int {
+	sections := s.criticalSections()
+	before := func(a, b criticalSection) bool {
+		return a.exit != nil && HappenedBefore(a.exit.VectorTime, b.enter.VectorTime)
+	}
+
+	violations := 0
+	for i := range sections {
+		for j := i + 1; j < len(sections); j++ {
+			if !before(sections[i], sections[j]) && !before(sections[j], sections[i]) {
+				violations++
+			}
+		}
+	}
+	return violations
+}
+
+// returns the deadlocks among processes waiting for votes in a quorum
+// algorithm, each as the sorted IDs of its processes; nil for the other
+// algorithms. a waiting process waits for every process that holds the
+// vote of one of its quorum members, as long as that process is waiting
+// itself, and a deadlock is a strongly connected group of this wait-for
+// relation. without deadlock resolution such a group waits forever.
+// processes that still have packets to send or receive are left out,
+// since those packets may yet end their wait: a real-time run stops
+// before its outboxes and inboxes are drained, while a discrete-event run
+// delivers what is in transit, so only lost packets keep processes out.
+func (s *Simulator) FindMutexDeadlocks() [][]int {
+	s.exclusionMu.Lock()
+	unsettled := make(map[int]bool)
+	for _, link := range s.exclusion.inFlight {
+		unsettled[link[0]] = true
+		unsettled[link[1]] = true
+	}
+	s.exclusionMu.Unlock()
+
+	voters := make(map[int]mutex.Voter)
+	var waiting []int
+	s.procMu.RLock()
+	for _, p := range s.Processes {
+		p.mu.Lock()
+		if voter, ok := p.locker.(mutex.Voter); ok {
+			voters[p.ID] = voter
+			if p.locker.Waiting() && !unsettled[p.ID] {
+				waiting = append(waiting, p.ID)
+			}
+		}
+		p.mu.Unlock()
+	}
+	s.procMu.RUnlock()
+	isWaiting := make(map[int]bool)
+	for _, id := range waiting {
+		isWaiting[id] = true
+	}
+
+	waitsFor := make(map[int][]int)
+	for _, id := range waiting {
+		votes := make(map[int]bool)
+		for _, q := range voters[id].Votes() {
+			votes[q] = true
+		}
+		for _, q := range voters[id].Quorum() {
+			voter, ok := voters[q]
+			if !ok {
+				continue // never requested nor asked for its vote
+			}
+			if holder := voter.VotedFor(); !votes[q] && holder != id && isWaiting[holder] {
+				waitsFor[id] = append(waitsFor[id], holder)
+			}
+		}
+	}
+	return waitCycles(waiting, waitsFor)
+}
+
+// returns the strongly connected groups of more than one process in a
+// wait-for graph, found with Tarjan's algorithm, in order of their
+// smallest ID.
+func waitCycles(nodes []int, edges map[int][]int) [][]int {
+	index := make(map[int]int)
+	low := make(map[int]int)
+	onStack := make(map[int]bool)
+	var stack []int
+	var cycles [][]int
+
+	var visit func(v int)
+	visit = func(v int) {
+		index[v] = len(index)
+		low[v] = index[v]
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range edges[v] {
+			if _, seen := index[w]; !seen {
+				visit(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+		if low[v] != index[v] {
+			return
+		}
+		var group []int
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			group = append(group, w)
+			if w == v {
+				break
+			}
+		}
+		if len(group) > 1 {
+			sort.Ints(group)
+			cycles = append(cycles, group)
+		}
+	}
+
+	for _, v := range nodes {
+		if _, seen := index[v]; !seen {
+			visit(v)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+// returns the algorithm, requests made, entries granted and requests still
+// waiting, protocol messages sent and per entry, the wait from request to
+// entry, the synchronization delay between one process leaving and a
+// waiting one entering, all in true time, the overlapping critical
+// sections found by CountMutexViolations, and the processes in the
+// deadlocks found by FindMutexDeadlocks.
+func (s *Simulator) GetMutexStatistics() map[string]interface{} {
+	s.exclusionMu.Lock()
+	algorithm, messages := s.exclusion.algorithm, s.exclusion.messages
+	entries := append([]csEntry(nil), s.exclusion.entries...)
+	s.exclusionMu.Unlock()
+
+	var granted []csEntry
+	var totalWait, maxWait time.Duration
+	for _, entry := range entries {
+		if entry.granted {
+			granted = append(granted, entry)
+			wait := entry.entered - entry.requested
+			totalWait += wait
+			maxWait = max(maxWait, wait)
+		}
+	}
+
+	// the next process enters after the previous one left while it was
+	// already waiting
+	sort.Slice(granted, func(i, j int) bool { return granted[i].entered < granted[j].entered })
+	handovers := 0
+	var totalDelay, maxDelay time.Duration
+	for i := 1; i < len(granted); i++ {
+		prev, next := granted[i-1], granted[i]
+		if prev.done && next.requested <= prev.exited {
+			delay := next.entered - prev.exited
+			totalDelay += delay
+			maxDelay = max(maxDelay, delay)
+			handovers++
+		}
+	}
+
+	deadlocked := 0
+	for _, cycle := range s.FindMutexDeadlocks() {
+		deadlocked += len(cycle)
+	}
+
+	perEntry, avgWait, avgDelay := 0.0, time.Duration(0), time.Duration(0)
+	if len(granted) > 0 {
+		perEntry = float64(messages) / float64(len(granted))
+		avgWait = totalWait / time.Duration(len(granted))
+	}
+	if handovers > 0 {
+		avgDelay = totalDelay / time.Duration(handovers)
+	}
+
+	return map[string]interface{}{
+		"algorithm":          algorithm,
+		"requests":           len(entries),
+		"entries":            len(granted),
+		"waiting":            len(entries) - len(granted),
+		"messages":           messages,
+		"messages_per_entry": perEntry,
+		"avg_wait":           avgWait,
+		"max_wait":           maxWait,
+		"avg_sync_delay":     avgDelay,
+		"max_sync_delay":     maxDelay,
+		"violations":         s.CountMutexViolations(),
+		"deadlocked":         deadlocked,
+	}
+}
@@ -0,0 +1,263 @@
+package simulator
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	network "github.com/simonnyman/DISY_Projects/Synchronization/network"
+)
+
+// verifies every algorithm in a discrete-event run: critical sections
+// never overlap in vector time, and no request costs more messages than
+// the algorithm's bound.
+func TestMutexDiscrete(t *testing.T) {
+	tests := []struct {
+		algorithm  string
+		perRequest int // messages per request in a group of 5, at most
+	}{
+		{MutexLamport, 12},       // 3(n-1)
+		{MutexRicartAgrawala, 8}, // 2(n-1)
+		{MutexSuzukiKasami, 5},   // n-1 requests and the token
+		{MutexMaekawa, 15},       // 5 per other member of a quorum of at most 4
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			sim := NewSimulator(5)
+			sim.SetMutualExclusion(tt.algorithm, 0.2, 20*time.Millisecond)
+			sim.SetNetwork(network.NewModel(network.Link{Latency: network.Exponential(2 * time.Millisecond)}, 3))
+			sim.RunDiscreteEvent(500*time.Millisecond, 0.2, 0.4, 8)
+
+			stats := sim.GetMutexStatistics()
+			if stats["entries"].(int) < 5 {
+				t.Fatalf("Expected several entries, got %v", stats)
+			}
+			if stats["violations"].(int) != 0 || stats["deadlocked"].(int) != 0 {
+				t.Errorf("Critical sections overlap or deadlocked: %v", stats)
+			}
+			if bound := tt.perRequest * stats["requests"].(int); stats["messages"].(int) > bound {
+				t.Errorf("Expected at most %d messages, got %d", bound, stats["messages"])
+			}
+			if stats["avg_sync_delay"].(time.Duration) <= 0 || stats["avg_wait"].(time.Duration) <= 0 {
+				t.Errorf("Expected positive wait and synchronization delay, got %v", stats)
+			}
+			if missed, spurious := sim.CountCausalityViolations(); missed+spurious != 0 {
+				t.Errorf("Protocol messages should keep clocks consistent, got %d missed and %d spurious", missed, spurious)
+			}
+		})
+	}
+}
+
+// verifies the exact cost of Lamport's algorithm: every request is sent to
+// and answered by 4 processes, and every exit released to them.
+func TestMutexLamportMessages(t *testing.T) {
+	sim := NewSimulator(5)
+	sim.SetMutualExclusion(MutexLamport, 0.2, 20*time.Millisecond)
+	sim.RunDiscreteEvent(500*time.Millisecond, 0.2, 0.4, 8)
+
+	exits := 0
+	for _, e := range sim.Events {
+		if e.EventType == "exit" {
+			exits++
+		}
+	}
+	stats := sim.GetMutexStatistics()
+	if expected := 8*stats["requests"].(int) + 4*exits; stats["messages"].(int) != expected {
+		t.Errorf("Expected %d messages for requests, replies and releases, got %d", expected, stats["messages"])
+	}
+}
+
+// verifies quorum locking sends fewer messages per request than
+// Ricart–Agrawala in a group of 16, where a quorum has 7 members.
+func TestMutexMaekawaMessages(t *testing.T) {
+	perRequest := func(algorithm string) float64 {
+		sim := NewSimulator(16)
+		sim.SetMutualExclusion(algorithm, 0.2, 20*time.Millisecond)
+		sim.SetNetwork(network.NewModel(network.Link{Latency: network.Exponential(2 * time.Millisecond)}, 1))
+		sim.RunDiscreteEvent(500*time.Millisecond, 0.2, 0.4, 1)
+
+		stats := sim.GetMutexStatistics()
+		if stats["entries"].(int) == 0 || stats["violations"].(int) != 0 || stats["deadlocked"].(int) != 0 {
+			t.Fatalf("%s: expected entries without overlap or deadlock, got %v", algorithm, stats)
+		}
+		return float64(stats["messages"].(int)) / float64(stats["requests"].(int))
+	}
+
+	if maekawa, ra := perRequest(MutexMaekawa), perRequest(MutexRicartAgrawala); maekawa >= ra {
+		t.Errorf("Expected fewer messages per request than Ricart–Agrawala's %.1f, got %.1f", ra, maekawa)
+	}
+}
+
+// verifies the checker finds the deadlock Maekawa's algorithm runs into
+// without INQUIRE, RELINQUISH and FAILED, and none with them.
+func TestMutexMaekawaDeadlock(t *testing.T) {
+	run := func(algorithm string) *Simulator {
+		sim := NewSimulator(9)
+		sim.SetMutualExclusion(algorithm, 0.2, 20*time.Millisecond)
+		sim.SetNetwork(network.NewModel(network.Link{Latency: network.Exponential(2 * time.Millisecond)}, 2))
+		sim.RunDiscreteEvent(500*time.Millisecond, 0.2, 0.4, 2)
+		return sim
+	}
+
+	sim := run(MutexMaekawaNaive)
+	deadlocks := sim.FindMutexDeadlocks()
+	if len(deadlocks) == 0 {
+		t.Fatal("Expected the naive algorithm to deadlock")
+	}
+	deadlocked := 0
+	for _, cycle := range deadlocks {
+		if len(cycle) < 2 {
+			t.Errorf("A deadlock needs at least 2 processes, got %v", cycle)
+		}
+		for _, id := range cycle {
+			if !sim.Processes[id].locker.Waiting() {
+				t.Errorf("Process %d in deadlock %v is not waiting", id, cycle)
+			}
+		}
+		deadlocked += len(cycle)
+	}
+	if stats := sim.GetMutexStatistics(); stats["deadlocked"].(int) != deadlocked || stats["violations"].(int) != 0 {
+		t.Errorf("Expected %d deadlocked processes and no overlap, got %v", deadlocked, stats)
+	}
+
+	if deadlocks := run(MutexMaekawa).FindMutexDeadlocks(); len(deadlocks) != 0 {
+		t.Errorf("Expected deadlock resolution, got %v", deadlocks)
+	}
+}
+
+// verifies a process with a packet still to be received is not reported
+// as deadlocked, since the packet may end its wait.
+func TestMutexDeadlockInFlight(t *testing.T) {
+	sim := NewSimulator(9)
+	sim.SetMutualExclusion(MutexMaekawaNaive, 0.2, 20*time.Millisecond)
+	sim.SetNetwork(network.NewModel(network.Link{Latency: network.Exponential(2 * time.Millisecond)}, 2))
+	sim.RunDiscreteEvent(500*time.Millisecond, 0.2, 0.4, 2)
+
+	deadlocks := sim.FindMutexDeadlocks()
+	if len(deadlocks) == 0 {
+		t.Fatal("Expected the naive algorithm to deadlock")
+	}
+	if n := len(sim.exclusion.inFlight); n != 0 {
+		t.Fatalf("Expected every packet delivered after a discrete-event run, %d were not", n)
+	}
+
+	// a packet to one member of each cycle, sent but not yet received
+	for _, cycle := range deadlocks {
+		sim.exclusion.inFlight[sim.nextMessageID()] = [2]int{-1, cycle[0]}
+	}
+	if got := sim.FindMutexDeadlocks(); len(got) != 0 {
+		t.Errorf("Expected no deadlock while packets are in transit, got %v", got)
+	}
+}
+
+// verifies strongly connected groups are found in a wait-for graph, and
+// chains leading into them and self-loops are left out.
+func TestWaitCycles(t *testing.T) {
+	edges := map[int][]int{
+		0: {1},
+		1: {2},
+		2: {0},
+		3: {0, 4},
+		4: {5},
+		5: {4},
+		6: {6, 3},
+	}
+	got := fmt.Sprint(waitCycles([]int{6, 5, 4, 3, 2, 1, 0}, edges))
+	if expected := "[[0 1 2] [4 5]]"; got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+// verifies the algorithms that need no FIFO links over links that reorder
+// messages.
+func TestMutexReordering(t *testing.T) {
+	for _, algorithm := range []string{MutexRicartAgrawala, MutexSuzukiKasami} {
+		sim := NewSimulator(4)
+		sim.SetMutualExclusion(algorithm, 0.3, 10*time.Millisecond)
+		sim.SetNetwork(network.NewModel(network.Link{
+			Latency:      network.Constant(time.Millisecond),
+			Reorder:      0.3,
+			ReorderDelay: 5 * time.Millisecond,
+		}, 2))
+		sim.RunDiscreteEvent(400*time.Millisecond, 0.2, 0.4, 5)
+
+		stats := sim.GetMutexStatistics()
+		if stats["entries"].(int) == 0 || stats["violations"].(int) != 0 {
+			t.Errorf("%s: expected entries without overlap, got %v", algorithm, stats)
+		}
+	}
+}
+
+// verifies the checker flags critical sections not ordered by messages.
+func TestMutexViolationDetected(t *testing.T) {
+	sim := NewSimulator(2)
+	for _, p := range sim.Processes {
+		p.mu.Lock()
+		sim.recordLocal(p, "enter")
+		sim.recordLocal(p, "exit")
+		p.mu.Unlock()
+	}
+	if got := sim.CountMutexViolations(); got != 1 {
+		t.Errorf("Expected 1 overlapping pair, got %d", got)
+	}
+
+	// a message from the first exit to the second process orders them
+	sim = NewSimulator(2)
+	p0, p1 := sim.Processes[0], sim.Processes[1]
+	p0.mu.Lock()
+	sim.recordLocal(p0, "enter")
+	sim.recordLocal(p0, "exit")
+	p0.mu.Unlock()
+	sim.sendMessage(0, 1)
+	sim.receiveMessage(1, <-p1.inbox)
+	p1.mu.Lock()
+	sim.recordLocal(p1, "enter")
+	p1.mu.Unlock()
+	if got := sim.CountMutexViolations(); got != 0 {
+		t.Errorf("Expected ordered critical sections, got %d overlapping pairs", got)
+	}
+}
+
+// verifies mutual exclusion holds in a real-time run. how far the run gets
+// depends on the scheduler, so progress and deadlocks are checked by
+// TestMutexDiscrete.
+func TestMutexRealTime(t *testing.T) {
+	for _, algorithm := range []string{MutexLamport, MutexRicartAgrawala, MutexSuzukiKasami, MutexMaekawa} {
+		sim := NewSimulator(4)
+		sim.SetMutualExclusion(algorithm, 0.3, 10*time.Millisecond)
+		sim.RunSimulation(200*time.Millisecond, 0.2, 0.4)
+
+		if stats := sim.GetMutexStatistics(); stats["violations"].(int) != 0 {
+			t.Errorf("%s: expected critical sections without overlap, got %v", algorithm, stats)
+		}
+	}
+}
+
+// verifies invalid settings and churn with mutual exclusion panic.
+func TestMutexPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"unknown algorithm", func() { NewSimulator(2).SetMutualExclusion("bakery", 0.1, 0) }},
+		{"invalid probability", func() { NewSimulator(2).SetMutualExclusion(MutexLamport, 1.5, 0) }},
+		{"negative hold", func() { NewSimulator(2).SetMutualExclusion(MutexLamport, 0.1, -1) }},
+		{"spawn", func() {
+			sim := NewSimulatorWithCapacity(2, 4)
+			sim.SetMutualExclusion(MutexLamport, 0.1, 0)
+			sim.SpawnProcess(0)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic")
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package simulator
+
+import (
+	"time"
+
+	network "github.com/simonnyman/DISY_Projects/Synchronization/network"
+)
+
+// routes all messages, including clock synchronization messages, through
+// the given network model. nil restores the default: immediate delivery in
+// real-time runs and DiscreteLatency in discrete-event runs, always once
+// and in order. set it before running the simulation.
+func (s *Simulator) SetNetwork(n network.Network) {
+	s.network = n
+}
+
+// hands a message to the network and schedules each copy that arrives.
+// messages across a partition are dropped or held instead.
+// in real-time runs a copy due now is pushed to the inbox directly,
+// blocking if wait is set and dropped if the inbox is full otherwise;
+// later copies are pushed by a timer and dropped if the run stops first.
+// returns false if a copy was dropped at a full inbox.
+func (s *Simulator) deliver(receiver *Process, msg *Message, wait bool) bool {
+	if s.blockAtPartition(receiver, msg, wait) {
+		return true
+	}
+	now := time.Duration(s.trueTime())
+
+	var arrivals []time.Duration
+	switch {
+	case s.network != nil:
+		arrivals = s.network.Transmit(msg.From, msg.To, now)
+	case s.sched != nil:
+		arrivals = []time.Duration{now + DiscreteLatency}
+	default:
+		arrivals = []time.Duration{now}
+	}
+
+	delivered := true
+	for _, at := range arrivals {
+		if s.sched != nil {
+			s.sched.schedule(at, stepDeliver, receiver.ID, msg)
+			continue
+		}
+		if at <= now {
+			delivered = push(receiver, msg, wait, s.halt) && delivered
+			continue
+		}
+		halt := s.halt
+		time.AfterFunc(at-now, func() { push(receiver, msg, wait, halt) })
+	}
+	return delivered
+}
+
+// pushes a message to a process's inbox, waiting until there is room or
+// halt is closed if wait is set. returns false if the message was dropped.
+func push(receiver *Process, msg *Message, wait bool, halt <-chan bool) bool {
+	if !wait {
+		select {
+		case receiver.inbox <- msg:
+			return true
+		default:
+			return false
+		}
+	}
+	select {
+	case receiver.inbox <- msg:
+		return true
+	case <-halt:
+		return false
+	}
+}
+
+// returns delivery counts reconstructed from the recorded events: messages
+// sent, receive events, messages never received, extra receives of the
+// same message, and receives that overtook a message sent earlier on the
+// same link. messages still in transit when a real-time run stopped count
+// as lost.
+func (s *Simulator) GetNetworkStatistics() map[string]interface{} {
+	type link struct{ from, to int }
+
+	sent := 0
+	sendIndex := make(map[int]int) // message ID to its position on its link
+	linkSends := make(map[link]int)
+	receives := make(map[int]int)
+	for _, e := range s.Events {
+		if e.EventType != "send" {
+			continue
+		}
+		sent++
+		l := link{e.ProcessID, e.TargetID}
+		sendIndex[e.MessageID] = linkSends[l]
+		linkSends[l]++
+	}
+
+	delivered, duplicates, outOfOrder := 0, 0, 0
+	latest := make(map[link]int) // highest send position received per link
+	for _, e := range s.Events {
+		if e.EventType != "receive" {
+			continue
+		}
+		delivered++
+		receives[e.MessageID]++
+		if receives[e.MessageID] > 1 {
+			duplicates++
+			continue
+		}
+
+		l := link{e.TargetID, e.ProcessID}
+		index := sendIndex[e.MessageID]
+		if last, ok := latest[l]; ok && index < last {
+			outOfOrder++
+		} else {
+			latest[l] = index
+		}
+	}
+
+	lost := 0
+	for id := range sendIndex {
+		if receives[id] == 0 {
+			lost++
+		}
+	}
+
+	lossRate := 0.0
+	if sent > 0 {
+		lossRate = float64(lost) / float64(sent)
+	}
+
+	return map[string]interface{}{
+		"sent":         sent,
+		"delivered":    delivered,
+		"lost":         lost,
+		"loss_rate":    lossRate,
+		"duplicates":   duplicates,
+		"out_of_order": outOfOrder,
+	}
+}
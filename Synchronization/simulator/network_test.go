@@ -0,0 +1,113 @@
+package simulator
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	network "github.com/simonnyman/DISY_Projects/Synchronization/network"
+)
+
+// verifies messages dropped by the network show up as lost.
+func TestNetworkDrop(t *testing.T) {
+	sim := NewSimulator(2)
+	sim.SetNetwork(network.NewModel(network.Link{Latency: network.Constant(0), Drop: 1}, 1))
+
+	sim.sendMessage(0, 1)
+	sim.sendMessage(1, 0)
+
+	stats := sim.GetNetworkStatistics()
+	if stats["lost"].(int) != 2 || stats["delivered"].(int) != 0 {
+		t.Errorf("Expected 2 lost and none delivered, got %v", stats)
+	}
+	if len(sim.Processes[0].inbox)+len(sim.Processes[1].inbox) != 0 {
+		t.Error("Dropped messages should not reach an inbox")
+	}
+}
+
+// verifies a duplicated message is received twice under the same ID.
+func TestNetworkDuplicate(t *testing.T) {
+	sim := NewSimulator(2)
+	sim.SetNetwork(network.NewModel(network.Link{Latency: network.Constant(0), Duplicate: 1}, 1))
+
+	sim.sendMessage(0, 1)
+	sim.receiveMessage(1, <-sim.Processes[1].inbox)
+	sim.receiveMessage(1, <-sim.Processes[1].inbox)
+
+	stats := sim.GetNetworkStatistics()
+	if stats["delivered"].(int) != 2 || stats["duplicates"].(int) != 1 || stats["lost"].(int) != 0 {
+		t.Errorf("Expected 2 deliveries with 1 duplicate, got %v", stats)
+	}
+	if sim.Events[1].MessageID != sim.Events[2].MessageID {
+		t.Error("Both receives should carry the sent message's ID")
+	}
+}
+
+// verifies out-of-order receives on a link are counted.
+func TestNetworkOutOfOrder(t *testing.T) {
+	sim := NewSimulator(2)
+
+	sim.sendMessage(0, 1)
+	sim.sendMessage(0, 1)
+	first := <-sim.Processes[1].inbox
+	second := <-sim.Processes[1].inbox
+	sim.receiveMessage(1, second)
+	sim.receiveMessage(1, first)
+
+	if got := sim.GetNetworkStatistics()["out_of_order"].(int); got != 1 {
+		t.Errorf("Expected 1 out-of-order receive, got %d", got)
+	}
+}
+
+// verifies delayed messages reach their receivers in a real-time run.
+func TestNetworkRealTimeLatency(t *testing.T) {
+	sim := NewSimulator(3)
+	sim.SetNetwork(network.NewModel(network.Link{Latency: network.Uniform(time.Millisecond, 3*time.Millisecond)}, 1))
+	sim.RunSimulation(100*time.Millisecond, 0.2, 0.6)
+
+	stats := sim.GetNetworkStatistics()
+	if stats["delivered"].(int) == 0 {
+		t.Fatal("Expected delayed messages to be delivered")
+	}
+	if stats["duplicates"].(int) != 0 {
+		t.Errorf("Expected no duplicates, got %d", stats["duplicates"])
+	}
+}
+
+// verifies a lossy, reordering network in a discrete-event run is still
+// reproducible and every message is either received or lost.
+func TestNetworkDiscreteEvent(t *testing.T) {
+	run := func() (*Simulator, []byte) {
+		sim := NewSimulator(4)
+		sim.SetNetwork(network.NewModel(network.Link{
+			Latency:      network.LogNormal(2*time.Millisecond, 0.8),
+			Drop:         0.1,
+			Duplicate:    0.1,
+			Reorder:      0.2,
+			ReorderDelay: 15 * time.Millisecond,
+		}, 11))
+		sim.RunDiscreteEvent(500*time.Millisecond, 0.2, 0.8, 5)
+
+		var buf bytes.Buffer
+		sim.WriteTrace(&buf)
+		return sim, buf.Bytes()
+	}
+
+	sim, trace := run()
+	if _, again := run(); !bytes.Equal(trace, again) {
+		t.Fatal("Same seeds should give the same trace")
+	}
+
+	stats := sim.GetNetworkStatistics()
+	for _, key := range []string{"lost", "duplicates", "out_of_order"} {
+		if stats[key].(int) == 0 {
+			t.Errorf("Expected some %s messages, got %v", key, stats)
+		}
+	}
+	if stats["sent"].(int)-stats["lost"].(int) != stats["delivered"].(int)-stats["duplicates"].(int) {
+		t.Errorf("Every message should be received once or lost, got %v", stats)
+	}
+	if v := sim.CountHLCViolations(); v != 0 {
+		t.Errorf("HLC should respect happened-before under reordering, got %d violations", v)
+	}
+}
@@ -0,0 +1,44 @@
+package simulator
+
+import (
+	"sort"
+
+	lamport "github.com/simonnyman/DISY_Projects/Synchronization/lamport"
+)
+
+// returns the event's Lamport timestamp with process-ID tie-breaking.
+func (e Event) LamportTimestamp() lamport.Timestamp {
+	return lamport.Timestamp{Time: e.Timestamp, ProcessID: e.ProcessID}
+}
+
+// returns all events in the total order of their (time, processID)
+// Lamport timestamps, the order a replicated state machine applies them in.
+func (s *Simulator) TotalOrder() []Event {
+	s.eventsMu.Lock()
+	order := make([]Event, len(s.Events))
+	copy(order, s.Events)
+	s.eventsMu.Unlock()
+
+	sort.Slice(order, func(i, j int) bool {
+		return order[i].LamportTimestamp().Less(order[j].LamportTimestamp())
+	})
+	return order
+}
+
+// counts event pairs that the total order places against happened-before,
+// i.e. the number of ways it fails to be a linear extension of the vector
+// clock ordering. should always be zero.
+func (s *Simulator) CountTotalOrderViolations() int {
+	order := s.TotalOrder()
+	violations := 0
+
+	for i := 0; i < len(order); i++ {
+		for j := i + 1; j < len(order); j++ {
+			if HappenedBefore(order[j].VectorTime, order[i].VectorTime) {
+				violations++
+			}
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,316 @@
+package simulator
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// what happens to messages sent across a partition
+const (
+	PartitionDrop = "drop" // messages across the cut are lost
+	PartitionHold = "hold" // messages across the cut are delivered once it heals
+)
+
+// a scheduled change of the network's connectivity
+type partitionChange struct {
+	at     time.Duration
+	groups map[int]int // group of each listed process, nil to heal
+	policy string
+}
+
+// a stretch of the run with constant connectivity
+type partitionPhase struct {
+	start      time.Duration
+	split      bool
+	firstEvent int // index in Events of the first event recorded in the phase
+	blocked    int // application messages sent across the cut
+	dropped    int
+	held       int
+	released   int            // held messages delivered when the phase began
+	links      map[[2]int]int // application messages blocked per link
+}
+
+// state of the partition schedule
+type partitionState struct {
+	schedule []partitionChange
+	current  partitionChange // in force now; groups is nil when connected
+	phases   []partitionPhase
+	held     []heldMessage
+}
+
+// a message waiting for a partition to heal
+type heldMessage struct {
+	receiver *Process
+	msg      *Message
+	wait     bool
+}
+
+// schedules a partition that starts at time at after the run begins.
+// each group lists processes that can still reach each other; processes
+// not listed in any group, including ones spawned later, form one more
+// group together. policy decides whether messages across the cut are
+// dropped (PartitionDrop) or held until the partition heals (PartitionHold).
+// a later partition replaces an earlier one without healing first.
+// panics if at is negative, the policy is unknown, no group is given, or a
+// process ID is out of bounds or listed twice.
+func (s *Simulator) SchedulePartition(at time.Duration, policy string, groups ...[]int) {
+	if at < 0 {
+		panic("simulator: partition time must not be negative")
+	}
+	if policy != PartitionDrop && policy != PartitionHold {
+		panic("simulator: unknown partition policy " + policy)
+	}
+	if len(groups) == 0 {
+		panic("simulator: partition needs at least one group")
+	}
+
+	assignment := make(map[int]int)
+	for g, group := range groups {
+		for _, id := range group {
+			if id < 0 || id >= s.MaxProcesses {
+				panic("simulator: partition process ID out of bounds")
+			}
+			if _, ok := assignment[id]; ok {
+				panic("simulator: process listed in two partition groups")
+			}
+			assignment[id] = g
+		}
+	}
+
+	s.addPartitionChange(partitionChange{at: at, groups: assignment, policy: policy})
+}
+
+// schedules the partition in force at time at to heal.
+// panics if at is negative.
+func (s *Simulator) ScheduleHeal(at time.Duration) {
+	if at < 0 {
+		panic("simulator: heal time must not be negative")
+	}
+	s.addPartitionChange(partitionChange{at: at})
+}
+
+// inserts a change into the schedule, keeping it sorted by time.
+func (s *Simulator) addPartitionChange(change partitionChange) {
+	s.partitionMu.Lock()
+	defer s.partitionMu.Unlock()
+	s.partition.schedule = append(s.partition.schedule, change)
+	sort.SliceStable(s.partition.schedule, func(i, j int) bool {
+		return s.partition.schedule[i].at < s.partition.schedule[j].at
+	})
+}
+
+// reports whether a partition separates two processes.
+// must be called with partitionMu held.
+func (ps *partitionState) cut(from, to int) bool {
+	if ps.current.groups == nil {
+		return false
+	}
+	group := func(id int) int {
+		if g, ok := ps.current.groups[id]; ok {
+			return g
+		}
+		return -1
+	}
+	return group(from) != group(to)
+}
+
+// drops or holds a message that would cross the current partition.
+// returns true if the message was blocked.
+func (s *Simulator) blockAtPartition(receiver *Process, msg *Message, wait bool) bool {
+	s.partitionMu.Lock()
+	defer s.partitionMu.Unlock()
+
+	ps := &s.partition
+	if !ps.cut(msg.From, msg.To) {
+		return false
+	}
+
+	if ps.current.policy == PartitionHold {
+		ps.held = append(ps.held, heldMessage{receiver, msg, wait})
+	}
+	// only application messages count; sync messages are blocked silently
+	if msg.MessageID < 0 {
+		return true
+	}
+	if len(ps.phases) > 0 {
+		phase := &ps.phases[len(ps.phases)-1]
+		if phase.links == nil {
+			phase.links = make(map[[2]int]int)
+		}
+		phase.links[[2]int{msg.From, msg.To}]++
+		phase.blocked++
+		if ps.current.policy == PartitionHold {
+			phase.held++
+		} else {
+			phase.dropped++
+		}
+	}
+	return true
+}
+
+// opens the first phase of a run: connected, starting at the current event.
+func (s *Simulator) startPartitions() {
+	s.eventsMu.Lock()
+	first := len(s.Events)
+	s.eventsMu.Unlock()
+
+	s.partitionMu.Lock()
+	defer s.partitionMu.Unlock()
+	s.partition.phases = append(s.partition.phases, partitionPhase{firstEvent: first})
+}
+
+// puts a scheduled change into force and delivers held messages that no
+// longer cross a cut.
+func (s *Simulator) applyPartitionChange(change partitionChange) {
+	s.eventsMu.Lock()
+	first := len(s.Events)
+	s.eventsMu.Unlock()
+
+	s.partitionMu.Lock()
+	ps := &s.partition
+	ps.current = change
+	var release, keep []heldMessage
+	for _, h := range ps.held {
+		if ps.cut(h.msg.From, h.msg.To) {
+			keep = append(keep, h)
+		} else {
+			release = append(release, h)
+		}
+	}
+	ps.held = keep
+
+	released := 0
+	for _, h := range release {
+		if h.msg.MessageID >= 0 {
+			released++
+		}
+	}
+	ps.phases = append(ps.phases, partitionPhase{
+		start:      change.at,
+		split:      change.groups != nil,
+		firstEvent: first,
+		released:   released,
+	})
+	s.partitionMu.Unlock()
+
+	for _, h := range release {
+		s.deliver(h.receiver, h.msg, h.wait)
+	}
+}
+
+// returns the scheduled changes that fall within duration.
+func (s *Simulator) partitionSchedule(duration time.Duration) []partitionChange {
+	s.partitionMu.Lock()
+	defer s.partitionMu.Unlock()
+
+	var changes []partitionChange
+	for _, change := range s.partition.schedule {
+		if change.at <= duration {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// starts the goroutine that applies the partition schedule in real time
+// until stop is closed.
+func (s *Simulator) runPartitions(wg *sync.WaitGroup, stop <-chan bool, duration time.Duration) {
+	s.startPartitions()
+	changes := s.partitionSchedule(duration)
+	if len(changes) == 0 {
+		return
+	}
+
+	start := time.Now()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for _, change := range changes {
+			timer := time.NewTimer(change.at - time.Since(start))
+			select {
+			case <-stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+				s.applyPartitionChange(change)
+			}
+		}
+	}()
+}
+
+// returns the messages blocked by all partitions of the run, indexed like
+// GetCommunicationMatrix. GetPartitionStatistics breaks them down by
+// phase.
+func (s *Simulator) GetBlockedMatrix() [][]int {
+	s.partitionMu.Lock()
+	defer s.partitionMu.Unlock()
+
+	matrix := newMatrix(s.NumProcesses)
+	for _, phase := range s.partition.phases {
+		for link, n := range phase.links {
+			matrix[link[0]][link[1]] += n
+		}
+	}
+	return matrix
+}
+
+// returns an n×n matrix of zeros.
+func newMatrix(n int) [][]int {
+	matrix := make([][]int, n)
+	for i := range matrix {
+		matrix[i] = make([]int, n)
+	}
+	return matrix
+}
+
+// returns one entry per phase of the partition schedule: when it started,
+// whether the network was split, the events recorded, the share of their
+// pairs that are concurrent, and the application messages blocked,
+// dropped, held and released at its start. blocked_matrix holds the
+// messages blocked in the phase, indexed like GetCommunicationMatrix.
+func (s *Simulator) GetPartitionStatistics() []map[string]interface{} {
+	s.partitionMu.Lock()
+	phases := append([]partitionPhase(nil), s.partition.phases...)
+	s.partitionMu.Unlock()
+
+	stats := make([]map[string]interface{}, 0, len(phases))
+	for i, phase := range phases {
+		blocked := newMatrix(s.NumProcesses)
+		for link, n := range phase.links {
+			blocked[link[0]][link[1]] = n
+		}
+
+		last := len(s.Events)
+		if i+1 < len(phases) {
+			last = phases[i+1].firstEvent
+		}
+		events := s.Events[phase.firstEvent:last]
+
+		concurrent := 0
+		for a := 0; a < len(events); a++ {
+			for b := a + 1; b < len(events); b++ {
+				if areConcurrent(events[a].VectorTime, events[b].VectorTime) {
+					concurrent++
+				}
+			}
+		}
+		rate := 0.0
+		if pairs := len(events) * (len(events) - 1) / 2; pairs > 0 {
+			rate = float64(concurrent) / float64(pairs)
+		}
+
+		stats = append(stats, map[string]interface{}{
+			"start":            phase.start,
+			"split":            phase.split,
+			"events":           len(events),
+			"concurrency_rate": rate,
+			"blocked":          phase.blocked,
+			"blocked_matrix":   blocked,
+			"dropped":          phase.dropped,
+			"held":             phase.held,
+			"released":         phase.released,
+		})
+	}
+	return stats
+}
@@ -0,0 +1,176 @@
+package simulator
+
+import (
+	"testing"
+	"time"
+)
+
+// verifies messages across a dropping partition are lost and counted per link.
+func TestPartitionDrop(t *testing.T) {
+	sim := NewSimulator(4)
+	sim.SchedulePartition(0, PartitionDrop, []int{0, 1})
+	sim.startPartitions()
+	sim.applyPartitionChange(sim.partitionSchedule(0)[0])
+
+	sim.sendMessage(0, 1) // same side
+	sim.sendMessage(0, 2) // across the cut
+	sim.sendMessage(3, 1) // across the cut
+	sim.sendMessage(2, 3) // unlisted processes form one group
+
+	if len(sim.Processes[1].inbox) != 1 || len(sim.Processes[3].inbox) != 1 || len(sim.Processes[2].inbox) != 0 {
+		t.Error("Only messages within a side should be delivered")
+	}
+	blocked := sim.GetBlockedMatrix()
+	if blocked[0][2] != 1 || blocked[3][1] != 1 || blocked[0][1] != 0 {
+		t.Errorf("Unexpected blocked matrix %v", blocked)
+	}
+	if sim.GetCommunicationMatrix()[0][2] != 1 {
+		t.Error("Communication matrix should still count the blocked send")
+	}
+
+	stats := sim.GetPartitionStatistics()
+	if len(stats) != 2 || !stats[1]["split"].(bool) || stats[1]["dropped"].(int) != 2 {
+		t.Errorf("Expected the split phase to drop 2 messages, got %v", stats)
+	}
+}
+
+// verifies blocked messages are reported per partition, and in total by
+// GetBlockedMatrix.
+func TestPartitionBlockedPerPhase(t *testing.T) {
+	sim := NewSimulator(3)
+	sim.SchedulePartition(0, PartitionDrop, []int{0}, []int{1, 2})
+	sim.SchedulePartition(time.Second, PartitionDrop, []int{0, 1}, []int{2})
+	changes := sim.partitionSchedule(time.Second)
+	sim.startPartitions()
+
+	sim.applyPartitionChange(changes[0])
+	sim.sendMessage(0, 1)
+	sim.sendMessage(0, 2)
+	sim.applyPartitionChange(changes[1])
+	sim.sendMessage(0, 1) // same side now
+	sim.sendMessage(0, 2)
+	sim.sendMessage(1, 2)
+
+	stats := sim.GetPartitionStatistics()
+	first, second := stats[1]["blocked_matrix"].([][]int), stats[2]["blocked_matrix"].([][]int)
+	if first[0][1] != 1 || first[0][2] != 1 || first[1][2] != 0 {
+		t.Errorf("Unexpected blocked matrix of the first partition %v", first)
+	}
+	if second[0][1] != 0 || second[0][2] != 1 || second[1][2] != 1 {
+		t.Errorf("Unexpected blocked matrix of the second partition %v", second)
+	}
+	if total := sim.GetBlockedMatrix(); total[0][1] != 1 || total[0][2] != 2 || total[1][2] != 1 {
+		t.Errorf("Expected the blocked matrices to add up, got %v", total)
+	}
+}
+
+// verifies held messages are delivered when the partition heals.
+func TestPartitionHold(t *testing.T) {
+	sim := NewSimulator(2)
+	sim.SchedulePartition(0, PartitionHold, []int{0}, []int{1})
+	sim.ScheduleHeal(time.Second)
+	changes := sim.partitionSchedule(time.Second)
+	sim.startPartitions()
+	sim.applyPartitionChange(changes[0])
+
+	sim.sendMessage(0, 1)
+	if len(sim.Processes[1].inbox) != 0 {
+		t.Fatal("Held message should not be delivered during the split")
+	}
+
+	sim.applyPartitionChange(changes[1])
+	if len(sim.Processes[1].inbox) != 1 {
+		t.Fatal("Held message should be delivered after healing")
+	}
+
+	stats := sim.GetPartitionStatistics()
+	if stats[1]["held"].(int) != 1 || stats[2]["released"].(int) != 1 || stats[2]["split"].(bool) {
+		t.Errorf("Expected 1 message held then released, got %v", stats)
+	}
+}
+
+// verifies invalid partition schedules panic.
+func TestPartitionPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"negative time", func() { NewSimulator(2).SchedulePartition(-1, PartitionDrop, []int{0}) }},
+		{"unknown policy", func() { NewSimulator(2).SchedulePartition(0, "queue", []int{0}) }},
+		{"no groups", func() { NewSimulator(2).SchedulePartition(0, PartitionDrop) }},
+		{"out of bounds", func() { NewSimulator(2).SchedulePartition(0, PartitionDrop, []int{2}) }},
+		{"listed twice", func() { NewSimulator(2).SchedulePartition(0, PartitionDrop, []int{0}, []int{0, 1}) }},
+		{"negative heal", func() { NewSimulator(2).ScheduleHeal(-1) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic")
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
+
+// verifies a scheduled split and heal in a discrete-event run: no message
+// crosses the cut while it lasts, and concurrency rises during the split.
+func TestPartitionScheduleDiscrete(t *testing.T) {
+	sim := NewSimulator(6)
+	sim.SchedulePartition(300*time.Millisecond, PartitionDrop, []int{0, 1, 2})
+	sim.ScheduleHeal(800 * time.Millisecond)
+	sim.RunDiscreteEvent(1200*time.Millisecond, 0.2, 0.6, 3)
+
+	stats := sim.GetPartitionStatistics()
+	if len(stats) != 3 {
+		t.Fatalf("Expected 3 phases, got %d", len(stats))
+	}
+	if stats[0]["split"].(bool) || !stats[1]["split"].(bool) || stats[2]["split"].(bool) {
+		t.Errorf("Expected connected, split, connected phases, got %v", stats)
+	}
+	if stats[1]["start"].(time.Duration) != 300*time.Millisecond || stats[2]["start"].(time.Duration) != 800*time.Millisecond {
+		t.Errorf("Phases should start at the scheduled times, got %v and %v", stats[1]["start"], stats[2]["start"])
+	}
+	if stats[1]["blocked"].(int) == 0 || stats[0]["blocked"].(int) != 0 || stats[2]["blocked"].(int) != 0 {
+		t.Errorf("Only the split should block messages, got %v", stats)
+	}
+	if stats[1]["concurrency_rate"].(float64) <= stats[2]["concurrency_rate"].(float64) {
+		t.Errorf("Concurrency during the split (%.3f) should exceed after healing (%.3f)",
+			stats[1]["concurrency_rate"], stats[2]["concurrency_rate"])
+	}
+
+	// no message sent during the split is received across the cut
+	side := map[int]bool{0: true, 1: true, 2: true}
+	first := stats[0]["events"].(int)
+	split := sim.Events[first : first+stats[1]["events"].(int)]
+	sentDuringSplit := make(map[int]bool)
+	for _, e := range split {
+		if e.EventType == "send" {
+			sentDuringSplit[e.MessageID] = true
+		}
+	}
+	for _, e := range sim.Events {
+		if e.EventType == "receive" && sentDuringSplit[e.MessageID] && side[e.ProcessID] != side[e.TargetID] {
+			t.Errorf("Message %d crossed the cut during the split", e.MessageID)
+		}
+	}
+}
+
+// verifies the schedule is applied in a real-time run.
+func TestPartitionScheduleRealTime(t *testing.T) {
+	sim := NewSimulator(4)
+	sim.SchedulePartition(30*time.Millisecond, PartitionHold, []int{0, 1})
+	sim.ScheduleHeal(80 * time.Millisecond)
+	sim.RunSimulation(120*time.Millisecond, 0.2, 0.6)
+
+	stats := sim.GetPartitionStatistics()
+	if len(stats) != 3 {
+		t.Fatalf("Expected 3 phases, got %d", len(stats))
+	}
+	if stats[1]["held"] != stats[2]["released"] {
+		t.Errorf("Every held message should be released on healing, got %v held and %v released",
+			stats[1]["held"], stats[2]["released"])
+	}
+}
@@ -0,0 +1,400 @@
+package simulator
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	physical "github.com/simonnyman/DISY_Projects/Synchronization/physical"
+)
+
+// names of the clock synchronization algorithms
+const (
+	SyncNone     = "none"
+	SyncCristian = "cristian" // every process asks a time server
+	SyncBerkeley = "berkeley" // a master polls every process and sends corrections
+)
+
+// PhysicalConfig describes how far the simulated physical clocks stray
+// from true time. the zero value gives perfect clocks.
+type PhysicalConfig struct {
+	MaxDrift  float64       // drift rates are drawn uniformly from [-MaxDrift, MaxDrift]
+	MaxOffset time.Duration // initial offsets are drawn uniformly from [-MaxOffset, MaxOffset]
+	Jitter    time.Duration // largest error of a single clock reading
+	Seed      int64         // seeds the draws, so the same seed gives the same clocks
+}
+
+// SyncMessage is the payload of a clock synchronization message.
+type SyncMessage struct {
+	Kind        string // "request", "reply", "poll", "report" or "adjust"
+	Round       int    // synchronization round the message belongs to
+	RequestTime int64  // requester's clock reading when the exchange started
+	Time        int64  // responder's clock reading
+	Adjustment  int64  // correction the Berkeley master sends to a process
+}
+
+// SkewSample is the state of the physical clocks at one instant.
+type SkewSample struct {
+	Elapsed  time.Duration // true time since the simulation started
+	Skew     time.Duration // largest difference between two live clocks
+	MaxError time.Duration // largest distance of a live clock from true time
+}
+
+// state and counters of clock synchronization
+type syncState struct {
+	algorithm       string
+	interval        time.Duration
+	rounds          int
+	messages        int
+	dropped         int   // messages lost to full inboxes
+	adjustments     int   // corrections applied to clocks
+	totalAdjustment int64 // sum of absolute corrections in nanoseconds
+	samples         []SkewSample
+
+	// Berkeley round in progress at the master, 0 when none is
+	round   int
+	master  int
+	pending int // reports still expected
+	offsets map[int]int64
+}
+
+// replaces every process's physical clock with one drawn from cfg.
+// clocks that read physical time, such as the HLC, are recreated to read
+// the new clock. must be called before the simulation records any events.
+// panics if MaxDrift is not in [0, 1), if MaxOffset or Jitter is negative,
+// or if events were recorded.
+func (s *Simulator) SetPhysicalClocks(cfg PhysicalConfig) {
+	if cfg.MaxDrift < 0 || cfg.MaxDrift >= 1 {
+		panic("simulator: MaxDrift must be in [0, 1)")
+	}
+	if cfg.MaxOffset < 0 || cfg.Jitter < 0 {
+		panic("simulator: MaxOffset and Jitter must not be negative")
+	}
+
+	s.eventsMu.Lock()
+	recorded := len(s.Events)
+	s.eventsMu.Unlock()
+	if recorded > 0 {
+		panic("simulator: cannot change physical clocks after events were recorded")
+	}
+
+	s.procMu.Lock()
+	defer s.procMu.Unlock()
+	s.physicalConfig = cfg
+	s.resetClocks()
+}
+
+// recreates every process's physical clock and the clocks reading it.
+// must be called with procMu held and before any events are recorded.
+func (s *Simulator) resetClocks() {
+	for _, p := range s.Processes {
+		p.PhysicalClock = s.newPhysicalClock(p.ID)
+		for name, factory := range s.factories {
+			p.Clocks[name] = factory(s.clockConfig(p.ID, p.PhysicalClock))
+		}
+	}
+}
+
+// selects the clock synchronization algorithm the simulation runs every
+// interval: SyncNone, SyncCristian or SyncBerkeley. the lowest live process
+// acts as time server or master. synchronization messages travel through
+// the inboxes like application messages but record no events.
+// panics if the algorithm is unknown or interval is not positive.
+func (s *Simulator) SetClockSync(algorithm string, interval time.Duration) {
+	switch algorithm {
+	case SyncNone, SyncCristian, SyncBerkeley:
+	default:
+		panic("simulator: unknown clock synchronization algorithm " + algorithm)
+	}
+	if interval <= 0 {
+		panic("simulator: sync interval must be positive")
+	}
+
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+	s.clockSync.algorithm = algorithm
+	s.clockSync.interval = interval
+}
+
+// creates the physical clock of a process from the simulator's config.
+// each process draws from its own seed, so spawning does not change the
+// clocks of existing processes.
+func (s *Simulator) newPhysicalClock(id int) *physical.Clock {
+	cfg := s.physicalConfig
+	rng := rand.New(rand.NewSource(cfg.Seed + int64(id)))
+	drift := (2*rng.Float64() - 1) * cfg.MaxDrift
+	offset := time.Duration((2*rng.Float64() - 1) * float64(cfg.MaxOffset))
+	return physical.NewClock(s.trueTime, drift, offset, cfg.Jitter, rng.Int63())
+}
+
+// returns true time in nanoseconds: virtual time in discrete-event runs,
+// the host wall clock otherwise.
+func (s *Simulator) trueTime() int64 {
+	if s.sched != nil {
+		return int64(s.sched.now)
+	}
+	return physical.HostTime()
+}
+
+// starts the goroutine that samples clock skew every 10ms and runs a
+// synchronization round every sync interval until stop is closed.
+func (s *Simulator) runClockSync(wg *sync.WaitGroup, stop <-chan bool) {
+	s.syncMu.Lock()
+	algorithm, interval := s.clockSync.algorithm, s.clockSync.interval
+	s.syncMu.Unlock()
+
+	start := time.Now()
+	s.sampleSkew(0)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(tickInterval)
+		defer ticker.Stop()
+
+		var syncTick <-chan time.Time
+		if algorithm != SyncNone {
+			syncTicker := time.NewTicker(interval)
+			defer syncTicker.Stop()
+			syncTick = syncTicker.C
+		}
+
+		for {
+			select {
+			case <-stop:
+				s.sampleSkew(time.Since(start))
+				return
+			case <-ticker.C:
+				s.sampleSkew(time.Since(start))
+			case <-syncTick:
+				s.startSyncRound(algorithm)
+			}
+		}
+	}()
+}
+
+// records the skew between the live physical clocks.
+func (s *Simulator) sampleSkew(elapsed time.Duration) {
+	var errors []int64
+	var maxError int64
+	for _, id := range s.LiveProcesses() {
+		p, _ := s.lookup(id)
+		err := p.PhysicalClock.Error()
+		errors = append(errors, err)
+		maxError = max(maxError, err, -err)
+	}
+
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+	s.clockSync.samples = append(s.clockSync.samples, SkewSample{
+		Elapsed:  elapsed,
+		Skew:     time.Duration(physical.Spread(errors)),
+		MaxError: time.Duration(maxError),
+	})
+}
+
+// starts one synchronization round between the lowest live process and
+// every other live process.
+func (s *Simulator) startSyncRound(algorithm string) {
+	live := s.LiveProcesses()
+	if len(live) < 2 {
+		return
+	}
+	server, _ := s.lookup(live[0])
+
+	s.syncMu.Lock()
+	s.clockSync.rounds++
+	round := s.clockSync.rounds
+	if algorithm == SyncBerkeley {
+		s.clockSync.round = round
+		s.clockSync.master = server.ID
+		s.clockSync.pending = len(live) - 1
+		s.clockSync.offsets = make(map[int]int64)
+	}
+	s.syncMu.Unlock()
+
+	for _, id := range live[1:] {
+		switch algorithm {
+		case SyncCristian:
+			client, _ := s.lookup(id)
+			s.sendSync(id, server.ID, SyncMessage{Kind: "request", Round: round, RequestTime: client.PhysicalClock.Now()})
+		case SyncBerkeley:
+			s.sendSync(server.ID, id, SyncMessage{Kind: "poll", Round: round, RequestTime: server.PhysicalClock.Now()})
+		}
+	}
+}
+
+// sends a synchronization message without blocking. messages that find a
+// full inbox are dropped, so receivers answering requests can never
+// deadlock each other.
+func (s *Simulator) sendSync(fromID, toID int, sm SyncMessage) {
+	receiver, ok := s.lookup(toID)
+	if !ok {
+		return
+	}
+
+	msg := &Message{From: fromID, To: toID, MessageID: -1, Sync: &sm}
+	sent := s.deliver(receiver, msg, false)
+
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+	if sent {
+		s.clockSync.messages++
+	} else {
+		s.clockSync.dropped++
+	}
+}
+
+// handles a synchronization message arriving at p.
+// retired and crashed processes ignore it.
+func (s *Simulator) receiveSync(p *Process, msg *Message) {
+	if p.Retired() || p.Crashed() {
+		return
+	}
+
+	sm := msg.Sync
+	switch sm.Kind {
+	case "request":
+		// time server: answer with the current reading
+		s.sendSync(p.ID, msg.From, SyncMessage{Kind: "reply", Round: sm.Round, RequestTime: sm.RequestTime, Time: p.PhysicalClock.Now()})
+	case "reply":
+		// Cristian client: assume the server read its clock halfway through
+		s.adjustClock(p, physical.CristianAdjustment(sm.RequestTime, sm.Time, p.PhysicalClock.Now()))
+	case "poll":
+		s.sendSync(p.ID, msg.From, SyncMessage{Kind: "report", Round: sm.Round, RequestTime: sm.RequestTime, Time: p.PhysicalClock.Now()})
+	case "report":
+		s.collectReport(p, msg.From, physical.BerkeleyOffset(sm.RequestTime, sm.Time, p.PhysicalClock.Now()), sm.Round)
+	case "adjust":
+		s.adjustClock(p, sm.Adjustment)
+	}
+}
+
+// records the offset reported by processID at the Berkeley master and,
+// once every process has reported, corrects all clocks to their average.
+// reports from an earlier round are ignored.
+func (s *Simulator) collectReport(master *Process, processID int, offset int64, round int) {
+	s.syncMu.Lock()
+	st := &s.clockSync
+	if round != st.round || master.ID != st.master {
+		s.syncMu.Unlock()
+		return
+	}
+	st.offsets[processID] = offset
+	st.pending--
+	if st.pending > 0 {
+		s.syncMu.Unlock()
+		return
+	}
+
+	ids := make([]int, 0, len(st.offsets))
+	for id := range st.offsets {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	offsets := []int64{0}
+	for _, id := range ids {
+		offsets = append(offsets, st.offsets[id])
+	}
+	st.round = 0
+	s.syncMu.Unlock()
+
+	adjustments := physical.BerkeleyAdjustments(offsets, 0)
+	s.adjustClock(master, adjustments[0])
+	for i, id := range ids {
+		s.sendSync(master.ID, id, SyncMessage{Kind: "adjust", Round: round, Adjustment: adjustments[i+1]})
+	}
+}
+
+// steps p's physical clock by delta nanoseconds.
+func (s *Simulator) adjustClock(p *Process, delta int64) {
+	p.PhysicalClock.Adjust(delta)
+
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+	s.clockSync.adjustments++
+	s.clockSync.totalAdjustment += max(delta, -delta)
+}
+
+// returns the clock skew sampled every 10ms while the simulation ran.
+func (s *Simulator) SkewSamples() []SkewSample {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+	return append([]SkewSample(nil), s.clockSync.samples...)
+}
+
+// counts event pairs ordered by happened-before whose physical timestamps
+// claim the opposite order. drift, offsets and backward corrections all
+// cause such contradictions; equal readings are not counted.
+func (s *Simulator) CountPhysicalOrderViolations() int {
+	_, violations := s.physicalOrder()
+	return violations
+}
+
+// returns the number of event pairs ordered by happened-before and how many
+// of them the physical timestamps contradict.
+func (s *Simulator) physicalOrder() (int, int) {
+	ordered, violations := 0, 0
+
+	for i := 0; i < len(s.Events); i++ {
+		for j := 0; j < len(s.Events); j++ {
+			e, f := s.Events[i], s.Events[j]
+			if !HappenedBefore(e.VectorTime, f.VectorTime) {
+				continue
+			}
+			ordered++
+			if f.PhysicalTime < e.PhysicalTime {
+				violations++
+			}
+		}
+	}
+
+	return ordered, violations
+}
+
+// returns synchronization counters, sampled clock skew and how often
+// physical timestamps contradict happened-before.
+func (s *Simulator) GetPhysicalClockStatistics() map[string]interface{} {
+	ordered, violations := s.physicalOrder()
+
+	s.syncMu.Lock()
+	st := s.clockSync
+	s.syncMu.Unlock()
+
+	var maxSkew, totalSkew, finalSkew time.Duration
+	for _, sample := range st.samples {
+		maxSkew = max(maxSkew, sample.Skew)
+		totalSkew += sample.Skew
+	}
+	avgSkew := time.Duration(0)
+	if len(st.samples) > 0 {
+		avgSkew = totalSkew / time.Duration(len(st.samples))
+		finalSkew = st.samples[len(st.samples)-1].Skew
+	}
+
+	avgAdjustment := time.Duration(0)
+	if st.adjustments > 0 {
+		avgAdjustment = time.Duration(st.totalAdjustment / int64(st.adjustments))
+	}
+
+	violationRate := 0.0
+	if ordered > 0 {
+		violationRate = float64(violations) / float64(ordered)
+	}
+
+	return map[string]interface{}{
+		"algorithm":        st.algorithm,
+		"sync_rounds":      st.rounds,
+		"sync_messages":    st.messages,
+		"sync_dropped":     st.dropped,
+		"adjustments":      st.adjustments,
+		"avg_adjustment":   avgAdjustment,
+		"max_skew":         maxSkew,
+		"avg_skew":         avgSkew,
+		"final_skew":       finalSkew,
+		"ordered_pairs":    ordered,
+		"order_violations": violations,
+		"violation_rate":   violationRate,
+		"hlc_violations":   s.CountHLCViolations(),
+	}
+}
@@ -0,0 +1,205 @@
+package simulator
+
+import (
+	"testing"
+	"time"
+
+	clock "github.com/simonnyman/DISY_Projects/Synchronization/clock"
+	hlc "github.com/simonnyman/DISY_Projects/Synchronization/hlc"
+	physical "github.com/simonnyman/DISY_Projects/Synchronization/physical"
+)
+
+// verifies every event records a physical timestamp and perfect clocks
+// never contradict happened-before.
+func TestPhysicalTimeRecorded(t *testing.T) {
+	sim := NewSimulator(3)
+	sim.RunSimulation(100*time.Millisecond, 0.3, 0.4)
+
+	for _, e := range sim.Events {
+		if e.PhysicalTime == 0 {
+			t.Fatalf("Event %+v has no physical timestamp", e)
+		}
+	}
+	if v := sim.CountPhysicalOrderViolations(); v != 0 {
+		t.Errorf("Perfect clocks should not contradict happened-before, got %d", v)
+	}
+}
+
+// verifies a receiver whose clock is behind the sender's contradicts the
+// send→receive order, while the HLC reading the same clocks does not.
+func TestPhysicalOrderViolation(t *testing.T) {
+	sim := NewSimulator(2)
+	sim.Processes[0].PhysicalClock.Adjust(int64(time.Hour))
+
+	sim.sendMessage(0, 1)
+	sim.receiveMessage(1, <-sim.Processes[1].inbox)
+
+	if v := sim.CountPhysicalOrderViolations(); v != 1 {
+		t.Errorf("Expected 1 violation, got %d", v)
+	}
+	if v := sim.CountHLCViolations(); v != 0 {
+		t.Errorf("HLC should respect happened-before, got %d violations", v)
+	}
+	send := sim.Events[0].Clocks[clock.HLC].(hlc.Timestamp)
+	if send.Wall < sim.Events[0].PhysicalTime-int64(time.Second) {
+		t.Errorf("HLC should read the adjusted physical clock, got wall %d", send.Wall)
+	}
+}
+
+// verifies the configured drift and offsets are drawn from the seed.
+func TestSetPhysicalClocks(t *testing.T) {
+	cfg := PhysicalConfig{MaxDrift: 1e-3, MaxOffset: 10 * time.Millisecond, Seed: 5}
+	sim1 := NewSimulator(3)
+	sim1.SetPhysicalClocks(cfg)
+	sim2 := NewSimulator(3)
+	sim2.SetPhysicalClocks(cfg)
+
+	for i := 0; i < 3; i++ {
+		d1, d2 := sim1.Processes[i].PhysicalClock.Drift(), sim2.Processes[i].PhysicalClock.Drift()
+		if d1 != d2 {
+			t.Errorf("P%d: same seed should give same drift, got %v and %v", i, d1, d2)
+		}
+		if d1 < -cfg.MaxDrift || d1 > cfg.MaxDrift {
+			t.Errorf("P%d: drift %v outside bound", i, d1)
+		}
+		if err := sim1.Processes[i].PhysicalClock.Error(); err < -int64(11*time.Millisecond) || err > int64(11*time.Millisecond) {
+			t.Errorf("P%d: error %d outside offset bound", i, err)
+		}
+	}
+}
+
+// verifies invalid physical clock and sync configurations panic.
+func TestPhysicalConfigPanics(t *testing.T) {
+	recorded := NewSimulator(1)
+	recorded.generateLocalEvent(0)
+
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"negative drift", func() { NewSimulator(1).SetPhysicalClocks(PhysicalConfig{MaxDrift: -0.1}) }},
+		{"drift 1", func() { NewSimulator(1).SetPhysicalClocks(PhysicalConfig{MaxDrift: 1}) }},
+		{"negative jitter", func() { NewSimulator(1).SetPhysicalClocks(PhysicalConfig{Jitter: -1}) }},
+		{"events recorded", func() { recorded.SetPhysicalClocks(PhysicalConfig{}) }},
+		{"unknown algorithm", func() { NewSimulator(1).SetClockSync("ntp", time.Second) }},
+		{"zero interval", func() { NewSimulator(1).SetClockSync(SyncCristian, 0) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic")
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
+
+// verifies one Cristian round, delivered by hand, aligns the clients with
+// the time server without recording events.
+func TestCristianRound(t *testing.T) {
+	sim := NewSimulator(3)
+	sim.Processes[1].PhysicalClock.Adjust(int64(30 * time.Millisecond))
+	sim.Processes[2].PhysicalClock.Adjust(-int64(60 * time.Millisecond))
+
+	sim.startSyncRound(SyncCristian)
+	for i := 0; i < 2; i++ {
+		sim.receiveMessage(0, <-sim.Processes[0].inbox)
+	}
+	for i := 1; i < 3; i++ {
+		sim.receiveMessage(i, <-sim.Processes[i].inbox)
+	}
+
+	for i := 1; i < 3; i++ {
+		if err := sim.Processes[i].PhysicalClock.Error(); err > int64(time.Millisecond) || err < -int64(time.Millisecond) {
+			t.Errorf("P%d should match the server, error %v", i, time.Duration(err))
+		}
+	}
+	if len(sim.Events) != 0 {
+		t.Errorf("Sync messages should record no events, got %d", len(sim.Events))
+	}
+	if msgs := sim.GetPhysicalClockStatistics()["sync_messages"].(int); msgs != 4 {
+		t.Errorf("Expected 4 sync messages, got %d", msgs)
+	}
+}
+
+// verifies one Berkeley round, delivered by hand, moves every clock to the
+// average.
+func TestBerkeleyRound(t *testing.T) {
+	sim := NewSimulator(3)
+	sim.Processes[1].PhysicalClock.Adjust(int64(30 * time.Millisecond))
+	sim.Processes[2].PhysicalClock.Adjust(-int64(60 * time.Millisecond))
+
+	sim.startSyncRound(SyncBerkeley)
+	for i := 1; i < 3; i++ {
+		sim.receiveMessage(i, <-sim.Processes[i].inbox)
+	}
+	for i := 0; i < 2; i++ {
+		sim.receiveMessage(0, <-sim.Processes[0].inbox)
+	}
+	for i := 1; i < 3; i++ {
+		sim.receiveMessage(i, <-sim.Processes[i].inbox)
+	}
+
+	errors := make([]int64, 3)
+	for i, p := range sim.Processes {
+		errors[i] = p.PhysicalClock.Error()
+	}
+	if spread := physical.Spread(errors); spread > int64(time.Millisecond) {
+		t.Errorf("Clocks should agree after a round, spread %v", time.Duration(spread))
+	}
+	if avg := errors[0]; avg > -int64(9*time.Millisecond) || avg < -int64(11*time.Millisecond) {
+		t.Errorf("Expected clocks 10ms behind, got %v", time.Duration(avg))
+	}
+}
+
+// verifies reports from an earlier Berkeley round are ignored.
+func TestBerkeleyStaleReport(t *testing.T) {
+	sim := NewSimulator(2)
+
+	sim.startSyncRound(SyncBerkeley)
+	poll := <-sim.Processes[1].inbox
+	sim.startSyncRound(SyncBerkeley)
+	<-sim.Processes[1].inbox
+
+	sim.receiveMessage(1, poll)
+	sim.receiveMessage(0, <-sim.Processes[0].inbox)
+
+	if adjustments := sim.GetPhysicalClockStatistics()["adjustments"].(int); adjustments != 0 {
+		t.Errorf("Stale report should not complete a round, got %d adjustments", adjustments)
+	}
+}
+
+// verifies synchronization keeps drifting clocks closer together than
+// leaving them alone.
+func TestClockSyncReducesSkew(t *testing.T) {
+	cfg := PhysicalConfig{MaxDrift: 0.05, MaxOffset: 20 * time.Millisecond, Seed: 3}
+
+	for _, algorithm := range []string{SyncCristian, SyncBerkeley} {
+		t.Run(algorithm, func(t *testing.T) {
+			free := NewSimulator(4)
+			free.SetPhysicalClocks(cfg)
+			free.RunSimulation(150*time.Millisecond, 0.3, 0.3)
+
+			synced := NewSimulator(4)
+			synced.SetPhysicalClocks(cfg)
+			synced.SetClockSync(algorithm, 20*time.Millisecond)
+			synced.RunSimulation(150*time.Millisecond, 0.3, 0.3)
+
+			freeStats := free.GetPhysicalClockStatistics()
+			syncedStats := synced.GetPhysicalClockStatistics()
+			if syncedStats["sync_rounds"].(int) == 0 {
+				t.Fatal("Expected synchronization rounds")
+			}
+			if syncedStats["final_skew"].(time.Duration) >= freeStats["final_skew"].(time.Duration) {
+				t.Errorf("Synchronized skew %v should be below unsynchronized %v",
+					syncedStats["final_skew"], freeStats["final_skew"])
+			}
+			if syncedStats["hlc_violations"].(int) != 0 {
+				t.Errorf("HLC should respect happened-before, got %d violations", syncedStats["hlc_violations"])
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package simulator
+
+// GlobalState is a consistent cut of a recorded run with the local
+// variables of every process at the cut.
+type GlobalState struct {
+	Cut  Cut
+	Vars []map[string]interface{} // variables after each process's last event in the cut, nil before its first
+}
+
+// LocalPredicate is a condition on the variables of one process.
+type LocalPredicate func(vars map[string]interface{}) bool
+
+// makes processes expose local variables to global predicates. update is
+// called at every event a process records, with the event and the
+// process's variables, which it may change; the event then carries a copy
+// of them in Vars. update runs while the process is locked and must not
+// call back into the simulator. set it before running the simulation.
+func (s *Simulator) SetLocalVariables(update func(e Event, vars map[string]interface{})) {
+	s.updateVars = update
+}
+
+// updates p's variables for an event and returns a copy of them.
+// must be called with p.mu held.
+func (s *Simulator) localVariables(p *Process, e Event) map[string]interface{} {
+	if p.vars == nil {
+		p.vars = make(map[string]interface{})
+	}
+	s.updateVars(e, p.vars)
+
+	vars := make(map[string]interface{}, len(p.vars))
+	for name, value := range p.vars {
+		vars[name] = value
+	}
+	return vars
+}
+
+// returns the global state at a cut.
+func globalState(trace [][]Event, cut Cut) GlobalState {
+	vars := make([]map[string]interface{}, len(trace))
+	for i, n := range cut {
+		if n > 0 {
+			vars[i] = trace[i][n-1].Vars
+		}
+	}
+	return GlobalState{Cut: cut, Vars: vars}
+}
+
+// reports whether the recorded run possibly passed through a global state
+// satisfying phi: some consistent cut does (Cooper–Marzullo). returns the
+// first such cut found, in level order. walks the lattice of consistent
+// cuts, which can grow exponentially with the number of processes; use
+// PossiblyConjunctive for conjunctions of local predicates.
+func (s *Simulator) Possibly(phi func(GlobalState) bool) (Cut, bool) {
+	trace := s.processEvents()
+	lower, upper := latticeBounds(trace, LatticeBounds{})
+
+	var witness Cut
+	walkLattice(trace, lower, upper, nil, func(level []Cut) bool {
+		for _, cut := range level {
+			if phi(globalState(trace, cut)) {
+				witness = cut
+				return false
+			}
+		}
+		return true
+	})
+	return witness, witness != nil
+}
+
+// reports whether the recorded run definitely passed through a global
+// state satisfying phi: every sequence of consistent cuts from the initial
+// to the final state, that is every way the run could have been observed,
+// contains one (Cooper–Marzullo). walks the consistent cuts reachable
+// without satisfying phi, which can grow exponentially with the number of
+// processes; use DefinitelyConjunctive for conjunctions of local
+// predicates.
+func (s *Simulator) Definitely(phi func(GlobalState) bool) bool {
+	trace := s.processEvents()
+	lower, upper := latticeBounds(trace, LatticeBounds{})
+
+	avoided := false
+	walkLattice(trace, lower, upper, func(cut Cut) bool {
+		return !phi(globalState(trace, cut))
+	}, func(level []Cut) bool {
+		for _, cut := range level {
+			if cutKey(cut) == cutKey(upper) {
+				avoided = true
+			}
+		}
+		return !avoided
+	})
+	return !avoided
+}
+
+// reports whether the run possibly passed through a global state in which
+// every process's local predicate holds, nil meaning true, without
+// enumerating the lattice (Garg–Waldecker). a candidate local state of
+// each process is advanced past states another candidate has already
+// seen the end of, until the candidates form a consistent cut or one
+// process runs out. returns the earliest such cut, as event counts.
+// takes O(n²m) time for n processes with m events each.
+// panics if local does not give one predicate per process.
+func (s *Simulator) PossiblyConjunctive(local []LocalPredicate) (Cut, bool) {
+	trace := s.processEvents()
+	checkLocalPredicates(trace, local)
+
+	cut := make(Cut, len(trace))
+	for i := range trace {
+		if !nextState(trace, local, i, cut) {
+			return nil, false
+		}
+	}
+
+	for advanced := true; advanced; {
+		advanced = false
+		for j := range trace {
+			if cut[j] == 0 {
+				continue
+			}
+			seen := trace[j][cut[j]-1].VectorTime
+			for i := range trace {
+				// j's state follows the end of i's state
+				if i == j || seen[i] <= frontier(trace, cut, i) {
+					continue
+				}
+				cut[i]++
+				if !nextState(trace, local, i, cut) {
+					return nil, false
+				}
+				advanced = true
+			}
+		}
+	}
+	return cut, true
+}
+
+// moves process i's candidate to its first state from cut[i] on in which
+// its local predicate holds. returns false if there is none.
+func nextState(trace [][]Event, local []LocalPredicate, i int, cut Cut) bool {
+	for ; cut[i] <= len(trace[i]); cut[i]++ {
+		if holdsAt(trace, local, i, cut[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// reports whether process i's local predicate holds after its first n
+// events.
+func holdsAt(trace [][]Event, local []LocalPredicate, i, n int) bool {
+	if local[i] == nil {
+		return true
+	}
+	var vars map[string]interface{}
+	if n > 0 {
+		vars = trace[i][n-1].Vars
+	}
+	return local[i](vars)
+}
+
+// an interval of consecutive local states of one process in which its
+// local predicate holds, as event counts
+type stateInterval struct {
+	first, last int
+}
+
+// reports whether the run definitely passed through a global state in
+// which every process's local predicate holds, nil meaning true, without
+// enumerating the lattice (Garg–Waldecker). that is the case exactly when
+// each process has an interval of states satisfying its predicate such
+// that every interval starts before every other one ends, so no
+// observation can leave one before entering another. an interval whose
+// end does not follow the start of another process's current interval
+// is discarded, as it cannot follow the start of later ones either.
+// takes O(n²m) time for n processes with m events each.
+// panics if local does not give one predicate per process.
+func (s *Simulator) DefinitelyConjunctive(local []LocalPredicate) bool {
+	trace := s.processEvents()
+	checkLocalPredicates(trace, local)
+
+	intervals := make([][]stateInterval, len(trace))
+	for i := range trace {
+		for n := 0; n <= len(trace[i]); n++ {
+			if !holdsAt(trace, local, i, n) {
+				continue
+			}
+			if k := len(intervals[i]); k > 0 && intervals[i][k-1].last == n-1 {
+				intervals[i][k-1].last = n
+			} else {
+				intervals[i] = append(intervals[i], stateInterval{n, n})
+			}
+		}
+		if len(intervals[i]) == 0 {
+			return false
+		}
+	}
+
+	for discarded := true; discarded; {
+		discarded = false
+		for i := range trace {
+			for j := range trace {
+				if i == j || startsBeforeEnd(trace, i, intervals[i][0], j, intervals[j][0]) {
+					continue
+				}
+				intervals[j] = intervals[j][1:]
+				if len(intervals[j]) == 0 {
+					return false
+				}
+				discarded = true
+			}
+		}
+	}
+	return true
+}
+
+// reports whether the event entering process i's interval a happened
+// before the event leaving process j's interval b. an interval from the
+// initial state is entered before everything and one up to the final
+// state is never left.
+func startsBeforeEnd(trace [][]Event, i int, a stateInterval, j int, b stateInterval) bool {
+	if a.first == 0 || b.last == len(trace[j]) {
+		return true
+	}
+	entered := trace[i][a.first-1].VectorTime[i]
+	return trace[j][b.last].VectorTime[i] >= entered
+}
+
+// panics unless local gives one predicate per process.
+func checkLocalPredicates(trace [][]Event, local []LocalPredicate) {
+	if len(local) != len(trace) {
+		panic("simulator: a conjunctive predicate needs one local predicate per process")
+	}
+}
@@ -0,0 +1,181 @@
+package simulator
+
+import (
+	"testing"
+	"time"
+)
+
+// returns a predicate that variable name is set to true.
+func isSet(name string) LocalPredicate {
+	return func(vars map[string]interface{}) bool {
+		v, _ := vars[name].(bool)
+		return v
+	}
+}
+
+// returns the global predicate that every local predicate holds.
+func conjunction(local []LocalPredicate) func(GlobalState) bool {
+	return func(g GlobalState) bool {
+		for i, l := range local {
+			if l != nil && !l(g.Vars[i]) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// verifies every event carries a copy of its process's variables.
+func TestLocalVariables(t *testing.T) {
+	sim := NewSimulator(2)
+	sim.SetLocalVariables(func(e Event, vars map[string]interface{}) {
+		n, _ := vars["events"].(int)
+		vars["events"] = n + 1
+		if e.EventType == "receive" {
+			vars["from"] = e.TargetID
+		}
+	})
+	sim.generateLocalEvent(0)
+	sim.sendMessage(0, 1)
+	sim.receiveMessage(1, <-sim.Processes[1].inbox)
+
+	p0, p1 := sim.Processes[0].Events, sim.Processes[1].Events
+	if p0[0].Vars["events"] != 1 || p0[1].Vars["events"] != 2 {
+		t.Errorf("Expected P0's events to count 1 and 2, got %v and %v", p0[0].Vars, p0[1].Vars)
+	}
+	if p1[0].Vars["events"] != 1 || p1[0].Vars["from"] != 0 {
+		t.Errorf("Expected P1 to note the sender, got %v", p1[0].Vars)
+	}
+	if sim.Events[0].Vars["events"] != 1 {
+		t.Errorf("Expected the global event list to carry variables, got %v", sim.Events[0].Vars)
+	}
+}
+
+// builds the run where P0 sets x, sends to P1, which sets y on receipt and
+// answers, and P0 clears x on the answer; with exchange unset both set
+// and clear their variable without messages.
+func flagRun(exchange bool) *Simulator {
+	sim := NewSimulator(2)
+	sim.SetLocalVariables(func(e Event, vars map[string]interface{}) {
+		name := []string{"x", "y"}[e.ProcessID]
+		switch {
+		case e.EventType == "local" && vars[name] == nil, e.EventType == "receive" && e.ProcessID == 1:
+			vars[name] = true
+		case e.EventType == "local", e.EventType == "receive":
+			vars[name] = false
+		}
+	})
+	if !exchange {
+		for i := 0; i < 2; i++ {
+			sim.generateLocalEvent(0)
+			sim.generateLocalEvent(1)
+		}
+		return sim
+	}
+	sim.generateLocalEvent(0)
+	sim.sendMessage(0, 1)
+	sim.receiveMessage(1, <-sim.Processes[1].inbox)
+	sim.sendMessage(1, 0)
+	sim.receiveMessage(0, <-sim.Processes[0].inbox)
+	sim.generateLocalEvent(1)
+	return sim
+}
+
+// verifies both detectors on runs where x ∧ y possibly holds, and where
+// messages force every observation through it.
+func TestPossiblyDefinitely(t *testing.T) {
+	local := []LocalPredicate{isSet("x"), isSet("y")}
+	tests := []struct {
+		exchange   bool
+		possibly   Cut
+		definitely bool
+	}{
+		{false, Cut{1, 1}, false},
+		{true, Cut{2, 1}, true},
+	}
+
+	for _, tt := range tests {
+		sim := flagRun(tt.exchange)
+
+		cut, ok := sim.Possibly(conjunction(local))
+		if !ok || cutKey(cut) != cutKey(tt.possibly) {
+			t.Errorf("exchange %v: expected Possibly at %v, got %v and %v", tt.exchange, tt.possibly, cut, ok)
+		}
+		if cut, ok := sim.PossiblyConjunctive(local); !ok || cutKey(cut) != cutKey(tt.possibly) {
+			t.Errorf("exchange %v: expected PossiblyConjunctive at %v, got %v and %v", tt.exchange, tt.possibly, cut, ok)
+		}
+		if got := sim.Definitely(conjunction(local)); got != tt.definitely {
+			t.Errorf("exchange %v: expected Definitely %v, got %v", tt.exchange, tt.definitely, got)
+		}
+		if got := sim.DefinitelyConjunctive(local); got != tt.definitely {
+			t.Errorf("exchange %v: expected DefinitelyConjunctive %v, got %v", tt.exchange, tt.definitely, got)
+		}
+	}
+
+	never := []LocalPredicate{isSet("x"), isSet("z")}
+	sim := flagRun(true)
+	if _, ok := sim.PossiblyConjunctive(never); ok || sim.DefinitelyConjunctive(never) {
+		t.Error("A variable never set should not possibly hold")
+	}
+	if sim.Definitely(func(GlobalState) bool { return false }) || !sim.Definitely(func(GlobalState) bool { return true }) {
+		t.Error("Definitely should follow constant predicates")
+	}
+}
+
+// verifies the conjunctive detectors agree with the lattice walks on
+// random runs in which processes step down and claim leadership.
+func TestConjunctiveMatchesLattice(t *testing.T) {
+	outcomes := make(map[[2]bool]int)
+	for seed := int64(1); seed <= 30; seed++ {
+		sim := NewSimulator(3)
+		sim.SetLocalVariables(func(e Event, vars map[string]interface{}) {
+			if e.EventType == "local" {
+				vars["leader"] = e.Timestamp%3 != 0
+			}
+		})
+		sim.RunDiscreteEvent(40*time.Millisecond, 0.4, 0.4, seed)
+
+		// could P0 and P2 both believe they lead?
+		local := []LocalPredicate{isSet("leader"), nil, isSet("leader")}
+		cut, possibly := sim.PossiblyConjunctive(local)
+		if witness, ok := sim.Possibly(conjunction(local)); ok != possibly {
+			t.Errorf("seed %d: Possibly %v (at %v), PossiblyConjunctive %v", seed, ok, witness, possibly)
+		}
+		if possibly && (!sim.IsConsistentCut(cut) || !conjunction(local)(globalState(sim.processEvents(), cut))) {
+			t.Errorf("seed %d: witness %v should be consistent and satisfy the predicate", seed, cut)
+		}
+		definitely := sim.DefinitelyConjunctive(local)
+		if ok := sim.Definitely(conjunction(local)); ok != definitely {
+			t.Errorf("seed %d: Definitely %v, DefinitelyConjunctive %v", seed, ok, definitely)
+		}
+		outcomes[[2]bool{possibly, definitely}]++
+	}
+
+	for _, outcome := range [][2]bool{{false, false}, {true, false}, {true, true}} {
+		if outcomes[outcome] == 0 {
+			t.Errorf("Expected runs with Possibly %v and Definitely %v, got %v", outcome[0], outcome[1], outcomes)
+		}
+	}
+}
+
+// verifies conjunctive predicates need one local predicate per process.
+func TestPredicatePanics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"possibly", func() { NewSimulator(2).PossiblyConjunctive([]LocalPredicate{nil}) }},
+		{"definitely", func() { NewSimulator(2).DefinitelyConjunctive(nil) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic")
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package simulator
+
+import (
+	"fmt"
+
+	dvv "github.com/simonnyman/DISY_Projects/Synchronization/dvv"
+)
+
+// counters for the replicated register
+type registerStats struct {
+	writes          int
+	syncs           int
+	siblingsCreated int // values that entered a replica next to other values
+	siblingsPruned  int // values dropped because a newer write had seen them
+	maxSiblings     int
+}
+
+// writes a new value to the process's replica with the context its
+// client last read, then lets the client read the result.
+// must be called with p.mu held.
+func (s *Simulator) registerWrite(p *Process, value string) {
+	before := p.Replica.Size()
+	p.Replica = p.Replica.Update(p.clientContext, p.ID, value)
+	p.clientContext = p.Replica.Join()
+	after := p.Replica.Size()
+
+	s.registerMu.Lock()
+	defer s.registerMu.Unlock()
+	s.register.writes++
+	if after > 1 {
+		s.register.siblingsCreated++
+	}
+	s.register.siblingsPruned += before + 1 - after
+	s.register.maxSiblings = max(s.register.maxSiblings, after)
+}
+
+// merges a replica received from another process into the process's replica.
+// must be called with p.mu held.
+func (s *Simulator) registerSync(p *Process, remote dvv.Set) {
+	local := make(map[dvv.Dot]bool)
+	for _, d := range p.Replica.Dots() {
+		local[d] = true
+	}
+
+	p.Replica = dvv.Merge(p.Replica, remote)
+
+	added, kept := 0, 0
+	for _, d := range p.Replica.Dots() {
+		if local[d] {
+			kept++
+		} else {
+			added++
+		}
+	}
+	after := p.Replica.Size()
+
+	s.registerMu.Lock()
+	defer s.registerMu.Unlock()
+	s.register.syncs++
+	if after > 1 {
+		s.register.siblingsCreated += added
+	}
+	s.register.siblingsPruned += len(local) - kept
+	s.register.maxSiblings = max(s.register.maxSiblings, after)
+}
+
+// returns the value written by a local event.
+func registerValue(processID int, lamportTime int64) string {
+	return fmt.Sprintf("P%d@%d", processID, lamportTime)
+}
+
+// returns sibling counts for the replicated register.
+// every local event writes the register at its process's replica and
+// every message carries the sender's replica for anti-entropy.
+func (s *Simulator) GetRegisterStatistics() map[string]interface{} {
+	s.registerMu.Lock()
+	stats := s.register
+	s.registerMu.Unlock()
+
+	finalSiblings := 0
+	for _, p := range s.Processes {
+		if !p.Retired() {
+			finalSiblings = max(finalSiblings, p.Replica.Size())
+		}
+	}
+
+	return map[string]interface{}{
+		"writes":           stats.writes,
+		"syncs":            stats.syncs,
+		"siblings_created": stats.siblingsCreated,
+		"siblings_pruned":  stats.siblingsPruned,
+		"max_siblings":     stats.maxSiblings,
+		"final_siblings":   finalSiblings,
+	}
+}
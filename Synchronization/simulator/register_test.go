@@ -0,0 +1,76 @@
+package simulator
+
+import (
+	"testing"
+	"time"
+)
+
+// verifies local writes on one replica replace each other.
+func TestRegisterSequentialWrites(t *testing.T) {
+	sim := NewSimulator(2)
+
+	sim.generateLocalEvent(0)
+	sim.generateLocalEvent(0)
+	sim.generateLocalEvent(0)
+
+	stats := sim.GetRegisterStatistics()
+	if stats["writes"].(int) != 3 {
+		t.Errorf("Expected 3 writes, got %d", stats["writes"])
+	}
+	if stats["siblings_created"].(int) != 0 {
+		t.Errorf("Sequential writes should create no siblings, got %d", stats["siblings_created"])
+	}
+	if stats["siblings_pruned"].(int) != 2 {
+		t.Errorf("Each overwrite should prune one value, got %d", stats["siblings_pruned"])
+	}
+	if sim.Processes[0].Replica.Values()[0] != "P0@3" {
+		t.Errorf("Expected last write P0@3, got %v", sim.Processes[0].Replica.Values())
+	}
+}
+
+// verifies concurrent writes on different replicas become siblings on sync
+// and a later write that has seen both resolves them.
+func TestRegisterConcurrentWrites(t *testing.T) {
+	sim := NewSimulator(2)
+
+	sim.generateLocalEvent(0)
+	sim.generateLocalEvent(1)
+
+	sim.sendMessage(0, 1)
+	msg := <-sim.Processes[1].inbox
+	sim.receiveMessage(1, msg)
+
+	if size := sim.Processes[1].Replica.Size(); size != 2 {
+		t.Fatalf("P1 should hold 2 siblings after sync, got %d", size)
+	}
+	if created := sim.GetRegisterStatistics()["siblings_created"].(int); created != 1 {
+		t.Errorf("Expected 1 sibling created, got %d", created)
+	}
+
+	// the local client has not read the merged state yet, so its write
+	// only replaces its own previous value
+	sim.generateLocalEvent(1)
+	if size := sim.Processes[1].Replica.Size(); size != 2 {
+		t.Errorf("Stale client write should keep P0's sibling, got %d", size)
+	}
+
+	// after reading, the next write resolves the conflict
+	sim.generateLocalEvent(1)
+	if size := sim.Processes[1].Replica.Size(); size != 1 {
+		t.Errorf("Write after read should resolve siblings, got %d", size)
+	}
+}
+
+// verifies a full run reports consistent register statistics.
+func TestRegisterRunSimulation(t *testing.T) {
+	sim := NewSimulator(4)
+	sim.RunSimulation(100*time.Millisecond, 0.4, 0.4)
+
+	stats := sim.GetRegisterStatistics()
+	if stats["writes"].(int) != sim.GetStatistics()["local_events"].(int) {
+		t.Errorf("Every local event should write, got %d writes", stats["writes"])
+	}
+	if stats["syncs"].(int) != sim.GetStatistics()["receive_events"].(int) {
+		t.Errorf("Every receive should sync, got %d syncs", stats["syncs"])
+	}
+}
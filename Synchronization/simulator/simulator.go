@@ -1,41 +1,137 @@
 package simulator
 
 import (
+	"fmt"
 	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	causal "github.com/simonnyman/DISY_Projects/Synchronization/causal"
+	clock "github.com/simonnyman/DISY_Projects/Synchronization/clock"
+	dvv "github.com/simonnyman/DISY_Projects/Synchronization/dvv"
 	lamport "github.com/simonnyman/DISY_Projects/Synchronization/lamport"
+	mutex "github.com/simonnyman/DISY_Projects/Synchronization/mutex"
+	network "github.com/simonnyman/DISY_Projects/Synchronization/network"
+	physical "github.com/simonnyman/DISY_Projects/Synchronization/physical"
+	totalorder "github.com/simonnyman/DISY_Projects/Synchronization/totalorder"
 	vector "github.com/simonnyman/DISY_Projects/Synchronization/vector"
 )
 
 // Event represents a single event in the distributed system.
 type Event struct {
-	ProcessID  int     // process that generated the event
-	EventType  string  // "local", "send", or "receive"
-	Timestamp  int64   // Lamport timestamp
-	VectorTime []int64 // Vector clock timestamp
-	TargetID   int     // for send: receiver, for receive: sender, -1 for local
-	MessageID  int     // unique message identifier, -1 for local events
+	ProcessID    int                        // process that generated the event
+	EventType    string                     // "local", "send", "receive", "spawn", "start", "retire", "join", "crash", "recover", "broadcast", "received", "multicast", "delivered", "enter" or "exit"
+	Timestamp    int64                      // Lamport timestamp
+	VectorTime   []int64                    // Vector clock timestamp
+	Clocks       map[string]clock.Timestamp // timestamp of each configured clock, keyed by name
+	PhysicalTime int64                      // reading of the process's physical clock in nanoseconds
+	TargetID     int                        // for send: receiver, for receive: sender, -1 for local and broadcast
+	MessageID    int                        // unique message identifier, -1 for local events
+	Vars         map[string]interface{}     // process's local variables after the event, nil without SetLocalVariables
 }
 
 // Process represents a single process in the distributed system.
+// the Lamport and vector clocks are always present because the analyses use
+// them as ground truth; any further clocks are configured per simulator.
 type Process struct {
-	ID           int
-	LamportClock *lamport.LamportClock
-	VectorClock  *vector.Vector
-	Events       []Event
-	inbox        chan *Message
+	ID            int
+	Name          string // hostname-style identifier used by the sparse clock
+	LamportClock  *lamport.LamportClock
+	VectorClock   *vector.Vector
+	PhysicalClock *physical.Clock        // simulated hardware clock, also read by the HLC
+	Clocks        map[string]clock.Clock // configured clocks keyed by name
+	Replica       dvv.Set                // this process's replica of the register
+	Events        []Event
+	clientContext []int64 // context the local client last read
+	inbox         chan *Message
+	stop          chan struct{} // closed when the process retires
+	retired       atomic.Bool
+	crashed       atomic.Bool
+	backlog       []*Message    // messages that arrived while crashed, in arrival order
+	resume        chan struct{} // closed when the process recovers, nil while running
+	stable        stableClocks  // clocks as the durability policy last saved them
+	unsaved       int           // events recorded since the last checkpoint
+	holdBack      *causal.Queue
+	orderer       totalorder.Protocol      // total-order multicast protocol instance
+	locker        mutex.Protocol           // mutual exclusion instance
+	csEntry       int                      // index of the latest critical section request
+	outbox        []*Message               // protocol messages waiting to be sent
+	wake          chan struct{}            // signals the sender goroutine that outbox has packets
+	linkSent      map[int]int              // messages numbered on each outgoing link
+	linkNext      map[int]int              // next position expected on each incoming link, FIFOChannels only
+	overtaken     map[int]map[int]*Message // messages waiting for an earlier one, by link and position
+	vars          map[string]interface{}   // local variables exposed to global predicates
+	mu            sync.Mutex               // serializes clock updates with event recording
 }
 
 // Simulator manages the distributed system simulation.
 type Simulator struct {
-	Processes        []*Process
-	NumProcesses     int
-	Events           []Event
+	Processes    []*Process
+	NumProcesses int
+	MaxProcesses int      // slots reserved in vector and matrix clocks
+	ClockNames   []string // configured clocks besides Lamport and vector
+	BloomSize    int      // cells in each Bloom clock
+	BloomHashes  int      // hash functions per Bloom clock event
+	Events       []Event
+
+	// DifferentialVectors makes messages carry only the vector clock entries
+	// that changed since the sender's last message to the same destination
+	// (Singhal–Kshemkalyani). set it before running the simulation.
+	// the technique assumes reliable FIFO links; over a network model that
+	// drops or reorders messages receivers can miss entries.
+	DifferentialVectors bool
+
+	// CausalDelivery turns every send into a broadcast to all other live
+	// processes that is held back until its causal predecessors have been
+	// delivered (Birman–Schiper–Stephenson). arrivals record "received"
+	// events and deliveries "delivered" events. broadcasts carry full
+	// vectors even with DifferentialVectors set, and the process group must
+	// stay fixed. set it before running the simulation.
+	CausalDelivery bool
+
+	// FIFOChannels makes every link deliver messages in the order they were
+	// sent, whatever the network does: each message is numbered on its
+	// link, a message that overtakes an earlier one is held at the receiver
+	// until the earlier one arrives, and duplicate copies are discarded.
+	// a lost message stalls its link. clock synchronization messages are
+	// not ordered. snapshots need it. set it before running the simulation.
+	FIFOChannels bool
+
+	factories        map[string]clock.Factory
 	messageIDCounter int
-	counterMu        sync.Mutex // protects messageIDCounter
-	eventsMu         sync.Mutex // protects Events slice
+	vectorBytes      int          // vector clock bytes piggybacked on messages
+	vectorMessages   int          // messages counted in vectorBytes
+	counterMu        sync.Mutex   // protects messageIDCounter, vectorBytes and vectorMessages
+	eventsMu         sync.Mutex   // protects Events slice
+	procMu           sync.RWMutex // protects Processes and NumProcesses
+	churnMu          sync.Mutex   // serializes spawning and retiring
+	register         registerStats
+	registerMu       sync.Mutex // protects register
+	physicalConfig   PhysicalConfig
+	clockSync        syncState
+	syncMu           sync.Mutex // protects clockSync
+	sched            *scheduler // virtual time and pending steps, nil unless run discretely
+	network          network.Network
+	halt             chan bool // closed when a real-time run stops, nil outside real-time runs
+	partition        partitionState
+	partitionMu      sync.Mutex // protects partition
+	durability       string
+	checkpointEvery  int // events between checkpoints
+	faults           faultState
+	faultMu          sync.Mutex // protects faults
+	causal           causalStats
+	causalMu         sync.Mutex // protects causal
+	totalOrder       string     // total-order multicast algorithm, TotalOrderNone for point-to-point sends
+	order            totalOrderStats
+	orderMu          sync.Mutex // protects order
+	exclusion        exclusionState
+	exclusionMu      sync.Mutex // protects exclusion
+	fifo             fifoStats
+	fifoMu           sync.Mutex // protects fifo
+	snapshot         snapshotState
+	snapshotMu       sync.Mutex // protects snapshot
+	updateVars       func(e Event, vars map[string]interface{})
 }
 
 // Message represents a message sent between processes.
@@ -43,193 +139,510 @@ type Message struct {
 	From        int
 	To          int
 	LamportTime int64
-	VectorTime  []int64
+	VectorTime  []int64                    // full vector, nil in differential mode
+	VectorDiff  []vector.Entry             // changed entries, differential mode only
+	Clocks      map[string]clock.Timestamp // timestamp of each configured clock
+	Replica     dvv.Set                    // sender's register replica for anti-entropy
 	MessageID   int
+	Broadcasts  []int              // sender's causal broadcast counts, nil for point-to-point messages
+	Sync        *SyncMessage       // clock synchronization payload, nil for application messages
+	Packet      *totalorder.Packet // total-order multicast packet, nil otherwise
+	Mutex       *mutex.Packet      // mutual exclusion packet, nil otherwise
+	LinkSeq     int                // position on the link from From to To, 0 for clock synchronization messages
+	Marker      int                // ID of the snapshot a Chandy–Lamport marker belongs to, 0 otherwise
 }
 
+// interval at which processes generate events and churn happens
+const tickInterval = 10 * time.Millisecond
+
+// clocks a simulator runs unless configured otherwise
+var DefaultClocks = []string{clock.HLC, clock.Matrix, clock.ITC, clock.Sparse, clock.Bloom}
+
 // creates a new simulator with the specified number of processes.
 // panics if numProcesses is less than 1.
 func NewSimulator(numProcesses int) *Simulator {
+	return NewSimulatorWithCapacity(numProcesses, numProcesses)
+}
+
+// creates a new simulator that starts with numProcesses processes and can
+// spawn more up to maxProcesses. vector and matrix clocks reserve a slot for
+// every possible process; interval tree clocks need no such bound.
+// panics if numProcesses is less than 1 or greater than maxProcesses.
+func NewSimulatorWithCapacity(numProcesses, maxProcesses int) *Simulator {
+	return NewSimulatorWithClocks(numProcesses, maxProcesses, DefaultClocks...)
+}
+
+// creates a new simulator that runs the named clocks from the clock
+// registry next to the Lamport and vector clocks.
+// panics if numProcesses is less than 1 or greater than maxProcesses,
+// or if a clock is unknown or named twice.
+func NewSimulatorWithClocks(numProcesses, maxProcesses int, clocks ...string) *Simulator {
 	if numProcesses < 1 {
 		panic("simulator: number of processes must be at least 1")
 	}
+	if maxProcesses < numProcesses {
+		panic("simulator: maxProcesses must be at least numProcesses")
+	}
 
-	processes := make([]*Process, numProcesses)
-
-	for i := 0; i < numProcesses; i++ {
-		processes[i] = &Process{
-			ID:           i,
-			LamportClock: lamport.NewLamportClock(),
-			VectorClock:  vector.NewVector(i, numProcesses),
-			Events:       make([]Event, 0),
-			inbox:        make(chan *Message, 100),
+	factories := make(map[string]clock.Factory, len(clocks))
+	for _, name := range clocks {
+		factory, ok := clock.Lookup(name)
+		if !ok {
+			panic("simulator: unknown clock " + name)
+		}
+		if _, ok := factories[name]; ok {
+			panic("simulator: clock " + name + " configured twice")
 		}
+		factories[name] = factory
 	}
 
-	return &Simulator{
-		Processes:        processes,
+	s := &Simulator{
+		Processes:        make([]*Process, numProcesses),
 		NumProcesses:     numProcesses,
+		MaxProcesses:     maxProcesses,
+		ClockNames:       append([]string(nil), clocks...),
+		BloomSize:        clock.DefaultBloomSize,
+		BloomHashes:      clock.DefaultBloomHashes,
 		Events:           make([]Event, 0),
+		factories:        factories,
 		messageIDCounter: 0,
+		clockSync:        syncState{algorithm: SyncNone},
+		durability:       DurabilityNone,
+	}
+
+	for i := 0; i < numProcesses; i++ {
+		s.Processes[i] = s.newProcess(i, nil)
+	}
+
+	return s
+}
+
+// reports whether the simulator runs the named clock.
+func (s *Simulator) HasClock(name string) bool {
+	_, ok := s.factories[name]
+	return ok
+}
+
+// replaces every process's Bloom clock with one of the given filter size
+// and hash count. must be called before the simulation records any events.
+// panics if size or hashes is less than 1, if the Bloom clock is not
+// configured, or if events were recorded.
+func (s *Simulator) SetBloomFilter(size, hashes int) {
+	if size < 1 || hashes < 1 {
+		panic("simulator: Bloom filter size and hash count must be at least 1")
+	}
+	if !s.HasClock(clock.Bloom) {
+		panic("simulator: Bloom clock is not configured")
+	}
+
+	s.eventsMu.Lock()
+	recorded := len(s.Events)
+	s.eventsMu.Unlock()
+	if recorded > 0 {
+		panic("simulator: cannot change Bloom filter after events were recorded")
+	}
+
+	s.procMu.Lock()
+	defer s.procMu.Unlock()
+	s.BloomSize, s.BloomHashes = size, hashes
+	s.factories[clock.Bloom] = clock.NewBloomFactory(size, hashes)
+	for _, p := range s.Processes {
+		p.Clocks[clock.Bloom] = s.factories[clock.Bloom](s.clockConfig(p.ID, p.PhysicalClock))
+	}
+}
+
+// creates a process with fresh clocks sized for the simulator's capacity.
+// clocks present in forked are used instead of creating new ones.
+func (s *Simulator) newProcess(id int, forked map[string]clock.Clock) *Process {
+	pc := s.newPhysicalClock(id)
+	clocks := make(map[string]clock.Clock, len(s.factories))
+	for name, factory := range s.factories {
+		if c, ok := forked[name]; ok {
+			clocks[name] = c
+		} else {
+			clocks[name] = factory(s.clockConfig(id, pc))
+		}
+	}
+
+	return &Process{
+		ID:            id,
+		Name:          ProcessName(id),
+		LamportClock:  lamport.NewLamportClock(),
+		VectorClock:   vector.NewVector(id, s.MaxProcesses),
+		PhysicalClock: pc,
+		Clocks:        clocks,
+		Replica:       dvv.NewSet(s.MaxProcesses),
+		Events:        make([]Event, 0),
+		clientContext: make([]int64, s.MaxProcesses),
+		inbox:         make(chan *Message, 100),
+		stop:          make(chan struct{}),
+		wake:          make(chan struct{}, 1),
+	}
+}
+
+// returns the configuration for a clock of the given process, which reads
+// physical time from pc.
+func (s *Simulator) clockConfig(id int, pc *physical.Clock) clock.Config {
+	return clock.Config{
+		ProcessID:    id,
+		Name:         ProcessName(id),
+		NumProcesses: s.NumProcesses,
+		Capacity:     s.MaxProcesses,
+		Now:          pc.Now,
 	}
 }
 
+// returns the hostname-style name of a process.
+func ProcessName(id int) string {
+	return fmt.Sprintf("proc-%d.sim.local", id)
+}
+
+// reports whether the process has been retired.
+func (p *Process) Retired() bool {
+	return p.retired.Load()
+}
+
+// records a local event on every configured clock.
+// must be called with p.mu held.
+func (p *Process) tickClocks() map[string]clock.Timestamp {
+	times := make(map[string]clock.Timestamp, len(p.Clocks))
+	for name, c := range p.Clocks {
+		times[name] = c.Tick()
+	}
+	return times
+}
+
+// records a send event on every configured clock.
+// must be called with p.mu held.
+func (p *Process) sendClocks() map[string]clock.Timestamp {
+	times := make(map[string]clock.Timestamp, len(p.Clocks))
+	for name, c := range p.Clocks {
+		times[name] = c.Send()
+	}
+	return times
+}
+
+// records a receive event on every configured clock.
+// must be called with p.mu held.
+func (p *Process) receiveClocks(from int, received map[string]clock.Timestamp) map[string]clock.Timestamp {
+	times := make(map[string]clock.Timestamp, len(p.Clocks))
+	for name, c := range p.Clocks {
+		times[name] = c.Receive(from, received[name])
+	}
+	return times
+}
+
+// returns the process with the given ID, or false if it does not exist.
+func (s *Simulator) lookup(processID int) (*Process, bool) {
+	s.procMu.RLock()
+	defer s.procMu.RUnlock()
+
+	if processID < 0 || processID >= s.NumProcesses {
+		return nil, false
+	}
+	return s.Processes[processID], true
+}
+
+// returns a unique message identifier.
+func (s *Simulator) nextMessageID() int {
+	s.counterMu.Lock()
+	defer s.counterMu.Unlock()
+	msgID := s.messageIDCounter
+	s.messageIDCounter++
+	return msgID
+}
+
 // generates a local event for the specified process.
 // panics if processID is out of bounds.
 func (s *Simulator) generateLocalEvent(processID int) {
-	if processID < 0 || processID >= s.NumProcesses {
+	p, ok := s.lookup(processID)
+	if !ok {
 		panic("simulator: processID out of bounds")
 	}
 
-	p := s.Processes[processID]
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Retired() || p.Crashed() {
+		return
+	}
 
 	lt := p.LamportClock.Tick()
 	vt := p.VectorClock.Tick()
+	times := p.tickClocks()
 
 	e := Event{
-		ProcessID:  processID,
-		EventType:  "local",
-		Timestamp:  lt,
-		VectorTime: vt,
-		TargetID:   -1,
-		MessageID:  -1,
+		ProcessID:    processID,
+		EventType:    "local",
+		Timestamp:    lt,
+		VectorTime:   vt,
+		Clocks:       times,
+		PhysicalTime: p.PhysicalClock.Now(),
+		TargetID:     -1,
+		MessageID:    -1,
 	}
 
-	p.Events = append(p.Events, e)
-	s.appendEvent(e)
+	s.recordEvent(p, e)
+	s.registerWrite(p, registerValue(processID, lt))
 }
 
 // sends a message from one process to another.
+// retired and crashed senders do nothing.
 // panics if fromID or toID is out of bounds.
 func (s *Simulator) sendMessage(fromID, toID int) {
-	if fromID < 0 || fromID >= s.NumProcesses {
+	sender, ok := s.lookup(fromID)
+	if !ok {
 		panic("simulator: fromID out of bounds")
 	}
-	if toID < 0 || toID >= s.NumProcesses {
+	receiver, ok := s.lookup(toID)
+	if !ok {
 		panic("simulator: toID out of bounds")
 	}
 
-	sender := s.Processes[fromID]
-	receiver := s.Processes[toID]
-
 	// update sender's clocks
+	sender.mu.Lock()
+	if sender.Retired() || sender.Crashed() {
+		sender.mu.Unlock()
+		return
+	}
 	lt := sender.LamportClock.Send()
-	vt := sender.VectorClock.Send()
+	var vt []int64
+	var diff []vector.Entry
+	if s.DifferentialVectors {
+		vt, diff = sender.VectorClock.SendTo(toID)
+	} else {
+		vt = sender.VectorClock.Send()
+	}
+	times := sender.sendClocks()
+	linkSeq := sender.nextLinkSeq(toID)
 
 	// get unique message ID
-	s.counterMu.Lock()
-	msgID := s.messageIDCounter
-	s.messageIDCounter++
-	s.counterMu.Unlock()
+	msgID := s.nextMessageID()
+
+	// record the send event
+	e := Event{
+		ProcessID:    fromID,
+		EventType:    "send",
+		Timestamp:    lt,
+		VectorTime:   vt,
+		Clocks:       times,
+		PhysicalTime: sender.PhysicalClock.Now(),
+		TargetID:     toID,
+		MessageID:    msgID,
+	}
+
+	s.recordEvent(sender, e)
+	replica := sender.Replica
+	sender.mu.Unlock()
 
-	// create and send the message
+	// send the message after releasing the lock so a full inbox cannot block
+	// the sender's own receiver goroutine
 	msg := &Message{
 		From:        fromID,
 		To:          toID,
 		LamportTime: lt,
-		VectorTime:  vt,
+		Clocks:      times,
+		Replica:     replica,
 		MessageID:   msgID,
+		LinkSeq:     linkSeq,
 	}
-	receiver.inbox <- msg
-
-	// record the send event
-	e := Event{
-		ProcessID:  fromID,
-		EventType:  "send",
-		Timestamp:  lt,
-		VectorTime: vt,
-		TargetID:   toID,
-		MessageID:  msgID,
+	if s.DifferentialVectors {
+		msg.VectorDiff = diff
+		s.recordPiggyback(vector.EntrySize * len(diff))
+	} else {
+		msg.VectorTime = vt
+		s.recordPiggyback(8 * len(vt))
 	}
+	s.deliver(receiver, msg, true)
+}
 
-	sender.Events = append(sender.Events, e)
-	s.appendEvent(e)
+// records the vector clock bytes carried by one message.
+func (s *Simulator) recordPiggyback(bytes int) {
+	s.counterMu.Lock()
+	defer s.counterMu.Unlock()
+	s.vectorBytes += bytes
+	s.vectorMessages++
 }
 
 // processes a received message and updates clocks.
+// messages arriving at a retired process are dropped and messages arriving
+// at a crashed process wait until it recovers; clock synchronization
+// messages are handed to the sync protocol and record no events, and
+// total-order packets to the multicast protocol. with FIFOChannels
+// messages are received in the order they were sent on their link.
 // panics if processID is out of bounds.
 func (s *Simulator) receiveMessage(processID int, msg *Message) {
-	if processID < 0 || processID >= s.NumProcesses {
+	receiver, ok := s.lookup(processID)
+	if !ok {
 		panic("simulator: processID out of bounds")
 	}
+	if msg.Sync != nil {
+		s.receiveSync(receiver, msg)
+		return
+	}
 
-	receiver := s.Processes[processID]
+	receiver.mu.Lock()
+	for _, msg := range s.resequence(receiver, msg) {
+		switch {
+		case receiver.Retired():
+		case receiver.Crashed():
+			s.stash(receiver, msg)
+		default:
+			s.accept(receiver, msg)
+		}
+	}
+	receiver.mu.Unlock()
 
+	// total-order replies are sent after unlocking
+	s.flush(receiver)
+}
+
+// applies a message at its receiver; snapshot markers go to the snapshot
+// protocol, which also records the other messages on channels it watches,
+// broadcasts go through the causal hold-back queue first, total-order
+// packets to the multicast protocol, and mutual exclusion packets to the
+// algorithm after their receive event.
+// must be called with receiver.mu held.
+func (s *Simulator) accept(receiver *Process, msg *Message) {
+	if msg.Marker > 0 {
+		s.receiveMarker(receiver, msg)
+		return
+	}
+	s.recordInFlight(receiver, msg)
+	if msg.Packet != nil {
+		s.receivePacket(receiver, msg)
+		return
+	}
+	if msg.Broadcasts != nil {
+		s.receiveBroadcast(receiver, msg)
+		return
+	}
+	s.receiveLocked(receiver, msg, "receive")
+	if msg.Mutex != nil {
+		s.receiveMutex(receiver, msg)
+	}
+}
+
+// updates the receiver's clocks with a message and records a receive event
+// of the given type.
+// must be called with receiver.mu held.
+func (s *Simulator) receiveLocked(receiver *Process, msg *Message, eventType string) {
 	// update receiver's clocks with message timestamps
 	lt := receiver.LamportClock.Receive(msg.LamportTime)
-	vt := receiver.VectorClock.Receive(msg.VectorTime)
+	var vt []int64
+	if msg.VectorTime == nil {
+		// rebuild the full clock from the entries that changed
+		vt = receiver.VectorClock.ReceiveEntries(msg.VectorDiff)
+	} else {
+		vt = receiver.VectorClock.Receive(msg.VectorTime)
+	}
+	times := receiver.receiveClocks(msg.From, msg.Clocks)
 
 	// record the receive event
 	e := Event{
-		ProcessID:  processID,
-		EventType:  "receive",
-		Timestamp:  lt,
-		VectorTime: vt,
-		TargetID:   msg.From,
-		MessageID:  msg.MessageID,
+		ProcessID:    receiver.ID,
+		EventType:    eventType,
+		Timestamp:    lt,
+		VectorTime:   vt,
+		Clocks:       times,
+		PhysicalTime: receiver.PhysicalClock.Now(),
+		TargetID:     msg.From,
+		MessageID:    msg.MessageID,
 	}
 
-	receiver.Events = append(receiver.Events, e)
-	s.appendEvent(e)
+	s.recordEvent(receiver, e)
+	s.registerSync(receiver, msg.Replica)
 }
 
-// appends an event to the global event list in a thread-safe manner.
-func (s *Simulator) appendEvent(e Event) {
+// appends an event to the process's and the global event list in a
+// thread-safe manner and saves the clocks per the durability policy. with
+// SetLocalVariables the event carries the process's variables after it.
+// must be called with p.mu held.
+func (s *Simulator) recordEvent(p *Process, e Event) {
+	if s.updateVars != nil {
+		e.Vars = s.localVariables(p, e)
+	}
+	p.Events = append(p.Events, e)
 	s.eventsMu.Lock()
 	s.Events = append(s.Events, e)
 	s.eventsMu.Unlock()
+	s.persist(p)
 }
 
 // runs the simulation for the specified duration.
 func (s *Simulator) RunSimulation(duration time.Duration, localEventProb, sendEventProb float64) {
+	checkRunParameters(duration, localEventProb, sendEventProb, 0, 0)
+	s.run(duration, localEventProb, sendEventProb, 0, 0)
+}
+
+// panics if a probability is out of range or duration is not positive.
+func checkRunParameters(duration time.Duration, localEventProb, sendEventProb, spawnProb, retireProb float64) {
 	if localEventProb < 0 || localEventProb > 1 {
 		panic("simulator: localEventProb must be between 0 and 1")
 	}
 	if sendEventProb < 0 || sendEventProb > 1 {
 		panic("simulator: sendEventProb must be between 0 and 1")
 	}
+	if spawnProb < 0 || retireProb < 0 || spawnProb+retireProb > 1 {
+		panic("simulator: spawnProb and retireProb must be between 0 and 1")
+	}
 	if duration <= 0 {
 		panic("simulator: duration must be positive")
 	}
+}
+
+// runs the simulation, optionally spawning and retiring processes every tick.
+// panics if the simulator ran in discrete-event mode.
+func (s *Simulator) run(duration time.Duration, localEventProb, sendEventProb, spawnProb, retireProb float64) {
+	if s.sched != nil {
+		panic("simulator: cannot run in real time after a discrete-event run")
+	}
 
 	var wg sync.WaitGroup
 	stopChan := make(chan bool)
+	s.halt = stopChan
 
-	// start goroutines for each process
-	for i := 0; i < s.NumProcesses; i++ {
-		wg.Add(2)
+	// starts the goroutines for one process
+	start := func(processID int) {
+		process, _ := s.lookup(processID)
+		wg.Add(3)
 
 		// event generator goroutine
-		go func(processID int) {
+		go func() {
 			defer wg.Done()
-			ticker := time.NewTicker(10 * time.Millisecond)
+			ticker := time.NewTicker(tickInterval)
 			defer ticker.Stop()
+			rng := rand.New(rand.NewSource(rand.Int63()))
 
 			for {
 				select {
 				case <-stopChan:
 					return
+				case <-process.stop:
+					return
 				case <-ticker.C:
-					r := rand.Float64()
-					if r < localEventProb {
-						s.generateLocalEvent(processID)
-					} else if r < localEventProb+sendEventProb {
-						// send to random process (not self)
-						toID := rand.Intn(s.NumProcesses)
-						if toID != processID {
-							s.sendMessage(processID, toID)
-						}
-					}
+					s.generateEvent(processID, rng, localEventProb, sendEventProb)
 				}
 			}
-		}(i)
+		}()
 
 		// message receiver goroutine
-		go func(processID int) {
+		// stops draining while the process is crashed, leaving messages
+		// in the inbox, and keeps draining after retirement, when
+		// receiveMessage drops them, so senders never block on a retired
+		// process
+		go func() {
 			defer wg.Done()
-			process := s.Processes[processID]
 
 			for {
+				if recovered := process.recovery(); recovered != nil {
+					select {
+					case <-stopChan:
+						return
+					case <-process.stop:
+					case <-recovered:
+					}
+				}
 				select {
 				case <-stopChan:
 					return
@@ -237,7 +650,60 @@ func (s *Simulator) RunSimulation(duration time.Duration, localEventProb, sendEv
 					s.receiveMessage(processID, msg)
 				}
 			}
-		}(i)
+		}()
+
+		// protocol message sender goroutine
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-stopChan:
+					return
+				case <-process.wake:
+					s.drainOutbox(process)
+				}
+			}
+		}()
+	}
+
+	// start goroutines for each process
+	for i := 0; i < s.NumProcesses; i++ {
+		start(i)
+	}
+
+	// physical clock sampling and synchronization goroutine
+	s.runClockSync(&wg, stopChan)
+
+	// partition schedule goroutine
+	s.runPartitions(&wg, stopChan, duration)
+
+	// crash schedule goroutine
+	s.runFaults(&wg, stopChan, duration)
+
+	// snapshot schedule goroutine
+	s.runSnapshots(&wg, stopChan, duration)
+
+	// churn goroutine
+	if spawnProb > 0 || retireProb > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(tickInterval)
+			defer ticker.Stop()
+			rng := rand.New(rand.NewSource(rand.Int63()))
+
+			for {
+				select {
+				case <-stopChan:
+					return
+				case <-ticker.C:
+					if childID, ok := s.churn(rng, spawnProb, retireProb); ok {
+						start(childID)
+					}
+				}
+			}
+		}()
 	}
 
 	// run simulation for specified duration
@@ -251,4 +717,51 @@ func (s *Simulator) RunSimulation(duration time.Duration, localEventProb, sendEv
 
 	// wait for all goroutines to finish
 	wg.Wait()
+	s.halt = nil
+
+	s.checkTotalOrder()
+}
+
+// lets a process generate a local event with localEventProb or send a
+// message to a random live peer with sendEventProb, unless it is crashed, a
+// random fault crashes or recovers it, or it requests or leaves the
+// critical section. with a total-order algorithm the
+// message is multicast to the whole group instead, and with CausalDelivery
+// it is broadcast to all live peers.
+func (s *Simulator) generateEvent(processID int, rng *rand.Rand, localEventProb, sendEventProb float64) {
+	if s.randomFault(processID, rng) || s.exclusionStep(processID, rng) {
+		return
+	}
+
+	r := rng.Float64()
+	if r < localEventProb {
+		s.generateLocalEvent(processID)
+	} else if r < localEventProb+sendEventProb {
+		if s.totalOrder != TotalOrderNone {
+			s.multicast(processID)
+			return
+		}
+		if s.CausalDelivery {
+			s.broadcast(processID)
+			return
+		}
+		// send to random live process (not self)
+		if toID, ok := s.randomPeer(processID, rng); ok {
+			s.sendMessage(processID, toID)
+		}
+	}
+}
+
+// picks a random process other than processID.
+// returns false if the pick is the process itself or a retired process.
+func (s *Simulator) randomPeer(processID int, rng *rand.Rand) (int, bool) {
+	s.procMu.RLock()
+	toID := rng.Intn(s.NumProcesses)
+	s.procMu.RUnlock()
+
+	if toID == processID {
+		return 0, false
+	}
+	p, _ := s.lookup(toID)
+	return toID, !p.Retired()
 }
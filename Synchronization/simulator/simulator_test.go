@@ -1,8 +1,17 @@
 package simulator
 
 import (
+	"math/big"
+	"reflect"
 	"testing"
 	"time"
+
+	clock "github.com/simonnyman/DISY_Projects/Synchronization/clock"
+	encoded "github.com/simonnyman/DISY_Projects/Synchronization/encoded"
+	hlc "github.com/simonnyman/DISY_Projects/Synchronization/hlc"
+	lamport "github.com/simonnyman/DISY_Projects/Synchronization/lamport"
+	sparse "github.com/simonnyman/DISY_Projects/Synchronization/sparse"
+	vector "github.com/simonnyman/DISY_Projects/Synchronization/vector"
 )
 
 // Basic functionality tests
@@ -466,6 +475,442 @@ func TestProcessStatistics(t *testing.T) {
 	}
 }
 
+// verifies HLC timestamps are recorded and respect message causality.
+func TestHLCTimestamps(t *testing.T) {
+	sim := NewSimulator(2)
+
+	sim.generateLocalEvent(0)
+	sim.sendMessage(0, 1)
+	msg := <-sim.Processes[1].inbox
+	sim.receiveMessage(1, msg)
+
+	send := sim.Processes[0].Events[1].Clocks[clock.HLC].(hlc.Timestamp)
+	receive := sim.Processes[1].Events[0].Clocks[clock.HLC].(hlc.Timestamp)
+
+	if send.Wall == 0 {
+		t.Error("Send event should carry a non-zero HLC wall time")
+	}
+	if msg.Clocks[clock.HLC] != send {
+		t.Errorf("Message HLC %v should match send event %v", msg.Clocks[clock.HLC], send)
+	}
+	if !send.Before(receive) {
+		t.Errorf("Receive HLC %v should be after send HLC %v", receive, send)
+	}
+}
+
+// verifies a full simulation produces no HLC causality violations.
+func TestHLCNoViolations(t *testing.T) {
+	sim := NewSimulator(4)
+	sim.RunSimulation(100*time.Millisecond, 0.3, 0.5)
+
+	if v := sim.CountHLCViolations(); v != 0 {
+		t.Errorf("Expected 0 HLC violations, got %d", v)
+	}
+}
+
+// verifies matrix timestamps travel on messages and events.
+func TestMatrixTimestamps(t *testing.T) {
+	sim := NewSimulator(2)
+
+	sim.sendMessage(0, 1)
+	msg := <-sim.Processes[1].inbox
+	sim.receiveMessage(1, msg)
+
+	sent, ok := msg.Clocks[clock.Matrix].([][]int64)
+	if !ok || sent[0][0] != 1 {
+		t.Fatalf("Message should carry P0's matrix, got %v", msg.Clocks[clock.Matrix])
+	}
+
+	receive := sim.Processes[1].Events[0].Clocks[clock.Matrix].([][]int64)
+	if receive[1][0] != 1 || receive[1][1] != 1 {
+		t.Errorf("P1's own row should be [1 1], got %v", receive[1])
+	}
+	if receive[0][0] != 1 {
+		t.Errorf("P1 should know P0's view [1 0], got %v", receive[0])
+	}
+}
+
+// verifies events become stable only after knowledge flows back.
+func TestStableEvents(t *testing.T) {
+	sim := NewSimulator(2)
+
+	sim.generateLocalEvent(0) // P0's first event
+	sim.sendMessage(0, 1)
+	msg := <-sim.Processes[1].inbox
+	sim.receiveMessage(1, msg)
+
+	if n := len(sim.StableEvents(0)); n != 0 {
+		t.Errorf("P0 should not know of stable events yet, got %d", n)
+	}
+
+	sim.sendMessage(1, 0)
+	msg = <-sim.Processes[0].inbox
+	sim.receiveMessage(0, msg)
+
+	// P0 now knows P1 has seen its local and send events, and it has
+	// itself seen P1's receive and send events
+	stable := sim.StableEvents(0)
+	if len(stable) != 4 {
+		t.Fatalf("Expected 4 stable events, got %d", len(stable))
+	}
+
+	// P1 knows P0's two events are seen by both, but has not heard back
+	// about its own events
+	for _, e := range sim.StableEvents(1) {
+		if e.ProcessID != 0 {
+			t.Errorf("P1 should not consider its own events stable, got %+v", e)
+		}
+	}
+
+	if n := sim.CountStableEvents(); n != 4 {
+		t.Errorf("Expected 4 stable events overall, got %d", n)
+	}
+}
+
+// verifies sparse timestamps order events exactly like dense vector clocks.
+func TestSparseMatchesVector(t *testing.T) {
+	sim := NewSimulator(4)
+	sim.RunSimulation(100*time.Millisecond, 0.3, 0.5)
+
+	for i := 0; i < len(sim.Events); i++ {
+		for j := i + 1; j < len(sim.Events); j++ {
+			want := vector.CompareClocks(sim.Events[i].VectorTime, sim.Events[j].VectorTime)
+			got := sparse.Compare(sim.Events[i].Clocks[clock.Sparse].(map[string]int64),
+				sim.Events[j].Clocks[clock.Sparse].(map[string]int64))
+			if got != want {
+				t.Fatalf("Events %d and %d: sparse %v, vector %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+// verifies sparse sizes are measured from the trace.
+func TestSparseComplexity(t *testing.T) {
+	sim := NewSimulator(50)
+
+	// only P0 and P1 talk, so their clocks stay small
+	sim.sendMessage(0, 1)
+	msg := <-sim.Processes[1].inbox
+	sim.receiveMessage(1, msg)
+
+	metrics := sim.AnalyzeComplexity()
+
+	// one entry: count(1) + len(1) + "proc-0.sim.local"(16) + counter(1)
+	if metrics.SparseMessageSize != 19 {
+		t.Errorf("Expected 19 wire bytes per message, got %.1f", metrics.SparseMessageSize)
+	}
+	// map buckets outweigh a few entries, but not 50 dense slots
+	if metrics.SparseClockSize >= metrics.VectorClockSize {
+		t.Errorf("Sparse clocks (%d bytes) should be smaller than dense (%d bytes) here",
+			metrics.SparseClockSize, metrics.VectorClockSize)
+	}
+}
+
+// verifies differential transmission rebuilds the same vector clocks,
+// checked against the independently maintained sparse clocks.
+func TestDifferentialVectorsMatchSparse(t *testing.T) {
+	sim := NewSimulator(4)
+	sim.DifferentialVectors = true
+	sim.RunSimulation(100*time.Millisecond, 0.3, 0.5)
+
+	for i := 0; i < len(sim.Events); i++ {
+		for j := i + 1; j < len(sim.Events); j++ {
+			want := sparse.Compare(sim.Events[i].Clocks[clock.Sparse].(map[string]int64),
+				sim.Events[j].Clocks[clock.Sparse].(map[string]int64))
+			got := vector.CompareClocks(sim.Events[i].VectorTime, sim.Events[j].VectorTime)
+			if got != want {
+				t.Fatalf("Events %d and %d: differential vector %v, sparse %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+// verifies the measured piggyback size in both transmission modes.
+func TestVectorPiggybackSize(t *testing.T) {
+	exchange := func(sim *Simulator) {
+		for i := 0; i < 3; i++ {
+			sim.sendMessage(0, 1)
+			msg := <-sim.Processes[1].inbox
+			sim.receiveMessage(1, msg)
+		}
+	}
+
+	full := NewSimulator(5)
+	exchange(full)
+	if size := full.AnalyzeComplexity().VectorMessageSize; size != 40 {
+		t.Errorf("Full transmission should carry 8n = 40 bytes, got %.1f", size)
+	}
+
+	diff := NewSimulator(5)
+	diff.DifferentialVectors = true
+	exchange(diff)
+
+	// only P0's own entry changes between messages
+	if size := diff.AnalyzeComplexity().VectorMessageSize; size != 12 {
+		t.Errorf("Differential transmission should carry one 12-byte entry, got %.1f", size)
+	}
+	if !reflect.DeepEqual(diff.Processes[1].VectorClock.Clock(), full.Processes[1].VectorClock.Clock()) {
+		t.Errorf("Receiver clocks differ: %v vs %v",
+			diff.Processes[1].VectorClock.Clock(), full.Processes[1].VectorClock.Clock())
+	}
+}
+
+// verifies Bloom verdicts only ever add orderings to concurrent pairs.
+func TestBloomStatistics(t *testing.T) {
+	sim := NewSimulator(4)
+	sim.SetBloomFilter(8, 2)
+	sim.RunSimulation(100*time.Millisecond, 0.3, 0.5)
+
+	stats := sim.GetBloomStatistics()
+	concurrent := stats["concurrent"].(int)
+	if concurrent != sim.CountConcurrentEvents() {
+		t.Errorf("Expected %d concurrent pairs, got %d", sim.CountConcurrentEvents(), concurrent)
+	}
+	if stats["bloom_concurrent"].(int)+stats["disagreements"].(int) != concurrent {
+		t.Errorf("Every disagreement should be a concurrent pair reported as ordered: %v", stats)
+	}
+	if rate := stats["avg_false_positive"].(float64); rate < 0 || rate > 1 {
+		t.Errorf("Expected probability between 0 and 1, got %f", rate)
+	}
+}
+
+// verifies the concurrency report counts the pairs the Bloom clocks get
+// wrong, and leaves them out without a Bloom clock.
+func TestConcurrencyStatistics(t *testing.T) {
+	sim := NewSimulator(4)
+	sim.SetBloomFilter(8, 2)
+	sim.RunDiscreteEvent(200*time.Millisecond, 0.3, 0.5, 3)
+
+	stats := sim.GetConcurrencyStatistics()
+	bloomStats := sim.GetBloomStatistics()
+	if stats["concurrent_pairs"] != bloomStats["concurrent"] || stats["pairs"] != bloomStats["pairs"] {
+		t.Errorf("Expected the pairs of the Bloom statistics, got %v and %v", stats, bloomStats)
+	}
+	if stats["bloom_disagreements"] != bloomStats["disagreements"] || stats["bloom_disagreement_rate"] != bloomStats["disagreement_rate"] {
+		t.Errorf("Expected the disagreements of the Bloom statistics, got %v and %v", stats, bloomStats)
+	}
+	if stats["bloom_disagreements"].(int) == 0 {
+		t.Error("Expected an 8-cell filter to disagree")
+	}
+
+	exact := NewSimulatorWithClocks(3, 3, clock.Lamport)
+	exact.RunDiscreteEvent(100*time.Millisecond, 0.3, 0.5, 3)
+	if _, ok := exact.GetConcurrencyStatistics()["bloom_disagreements"]; ok {
+		t.Error("Expected no Bloom disagreements without a Bloom clock")
+	}
+}
+
+// verifies a large filter separates concurrent events on a small trace.
+func TestBloomLargeFilter(t *testing.T) {
+	sim := NewSimulator(3)
+	sim.SetBloomFilter(4096, 2)
+
+	sim.generateLocalEvent(0)
+	sim.generateLocalEvent(1)
+	sim.sendMessage(0, 2)
+	msg := <-sim.Processes[2].inbox
+	sim.receiveMessage(2, msg)
+
+	stats := sim.GetBloomStatistics()
+	if stats["disagreements"].(int) != 0 {
+		t.Errorf("Expected no disagreements with 4096 cells, got %d", stats["disagreements"])
+	}
+	if filter := sim.Events[0].Clocks[clock.Bloom].([]int64); stats["filter_size"].(int) != 4096 || len(filter) != 4096 {
+		t.Errorf("Expected 4096-cell filters, got %d", len(filter))
+	}
+}
+
+// verifies the Bloom filter cannot be changed once events exist.
+func TestSetBloomFilterPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic when changing the filter after events")
+		}
+	}()
+
+	sim := NewSimulator(2)
+	sim.generateLocalEvent(0)
+	sim.SetBloomFilter(64, 2)
+}
+
+// counts events seen locally, to test registering custom clocks
+type eventCounter struct{ n int64 }
+
+func (c *eventCounter) Tick() clock.Timestamp { c.n++; return c.n }
+func (c *eventCounter) Send() clock.Timestamp { c.n++; return c.n }
+func (c *eventCounter) Receive(from int, received clock.Timestamp) clock.Timestamp {
+	c.n++
+	return c.n
+}
+func (c *eventCounter) Snapshot() clock.Timestamp { return c.n }
+func (c *eventCounter) Compare(t1, t2 clock.Timestamp) vector.Ordering {
+	return vector.Concurrent
+}
+
+// verifies a simulator runs exactly the configured clocks.
+func TestNewSimulatorWithClocks(t *testing.T) {
+	sim := NewSimulatorWithClocks(2, 2, clock.HLC)
+
+	sim.generateLocalEvent(0)
+	sim.sendMessage(0, 1)
+	msg := <-sim.Processes[1].inbox
+	sim.receiveMessage(1, msg)
+
+	if len(msg.Clocks) != 1 {
+		t.Errorf("Message should carry only the HLC, got %v", msg.Clocks)
+	}
+	for _, e := range sim.Events {
+		if _, ok := e.Clocks[clock.HLC]; !ok || len(e.Clocks) != 1 {
+			t.Errorf("Event should carry only the HLC, got %v", e.Clocks)
+		}
+	}
+	if !sim.HasClock(clock.HLC) || sim.HasClock(clock.Matrix) {
+		t.Error("HasClock should report only the HLC")
+	}
+	if sim.CountStableEvents() != 0 || sim.CountITCMismatches() != 0 {
+		t.Error("Analyses of unconfigured clocks should report nothing")
+	}
+}
+
+// verifies panic on unknown or repeated clock names.
+func TestNewSimulatorWithClocksPanic(t *testing.T) {
+	tests := []struct {
+		name   string
+		clocks []string
+	}{
+		{"unknown", []string{"sundial"}},
+		{"duplicate", []string{clock.HLC, clock.HLC}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("Expected panic for clocks %v", tt.clocks)
+				}
+			}()
+			NewSimulatorWithClocks(2, 2, tt.clocks...)
+		})
+	}
+}
+
+// verifies a clock registered outside the simulator is carried on events
+// and messages without any simulator changes.
+func TestCustomClock(t *testing.T) {
+	if _, ok := clock.Lookup("test-counter"); !ok {
+		clock.Register("test-counter", func(cfg clock.Config) clock.Clock {
+			return &eventCounter{}
+		})
+	}
+	sim := NewSimulatorWithClocks(2, 3, "test-counter", clock.ITC)
+
+	sim.generateLocalEvent(0)
+	sim.sendMessage(0, 1)
+	msg := <-sim.Processes[1].inbox
+	sim.receiveMessage(1, msg)
+	child := sim.SpawnProcess(1)
+
+	if msg.Clocks["test-counter"] != int64(2) {
+		t.Errorf("Message should carry counter 2, got %v", msg.Clocks["test-counter"])
+	}
+	if got := sim.Processes[child].Events[0].Clocks["test-counter"]; got != int64(1) {
+		t.Errorf("Spawned process should start a fresh counter, got %v", got)
+	}
+	if sim.CountITCMismatches() != 0 {
+		t.Error("ITC and vector orderings should agree")
+	}
+}
+
+// verifies scalar clocks disagree with vector clocks exactly on concurrent pairs.
+func TestCountClockMismatches(t *testing.T) {
+	sim := NewSimulatorWithClocks(3, 3, clock.Lamport, clock.Sparse)
+	sim.RunSimulation(50*time.Millisecond, 0.3, 0.5)
+
+	if got, want := sim.CountClockMismatches(clock.Lamport), sim.CountConcurrentEvents(); got != want {
+		t.Errorf("Lamport should mismatch on the %d concurrent pairs, got %d", want, got)
+	}
+	if got := sim.CountClockMismatches(clock.Sparse); got != 0 {
+		t.Errorf("Sparse clocks should never mismatch, got %d", got)
+	}
+}
+
+// verifies the encoded clock run on a trace holds the encoding of the vector time.
+func TestEncodedClockMatchesTrace(t *testing.T) {
+	sim := NewSimulatorWithClocks(3, 3, clock.Encoded)
+	sim.RunSimulation(50*time.Millisecond, 0.3, 0.5)
+
+	for _, e := range sim.Events {
+		if got, want := e.Clocks[clock.Encoded].(*big.Int), encoded.Encode(e.VectorTime); got.Cmp(want) != 0 {
+			t.Fatalf("Event %v: encoded %v, expected %v", e.VectorTime, got, want)
+		}
+	}
+	if got := sim.CountClockMismatches(clock.Encoded); got != 0 {
+		t.Errorf("Encoded clocks should never mismatch, got %d", got)
+	}
+}
+
+// verifies the encoded bit length is measured from the trace.
+func TestEncodedComplexity(t *testing.T) {
+	sim := NewSimulator(4)
+	sim.generateLocalEvent(3) // [0 0 0 1] = 7
+	sim.generateLocalEvent(0) // [1 0 0 0] = 2
+
+	metrics := sim.AnalyzeComplexity()
+	if metrics.EncodedMaxBits != 3 {
+		t.Errorf("Expected 3 bits for 7, got %d", metrics.EncodedMaxBits)
+	}
+	if metrics.EncodedClockBits != 2.5 {
+		t.Errorf("Expected 2.5 bits on average, got %.1f", metrics.EncodedClockBits)
+	}
+	if metrics.EncodedSmallerRate != 1 {
+		t.Errorf("Every encoding should be smaller than 256 bits, got rate %.2f", metrics.EncodedSmallerRate)
+	}
+}
+
+// verifies the total order breaks Lamport ties by process ID.
+func TestTotalOrder(t *testing.T) {
+	sim := NewSimulator(3)
+
+	sim.generateLocalEvent(2) // 1.P2
+	sim.generateLocalEvent(0) // 1.P0
+	sim.sendMessage(2, 1)     // 2.P2
+	msg := <-sim.Processes[1].inbox
+	sim.receiveMessage(1, msg) // 3.P1
+
+	order := sim.TotalOrder()
+	expected := []lamport.Timestamp{
+		{Time: 1, ProcessID: 0},
+		{Time: 1, ProcessID: 2},
+		{Time: 2, ProcessID: 2},
+		{Time: 3, ProcessID: 1},
+	}
+	if len(order) != len(expected) {
+		t.Fatalf("Expected %d events, got %d", len(expected), len(order))
+	}
+	for i, e := range order {
+		if e.LamportTimestamp() != expected[i] {
+			t.Errorf("Position %d: expected %v, got %v", i, expected[i], e.LamportTimestamp())
+		}
+	}
+	if sim.Events[0].ProcessID != 2 {
+		t.Error("TotalOrder should not reorder the recorded events")
+	}
+}
+
+// verifies the total order is a linear extension of happened-before,
+// including the spawn/start and retire/join pairs of churn.
+func TestTotalOrderNoViolations(t *testing.T) {
+	sim := NewSimulatorWithCapacity(3, 6)
+	sim.RunSimulationWithChurn(100*time.Millisecond, 0.3, 0.5, 0.1, 0.05)
+
+	if v := sim.CountTotalOrderViolations(); v != 0 {
+		t.Errorf("Expected 0 total order violations, got %d", v)
+	}
+	if len(sim.TotalOrder()) != len(sim.Events) {
+		t.Error("Total order should contain every event")
+	}
+}
+
 // Test helper functions
 
 // verifies HappenedBefore correctly identifies causal ordering.
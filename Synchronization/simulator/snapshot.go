@@ -0,0 +1,464 @@
+package simulator
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	clock "github.com/simonnyman/DISY_Projects/Synchronization/clock"
+)
+
+// Snapshot is a global state recorded by the Chandy–Lamport algorithm: the
+// local state of every process and the messages in transit on every
+// channel between them.
+type Snapshot struct {
+	ID        int
+	Initiator int
+	Complete  bool           // every process has received a marker on every incoming channel
+	Duration  time.Duration  // true time from initiation to completion, 0 while incomplete
+	States    []LocalState   // recorded local states in process ID order
+	Channels  []ChannelState // channels into processes that recorded their state, by receiver then sender
+}
+
+// LocalState is the state a process recorded for a snapshot.
+type LocalState struct {
+	ProcessID    int
+	Events       int                        // events recorded before the state, the process's part of the cut
+	Lamport      int64                      // Lamport clock
+	Vector       []int64                    // vector clock
+	Clocks       map[string]clock.Timestamp // current timestamp of each configured clock
+	PhysicalTime int64                      // reading of the physical clock
+}
+
+// ChannelState is what a snapshot recorded on the channel from one process
+// to another: the messages received after the receiver recorded its state
+// and before the marker, in the order they were sent. while the marker is
+// still in transit it holds the messages received so far.
+type ChannelState struct {
+	From     int
+	To       int
+	Messages []Message
+}
+
+// a snapshot scheduled at a point in time
+type scheduledSnapshot struct {
+	at        time.Duration
+	initiator int
+}
+
+// schedule, snapshots and counters of the snapshot protocol
+type snapshotState struct {
+	schedule []scheduledSnapshot
+	runs     []*snapshotRun
+	markers  int
+}
+
+// one execution of the marker protocol
+type snapshotRun struct {
+	id        int
+	initiator int
+	group     []int // live processes when it started
+	started   time.Duration
+	duration  time.Duration
+	states    map[int]LocalState
+	open      map[int]map[int]bool // incoming channels each process still records
+	channels  map[[2]int][]Message
+	remaining int // processes still waiting for a marker
+}
+
+// starts a Chandy–Lamport snapshot at initiator, at the current point of a
+// real-time run or, outside runs, with markers left in the inboxes for the
+// caller to receive. the initiator records its state and sends a marker on
+// every outgoing channel; a process receiving its first marker records its
+// state, sends markers and records every other incoming channel until a
+// marker arrives on it. markers carry no timestamps and record no events.
+// the snapshot covers the processes live now; the group must stay fixed.
+// returns the snapshot ID.
+// panics if FIFOChannels is not set, if initiator is out of bounds, or if
+// it is retired or crashed.
+func (s *Simulator) InitiateSnapshot(initiator int) int {
+	s.checkSnapshots()
+	p, ok := s.lookup(initiator)
+	if !ok {
+		panic("simulator: initiator out of bounds")
+	}
+	id, ok := s.initiateSnapshot(p)
+	if !ok {
+		panic("simulator: initiator is retired or crashed")
+	}
+	return id
+}
+
+// schedules a Chandy–Lamport snapshot initiated by initiator at time at
+// after the run begins. a snapshot whose initiator is retired, crashed or
+// not yet spawned has no effect.
+// panics if FIFOChannels is not set, if at is negative or if initiator is
+// out of bounds.
+func (s *Simulator) ScheduleSnapshot(at time.Duration, initiator int) {
+	s.checkSnapshots()
+	if at < 0 {
+		panic("simulator: snapshot time must not be negative")
+	}
+	if initiator < 0 || initiator >= s.MaxProcesses {
+		panic("simulator: initiator out of bounds")
+	}
+
+	s.snapshotMu.Lock()
+	defer s.snapshotMu.Unlock()
+	s.snapshot.schedule = append(s.snapshot.schedule, scheduledSnapshot{at, initiator})
+	sort.SliceStable(s.snapshot.schedule, func(i, j int) bool {
+		return s.snapshot.schedule[i].at < s.snapshot.schedule[j].at
+	})
+}
+
+// panics unless the channels are FIFO, which the recorded channel states
+// rely on.
+func (s *Simulator) checkSnapshots() {
+	if !s.FIFOChannels {
+		panic("simulator: snapshots need FIFOChannels")
+	}
+}
+
+// reports whether snapshots were scheduled or taken.
+func (s *Simulator) snapshotsUsed() bool {
+	s.snapshotMu.Lock()
+	defer s.snapshotMu.Unlock()
+	return len(s.snapshot.schedule) > 0 || len(s.snapshot.runs) > 0
+}
+
+// starts a snapshot at p unless it is retired or crashed. returns the
+// snapshot ID and true if it started.
+func (s *Simulator) initiateSnapshot(p *Process) (int, bool) {
+	group := s.LiveProcesses()
+
+	p.mu.Lock()
+	if p.Retired() || p.Crashed() {
+		p.mu.Unlock()
+		return 0, false
+	}
+
+	s.snapshotMu.Lock()
+	run := &snapshotRun{
+		id:        len(s.snapshot.runs) + 1,
+		initiator: p.ID,
+		group:     group,
+		started:   time.Duration(s.trueTime()),
+		states:    make(map[int]LocalState),
+		open:      make(map[int]map[int]bool),
+		channels:  make(map[[2]int][]Message),
+		remaining: len(group),
+	}
+	s.snapshot.runs = append(s.snapshot.runs, run)
+	s.recordState(p, run, -1)
+	s.snapshotMu.Unlock()
+	p.mu.Unlock()
+
+	s.flush(p)
+	return run.id, true
+}
+
+// records p's state for a snapshot, sends a marker on every outgoing
+// channel and starts recording every incoming channel except the one the
+// first marker came from, or none for the initiator.
+// must be called with p.mu and s.snapshotMu held.
+func (s *Simulator) recordState(p *Process, run *snapshotRun, from int) {
+	times := make(map[string]clock.Timestamp, len(p.Clocks))
+	for name, c := range p.Clocks {
+		times[name] = c.Snapshot()
+	}
+	run.states[p.ID] = LocalState{
+		ProcessID:    p.ID,
+		Events:       len(p.Events),
+		Lamport:      p.LamportClock.Time(),
+		Vector:       p.VectorClock.Clock(),
+		Clocks:       times,
+		PhysicalTime: p.PhysicalClock.Now(),
+	}
+
+	open := make(map[int]bool)
+	for _, id := range run.group {
+		if id == p.ID {
+			continue
+		}
+		p.outbox = append(p.outbox, &Message{
+			From:      p.ID,
+			To:        id,
+			MessageID: -1,
+			LinkSeq:   p.nextLinkSeq(id),
+			Marker:    run.id,
+		})
+		s.snapshot.markers++
+		if id != from {
+			open[id] = true
+		}
+	}
+	run.open[p.ID] = open
+	s.closeChannel(p, run, -1)
+}
+
+// handles a marker: the first one of a snapshot makes p record its state,
+// later ones end the recording of their channel.
+// must be called with p.mu held.
+func (s *Simulator) receiveMarker(p *Process, msg *Message) {
+	s.snapshotMu.Lock()
+	defer s.snapshotMu.Unlock()
+
+	run := s.snapshot.runs[msg.Marker-1]
+	if _, ok := run.states[p.ID]; !ok {
+		s.recordState(p, run, msg.From)
+		return
+	}
+	s.closeChannel(p, run, msg.From)
+}
+
+// stops recording the channel from process from into p, if it is open,
+// and completes p's part of the snapshot once no channel is left open.
+// must be called with p.mu and s.snapshotMu held.
+func (s *Simulator) closeChannel(p *Process, run *snapshotRun, from int) {
+	open := run.open[p.ID]
+	if from >= 0 {
+		if !open[from] {
+			return
+		}
+		delete(open, from)
+	}
+	if len(open) > 0 {
+		return
+	}
+	run.remaining--
+	if run.remaining == 0 {
+		run.duration = time.Duration(s.trueTime()) - run.started
+	}
+}
+
+// appends a message to the state of its channel in every snapshot that is
+// recording the channel.
+// must be called with p.mu held.
+func (s *Simulator) recordInFlight(p *Process, msg *Message) {
+	s.snapshotMu.Lock()
+	defer s.snapshotMu.Unlock()
+
+	for _, run := range s.snapshot.runs {
+		if run.remaining > 0 && run.open[p.ID][msg.From] {
+			link := [2]int{msg.From, p.ID}
+			run.channels[link] = append(run.channels[link], *msg)
+		}
+	}
+}
+
+// starts the goroutine that initiates the scheduled snapshots in real time
+// until stop is closed.
+func (s *Simulator) runSnapshots(wg *sync.WaitGroup, stop <-chan bool, duration time.Duration) {
+	scheduled := s.snapshotSchedule(duration)
+	if len(scheduled) == 0 {
+		return
+	}
+
+	start := time.Now()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for _, snap := range scheduled {
+			timer := time.NewTimer(snap.at - time.Since(start))
+			select {
+			case <-stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+				s.applySnapshot(snap)
+			}
+		}
+	}()
+}
+
+// returns the scheduled snapshots that fall within duration.
+func (s *Simulator) snapshotSchedule(duration time.Duration) []scheduledSnapshot {
+	s.snapshotMu.Lock()
+	defer s.snapshotMu.Unlock()
+
+	var scheduled []scheduledSnapshot
+	for _, snap := range s.snapshot.schedule {
+		if snap.at <= duration {
+			scheduled = append(scheduled, snap)
+		}
+	}
+	return scheduled
+}
+
+// initiates a scheduled snapshot if its initiator exists.
+func (s *Simulator) applySnapshot(snap scheduledSnapshot) {
+	if p, ok := s.lookup(snap.initiator); ok {
+		s.initiateSnapshot(p)
+	}
+}
+
+// returns every snapshot taken so far, in the order they were initiated.
+func (s *Simulator) Snapshots() []Snapshot {
+	s.snapshotMu.Lock()
+	defer s.snapshotMu.Unlock()
+
+	snapshots := make([]Snapshot, 0, len(s.snapshot.runs))
+	for _, run := range s.snapshot.runs {
+		snap := Snapshot{
+			ID:        run.id,
+			Initiator: run.initiator,
+			Complete:  run.remaining == 0,
+			Duration:  run.duration,
+		}
+		for _, to := range run.group {
+			state, ok := run.states[to]
+			if !ok {
+				continue
+			}
+			snap.States = append(snap.States, state)
+			for _, from := range run.group {
+				if from != to {
+					messages := append([]Message(nil), run.channels[[2]int{from, to}]...)
+					snap.Channels = append(snap.Channels, ChannelState{From: from, To: to, Messages: messages})
+				}
+			}
+		}
+		snapshots = append(snapshots, snap)
+	}
+	return snapshots
+}
+
+// checks a complete snapshot against the recorded events. the cut is
+// consistent if no process's part of it has seen an event of another
+// process beyond that process's part, judged by the vector timestamps of
+// the last event of each part, so no receive is included without its
+// send. the channel states must hold exactly the messages sent inside the
+// cut and received outside it, for the messages that record send and
+// receive events.
+// returns an error naming the first problem, or nil.
+func (s *Simulator) VerifySnapshot(snap Snapshot) error {
+	if !snap.Complete {
+		return fmt.Errorf("simulator: snapshot %d is incomplete", snap.ID)
+	}
+
+	cut := make(map[int]int)
+	events := make(map[int][]Event)
+	boundary := make(map[int][]int64)
+	for _, state := range snap.States {
+		p, ok := s.lookup(state.ProcessID)
+		if ok {
+			p.mu.Lock()
+			events[p.ID] = p.Events
+			p.mu.Unlock()
+		}
+		if !ok || state.Events > len(events[p.ID]) {
+			return fmt.Errorf("simulator: snapshot %d records events P%d does not have", snap.ID, state.ProcessID)
+		}
+		cut[p.ID] = state.Events
+		boundary[p.ID] = make([]int64, s.MaxProcesses)
+		if state.Events > 0 {
+			boundary[p.ID] = events[p.ID][state.Events-1].VectorTime
+		}
+	}
+
+	for _, j := range snap.States {
+		for _, i := range snap.States {
+			if seen, own := boundary[j.ProcessID][i.ProcessID], boundary[i.ProcessID][i.ProcessID]; seen > own {
+				return fmt.Errorf("simulator: snapshot %d is inconsistent: P%d's cut has seen P%d at %d, beyond its cut at %d",
+					snap.ID, j.ProcessID, i.ProcessID, seen, own)
+			}
+		}
+	}
+
+	// positions of sends and receives by message and link
+	sentAt := make(map[int]int)
+	receivedAt := make(map[[3]int]int)
+	inFlight := make(map[[2]int]map[int]bool)
+	for id, trace := range events {
+		for pos, e := range trace {
+			switch e.EventType {
+			case "send", "broadcast":
+				sentAt[e.MessageID] = pos
+			case "receive", "received":
+				receivedAt[[3]int{e.TargetID, id, e.MessageID}] = pos
+			}
+		}
+	}
+	for id, trace := range events {
+		for _, e := range trace[:cut[id]] {
+			for to := range events {
+				if to == id || !(e.EventType == "broadcast" || (e.EventType == "send" && e.TargetID == to)) {
+					continue
+				}
+				if pos, ok := receivedAt[[3]int{id, to, e.MessageID}]; !ok || pos >= cut[to] {
+					link := [2]int{id, to}
+					if inFlight[link] == nil {
+						inFlight[link] = make(map[int]bool)
+					}
+					inFlight[link][e.MessageID] = true
+				}
+			}
+		}
+	}
+
+	for _, channel := range snap.Channels {
+		link := [2]int{channel.From, channel.To}
+		recorded := make(map[int]bool)
+		for _, msg := range channel.Messages {
+			pos, ok := sentAt[msg.MessageID]
+			if msg.MessageID < 0 || !ok {
+				continue
+			}
+			if pos >= cut[channel.From] {
+				return fmt.Errorf("simulator: snapshot %d: message %d on channel P%d→P%d was sent after the cut",
+					snap.ID, msg.MessageID, channel.From, channel.To)
+			}
+			recorded[msg.MessageID] = true
+		}
+		for msgID := range inFlight[link] {
+			if !recorded[msgID] {
+				return fmt.Errorf("simulator: snapshot %d: message %d in transit on channel P%d→P%d was not recorded",
+					snap.ID, msgID, channel.From, channel.To)
+			}
+		}
+	}
+	return nil
+}
+
+// returns how many snapshots were taken and completed, the markers sent,
+// the messages recorded in channel states, the true time from initiation
+// to completion, and how many complete snapshots VerifySnapshot rejects.
+func (s *Simulator) GetSnapshotStatistics() map[string]interface{} {
+	snapshots := s.Snapshots()
+	s.snapshotMu.Lock()
+	markers := s.snapshot.markers
+	s.snapshotMu.Unlock()
+
+	complete, inFlight, inconsistent := 0, 0, 0
+	var totalDuration, maxDuration time.Duration
+	for _, snap := range snapshots {
+		for _, channel := range snap.Channels {
+			inFlight += len(channel.Messages)
+		}
+		if !snap.Complete {
+			continue
+		}
+		complete++
+		totalDuration += snap.Duration
+		maxDuration = max(maxDuration, snap.Duration)
+		if s.VerifySnapshot(snap) != nil {
+			inconsistent++
+		}
+	}
+
+	avgDuration := time.Duration(0)
+	if complete > 0 {
+		avgDuration = totalDuration / time.Duration(complete)
+	}
+
+	return map[string]interface{}{
+		"snapshots":        len(snapshots),
+		"complete":         complete,
+		"markers":          markers,
+		"channel_messages": inFlight,
+		"avg_duration":     avgDuration,
+		"max_duration":     maxDuration,
+		"inconsistent":     inconsistent,
+	}
+}
@@ -0,0 +1,233 @@
+package simulator
+
+import (
+	"testing"
+	"time"
+
+	network "github.com/simonnyman/DISY_Projects/Synchronization/network"
+)
+
+// receives every message waiting in an inbox, in process order, until all
+// inboxes are empty.
+func drainInboxes(sim *Simulator) {
+	for received := true; received; {
+		received = false
+		for _, p := range sim.Processes {
+			select {
+			case msg := <-p.inbox:
+				sim.receiveMessage(p.ID, msg)
+				received = true
+			default:
+			}
+		}
+	}
+}
+
+// verifies a message received after the receiver recorded its state and
+// before the marker on its channel is recorded as in transit.
+func TestSnapshotChannelState(t *testing.T) {
+	sim := NewSimulator(3)
+	sim.FIFOChannels = true
+	sim.generateLocalEvent(1)
+	sim.sendMessage(1, 0)
+	inTransit := <-sim.Processes[0].inbox
+
+	id := sim.InitiateSnapshot(0)
+	sim.receiveMessage(0, inTransit)
+	drainInboxes(sim)
+
+	snapshots := sim.Snapshots()
+	if len(snapshots) != 1 || snapshots[0].ID != id {
+		t.Fatalf("Expected snapshot %d, got %v", id, snapshots)
+	}
+	snap := snapshots[0]
+	if !snap.Complete || len(snap.States) != 3 || len(snap.Channels) != 6 {
+		t.Fatalf("Expected a complete snapshot of 3 processes and 6 channels, got %+v", snap)
+	}
+	if state := snap.States[1]; state.Events != 2 || state.Lamport != 2 || state.Vector[1] != 2 || len(state.Clocks) != len(DefaultClocks) {
+		t.Errorf("Expected P1 to record its 2 events, got %+v", state)
+	}
+	for _, channel := range snap.Channels {
+		expected := 0
+		if channel.From == 1 && channel.To == 0 {
+			expected = 1
+		}
+		if len(channel.Messages) != expected {
+			t.Errorf("Expected %d messages on P%d→P%d, got %d", expected, channel.From, channel.To, len(channel.Messages))
+		}
+	}
+	if msgs := snap.Channels[0].Messages; len(msgs) != 1 || msgs[0].MessageID != inTransit.MessageID {
+		t.Errorf("Expected message %d on P1→P0, got %v", inTransit.MessageID, msgs)
+	}
+	if err := sim.VerifySnapshot(snap); err != nil {
+		t.Error(err)
+	}
+
+	stats := sim.GetSnapshotStatistics()
+	if stats["complete"].(int) != 1 || stats["markers"].(int) != 6 || stats["channel_messages"].(int) != 1 {
+		t.Errorf("Expected 1 snapshot, 6 markers and 1 message in transit, got %v", stats)
+	}
+}
+
+// verifies the checker rejects a cut that includes a receive without its
+// send, and channel states that miss a message or record one sent later.
+func TestSnapshotVerifyDetects(t *testing.T) {
+	sim := NewSimulator(2)
+	sim.FIFOChannels = true
+	sim.InitiateSnapshot(0)
+	sim.sendMessage(0, 1)
+	sim.sendMessage(1, 0)
+	drainInboxes(sim)
+
+	snap := sim.Snapshots()[0]
+	if err := sim.VerifySnapshot(snap); err != nil {
+		t.Fatal(err)
+	}
+
+	// P1's cut grows to include the receive of P0's message, which P0
+	// sent after its cut
+	inconsistent := snap
+	inconsistent.States = append([]LocalState(nil), snap.States...)
+	inconsistent.States[1].Events = 2
+	if sim.VerifySnapshot(inconsistent) == nil {
+		t.Error("Expected a receive without its send to be rejected")
+	}
+
+	// P0's cut grows to include its send, which P1 received after its cut
+	missing := snap
+	missing.States = append([]LocalState(nil), snap.States...)
+	missing.States[0].Events = 1
+	if sim.VerifySnapshot(missing) == nil {
+		t.Error("Expected a message in transit missing from the channel state to be rejected")
+	}
+
+	// P0's message to P1 recorded in transit although sent after P0's cut
+	sent := snap
+	sent.Channels = append([]ChannelState(nil), snap.Channels...)
+	for i, channel := range sent.Channels {
+		if channel.From == 0 {
+			sent.Channels[i].Messages = []Message{{MessageID: 0}}
+		}
+	}
+	if sim.VerifySnapshot(sent) == nil {
+		t.Error("Expected a message sent after the cut to be rejected")
+	}
+
+	snap.Complete = false
+	if sim.VerifySnapshot(snap) == nil {
+		t.Error("Expected an incomplete snapshot to be rejected")
+	}
+}
+
+// verifies scheduled snapshots from several initiators over a network that
+// reorders messages, while mutual exclusion adds protocol traffic: every
+// snapshot completes with a consistent cut and the messages in transit.
+func TestSnapshotDiscrete(t *testing.T) {
+	sim := NewSimulator(5)
+	sim.FIFOChannels = true
+	sim.SetMutualExclusion(MutexRicartAgrawala, 0.2, 10*time.Millisecond)
+	sim.SetNetwork(network.NewModel(network.Link{
+		Latency:      network.Exponential(3 * time.Millisecond),
+		Reorder:      0.2,
+		ReorderDelay: 10 * time.Millisecond,
+	}, 6))
+	for i := 0; i < 5; i++ {
+		sim.ScheduleSnapshot(time.Duration(50+40*i)*time.Millisecond, i)
+	}
+	sim.ScheduleSnapshot(120*time.Millisecond, 3) // overlaps the one from P1
+	sim.RunDiscreteEvent(400*time.Millisecond, 0.3, 0.5, 6)
+
+	stats := sim.GetSnapshotStatistics()
+	if stats["snapshots"].(int) != 6 || stats["complete"].(int) != 6 || stats["inconsistent"].(int) != 0 {
+		t.Errorf("Expected 6 complete, consistent snapshots, got %v", stats)
+	}
+	if stats["markers"].(int) != 6*20 || stats["channel_messages"].(int) == 0 {
+		t.Errorf("Expected 20 markers per snapshot and messages in transit, got %v", stats)
+	}
+	if stats["avg_duration"].(time.Duration) <= 0 {
+		t.Errorf("Expected markers to take time, got %v", stats["avg_duration"])
+	}
+	for _, snap := range sim.Snapshots() {
+		if err := sim.VerifySnapshot(snap); err != nil {
+			t.Error(err)
+		}
+	}
+	if stats := sim.GetMutexStatistics(); stats["violations"].(int) != 0 {
+		t.Errorf("Markers should not disturb mutual exclusion, got %v", stats)
+	}
+}
+
+// verifies a snapshot started while a process is crashed completes once it
+// recovers and receives the markers that waited.
+func TestSnapshotCrash(t *testing.T) {
+	sim := NewSimulator(3)
+	sim.FIFOChannels = true
+	sim.SetDurability(DurabilityWAL, 0)
+	sim.ScheduleCrash(40*time.Millisecond, 2)
+	sim.ScheduleSnapshot(60*time.Millisecond, 0)
+	sim.ScheduleRecovery(150*time.Millisecond, 2)
+	sim.RunDiscreteEvent(300*time.Millisecond, 0.3, 0.5, 3)
+
+	snap := sim.Snapshots()[0]
+	if !snap.Complete || snap.Duration < 90*time.Millisecond {
+		t.Errorf("Expected the snapshot to complete after the recovery, got %+v", snap)
+	}
+	if err := sim.VerifySnapshot(snap); err != nil {
+		t.Error(err)
+	}
+}
+
+// verifies snapshots taken during a real-time run.
+func TestSnapshotRealTime(t *testing.T) {
+	sim := NewSimulator(4)
+	sim.FIFOChannels = true
+	sim.SetNetwork(network.NewModel(network.Link{Latency: network.Uniform(0, 4*time.Millisecond)}, 1))
+	sim.ScheduleSnapshot(30*time.Millisecond, 0)
+	sim.ScheduleSnapshot(60*time.Millisecond, 2)
+	sim.RunSimulation(150*time.Millisecond, 0.2, 0.6)
+
+	stats := sim.GetSnapshotStatistics()
+	if stats["complete"].(int) != 2 || stats["inconsistent"].(int) != 0 {
+		t.Errorf("Expected 2 complete, consistent snapshots, got %v", stats)
+	}
+}
+
+// verifies invalid snapshots and churn with snapshots panic.
+func TestSnapshotPanics(t *testing.T) {
+	fifoSimulator := func() *Simulator {
+		sim := NewSimulatorWithCapacity(2, 4)
+		sim.FIFOChannels = true
+		return sim
+	}
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"no FIFO channels", func() { NewSimulator(2).InitiateSnapshot(0) }},
+		{"no FIFO channels scheduled", func() { NewSimulator(2).ScheduleSnapshot(0, 0) }},
+		{"initiator out of bounds", func() { fifoSimulator().InitiateSnapshot(2) }},
+		{"scheduled out of bounds", func() { fifoSimulator().ScheduleSnapshot(0, 4) }},
+		{"negative time", func() { fifoSimulator().ScheduleSnapshot(-1, 0) }},
+		{"crashed initiator", func() {
+			sim := fifoSimulator()
+			sim.CrashProcess(0)
+			sim.InitiateSnapshot(0)
+		}},
+		{"spawn", func() {
+			sim := fifoSimulator()
+			sim.ScheduleSnapshot(0, 0)
+			sim.SpawnProcess(0)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic")
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
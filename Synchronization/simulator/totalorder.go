@@ -0,0 +1,328 @@
+package simulator
+
+import (
+	"fmt"
+	"time"
+
+	totalorder "github.com/simonnyman/DISY_Projects/Synchronization/totalorder"
+)
+
+// total-order multicast algorithms
+const (
+	TotalOrderNone    = ""        // sends are point-to-point messages
+	TotalOrderLamport = "lamport" // Lamport timestamps and acknowledgements
+	TotalOrderISIS    = "isis"    // ISIS agreed priorities
+)
+
+// counters of total-order multicast
+type totalOrderStats struct {
+	data         int // data packets sent, one per multicast and receiver
+	control      int // acknowledgements, proposals and agreements sent
+	deliveries   int
+	totalLatency time.Duration // time from multicast to delivery, summed
+	maxLatency   time.Duration
+	violation    error // result of the agreement check at the end of the last run
+}
+
+// a multicast travelling inside the protocol's data packets: the message
+// whose timestamps take effect at delivery, and when it was multicast
+type orderedMulticast struct {
+	msg  *Message
+	sent time.Duration
+}
+
+// turns every send into a multicast to the whole process group, including
+// the sender, delivered in the same order everywhere by the given
+// algorithm (TotalOrderLamport or TotalOrderISIS), or back into
+// point-to-point messages (TotalOrderNone). the sender records a
+// "multicast" event and every process a "delivered" event in the agreed
+// order; acknowledgements, proposals and agreements record no events, like
+// clock synchronization messages. the Lamport algorithm needs FIFO links,
+// and both block while a process is crashed or packets are lost. the
+// process group must stay fixed, and the algorithm takes precedence over
+// CausalDelivery. at the end of every run the simulator checks that all
+// processes delivered the same sequence; see GetTotalOrderStatistics.
+// set it before running the simulation.
+// panics if the algorithm is unknown.
+func (s *Simulator) SetTotalOrderMulticast(algorithm string) {
+	switch algorithm {
+	case TotalOrderNone, TotalOrderLamport, TotalOrderISIS:
+	default:
+		panic("simulator: unknown total-order algorithm " + algorithm)
+	}
+	s.totalOrder = algorithm
+}
+
+// returns the protocol instance of p, creating it on first use.
+// must be called with p.mu held.
+func (s *Simulator) orderProtocol(p *Process) totalorder.Protocol {
+	if p.orderer == nil {
+		s.procMu.RLock()
+		n := s.NumProcesses
+		s.procMu.RUnlock()
+		if s.totalOrder == TotalOrderISIS {
+			p.orderer = totalorder.NewISIS(p.ID, n)
+		} else {
+			p.orderer = totalorder.NewLamport(p.ID, n)
+		}
+	}
+	return p.orderer
+}
+
+// multicasts a message from one process to the whole group.
+// retired and crashed senders do nothing.
+// panics if fromID is out of bounds.
+func (s *Simulator) multicast(fromID int) {
+	sender, ok := s.lookup(fromID)
+	if !ok {
+		panic("simulator: fromID out of bounds")
+	}
+
+	sender.mu.Lock()
+	if sender.Retired() || sender.Crashed() {
+		sender.mu.Unlock()
+		return
+	}
+	lt := sender.LamportClock.Send()
+	vt := sender.VectorClock.Send()
+	times := sender.sendClocks()
+	msgID := s.nextMessageID()
+
+	e := Event{
+		ProcessID:    fromID,
+		EventType:    "multicast",
+		Timestamp:    lt,
+		VectorTime:   vt,
+		Clocks:       times,
+		PhysicalTime: sender.PhysicalClock.Now(),
+		TargetID:     -1,
+		MessageID:    msgID,
+	}
+	s.recordEvent(sender, e)
+
+	msg := &Message{
+		From:        fromID,
+		LamportTime: lt,
+		VectorTime:  vt,
+		Clocks:      times,
+		Replica:     sender.Replica,
+		MessageID:   msgID,
+	}
+	packets, ready := s.orderProtocol(sender).Multicast(orderedMulticast{msg, time.Duration(s.trueTime())})
+	s.post(sender, packets)
+	s.deliverOrdered(sender, ready)
+	sender.mu.Unlock()
+
+	s.flush(sender)
+}
+
+// hands a protocol packet to p's protocol instance and delivers the
+// multicasts it releases. replies are sent by the caller after unlocking.
+// must be called with p.mu held.
+func (s *Simulator) receivePacket(p *Process, msg *Message) {
+	packets, ready := s.orderProtocol(p).Receive(*msg.Packet)
+	s.post(p, packets)
+	s.deliverOrdered(p, ready)
+}
+
+// queues protocol packets in p's outbox. data packets carry the
+// multicast's message ID so partitions count them; control packets do not.
+// must be called with p.mu held.
+func (s *Simulator) post(p *Process, packets []totalorder.Packet) {
+	data, control := 0, 0
+	for i := range packets {
+		pkt := &packets[i]
+		msg := &Message{From: pkt.From, To: pkt.To, MessageID: -1, Packet: pkt, LinkSeq: p.nextLinkSeq(pkt.To)}
+		if pkt.Kind == totalorder.Data {
+			om := pkt.Payload.(orderedMulticast)
+			msg.MessageID = om.msg.MessageID
+			s.recordPiggyback(8 * len(om.msg.VectorTime))
+			data++
+		} else {
+			control++
+		}
+		p.outbox = append(p.outbox, msg)
+	}
+
+	s.orderMu.Lock()
+	defer s.orderMu.Unlock()
+	s.order.data += data
+	s.order.control += control
+}
+
+// records a "delivered" event at p for each multicast in the agreed order.
+// delivering an own multicast is a local event; others merge the sender's
+// timestamps.
+// must be called with p.mu held.
+func (s *Simulator) deliverOrdered(p *Process, ready []totalorder.Delivery) {
+	if len(ready) == 0 {
+		return
+	}
+	now := time.Duration(s.trueTime())
+
+	var totalLatency, maxLatency time.Duration
+	for _, d := range ready {
+		om := d.Payload.(orderedMulticast)
+		if om.msg.From == p.ID {
+			e := Event{
+				ProcessID:    p.ID,
+				EventType:    "delivered",
+				Timestamp:    p.LamportClock.Tick(),
+				VectorTime:   p.VectorClock.Tick(),
+				Clocks:       p.tickClocks(),
+				PhysicalTime: p.PhysicalClock.Now(),
+				TargetID:     p.ID,
+				MessageID:    om.msg.MessageID,
+			}
+			s.recordEvent(p, e)
+		} else {
+			s.receiveLocked(p, om.msg, "delivered")
+		}
+		latency := now - om.sent
+		totalLatency += latency
+		maxLatency = max(maxLatency, latency)
+	}
+
+	s.orderMu.Lock()
+	defer s.orderMu.Unlock()
+	s.order.deliveries += len(ready)
+	s.order.totalLatency += totalLatency
+	s.order.maxLatency = max(s.order.maxLatency, maxLatency)
+}
+
+// sends the packets waiting in p's outbox. during a real-time run p's
+// sender goroutine sends them, so receiver goroutines never block on each
+// other's full inboxes; otherwise they are sent right away. either way
+// they leave in the order the protocol produced them.
+func (s *Simulator) flush(p *Process) {
+	if s.halt != nil {
+		select {
+		case p.wake <- struct{}{}:
+		default:
+		}
+		return
+	}
+	s.drainOutbox(p)
+}
+
+// sends every packet in p's outbox, blocking on full inboxes.
+func (s *Simulator) drainOutbox(p *Process) {
+	p.mu.Lock()
+	outbox := p.outbox
+	p.outbox = nil
+	p.mu.Unlock()
+
+	for _, msg := range outbox {
+		receiver, _ := s.lookup(msg.To)
+		s.deliver(receiver, msg, true)
+	}
+}
+
+// returns the multicasts each process delivered, in delivery order.
+func (s *Simulator) orderedDeliveries() map[int][]int {
+	multicasts := make(map[int]bool)
+	sequences := make(map[int][]int)
+	for _, e := range s.Events {
+		switch e.EventType {
+		case "multicast":
+			multicasts[e.MessageID] = true
+		case "delivered":
+			if multicasts[e.MessageID] {
+				sequences[e.ProcessID] = append(sequences[e.ProcessID], e.MessageID)
+			}
+		}
+	}
+	return sequences
+}
+
+// checks that every process delivered the same sequence of multicasts.
+// processes that fell behind, because they crashed or the run stopped
+// with packets in flight, must have delivered a prefix of it.
+// returns an error naming the first disagreement, or nil.
+func (s *Simulator) VerifyTotalOrder() error {
+	sequences := s.orderedDeliveries()
+
+	longest := -1
+	for id, seq := range sequences {
+		if longest < 0 || len(seq) > len(sequences[longest]) || (len(seq) == len(sequences[longest]) && id < longest) {
+			longest = id
+		}
+	}
+
+	for id := 0; id < s.MaxProcesses; id++ {
+		for i, msgID := range sequences[id] {
+			if expected := sequences[longest][i]; msgID != expected {
+				return fmt.Errorf("simulator: P%d delivered multicast %d at position %d, P%d delivered %d",
+					id, msgID, i, longest, expected)
+			}
+		}
+	}
+	return nil
+}
+
+// runs the agreement check at the end of a run.
+func (s *Simulator) checkTotalOrder() {
+	if s.totalOrder == TotalOrderNone {
+		return
+	}
+	err := s.VerifyTotalOrder()
+	s.orderMu.Lock()
+	defer s.orderMu.Unlock()
+	s.order.violation = err
+}
+
+// returns the algorithm, multicast and delivery counts, the data and
+// control packets sent and control packets per multicast, the multicasts
+// still queued across processes, the latency from multicast to delivery,
+// and whether all processes agreed on the delivery order at the end of the
+// last run, with the first disagreement if not.
+func (s *Simulator) GetTotalOrderStatistics() map[string]interface{} {
+	s.orderMu.Lock()
+	st := s.order
+	s.orderMu.Unlock()
+
+	multicasts := 0
+	for _, e := range s.Events {
+		if e.EventType == "multicast" {
+			multicasts++
+		}
+	}
+
+	pending := 0
+	s.procMu.RLock()
+	for _, p := range s.Processes {
+		p.mu.Lock()
+		if p.orderer != nil {
+			pending += p.orderer.Pending()
+		}
+		p.mu.Unlock()
+	}
+	s.procMu.RUnlock()
+
+	perMulticast := 0.0
+	if multicasts > 0 {
+		perMulticast = float64(st.control) / float64(multicasts)
+	}
+	avgLatency := time.Duration(0)
+	if st.deliveries > 0 {
+		avgLatency = st.totalLatency / time.Duration(st.deliveries)
+	}
+	violation := ""
+	if st.violation != nil {
+		violation = st.violation.Error()
+	}
+
+	return map[string]interface{}{
+		"algorithm":             s.totalOrder,
+		"multicasts":            multicasts,
+		"deliveries":            st.deliveries,
+		"data_packets":          st.data,
+		"control_packets":       st.control,
+		"control_per_multicast": perMulticast,
+		"pending":               pending,
+		"avg_latency":           avgLatency,
+		"max_latency":           st.maxLatency,
+		"agreement":             st.violation == nil,
+		"violation":             violation,
+	}
+}
@@ -0,0 +1,156 @@
+package simulator
+
+import (
+	"testing"
+	"time"
+
+	network "github.com/simonnyman/DISY_Projects/Synchronization/network"
+)
+
+// verifies both algorithms deliver every multicast to every process in the
+// same order, with the expected control traffic per multicast.
+func TestTotalOrderDiscrete(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		control   float64 // control packets per multicast in a group of 5
+	}{
+		{TotalOrderLamport, 16}, // 4 receivers acknowledge to 4 processes each
+		{TotalOrderISIS, 8},     // 4 proposals and 4 agreements
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			sim := NewSimulator(5)
+			sim.SetTotalOrderMulticast(tt.algorithm)
+			sim.SetNetwork(network.NewModel(network.Link{Latency: network.Exponential(2 * time.Millisecond)}, 4))
+			sim.RunDiscreteEvent(300*time.Millisecond, 0.2, 0.5, 9)
+
+			stats := sim.GetTotalOrderStatistics()
+			multicasts := stats["multicasts"].(int)
+			if multicasts == 0 {
+				t.Fatal("Expected multicasts")
+			}
+			if !stats["agreement"].(bool) || sim.VerifyTotalOrder() != nil {
+				t.Errorf("Processes disagree: %v", stats["violation"])
+			}
+			if stats["deliveries"].(int) != 5*multicasts || stats["pending"].(int) != 0 {
+				t.Errorf("Expected %d deliveries and none pending, got %v", 5*multicasts, stats)
+			}
+			if got := stats["control_per_multicast"].(float64); got != tt.control {
+				t.Errorf("Expected %.0f control packets per multicast, got %.2f", tt.control, got)
+			}
+			if missed, spurious := sim.CountCausalityViolations(); missed+spurious != 0 {
+				t.Errorf("Deliveries should respect causality, got %d missed and %d spurious", missed, spurious)
+			}
+		})
+	}
+}
+
+// verifies ISIS agrees even when links reorder packets.
+func TestTotalOrderISISReordering(t *testing.T) {
+	sim := NewSimulator(4)
+	sim.SetTotalOrderMulticast(TotalOrderISIS)
+	sim.SetNetwork(network.NewModel(network.Link{
+		Latency:      network.Constant(time.Millisecond),
+		Reorder:      0.3,
+		ReorderDelay: 5 * time.Millisecond,
+	}, 2))
+	sim.RunDiscreteEvent(300*time.Millisecond, 0.2, 0.5, 4)
+
+	stats := sim.GetTotalOrderStatistics()
+	if !stats["agreement"].(bool) || stats["pending"].(int) != 0 {
+		t.Errorf("Expected agreement with nothing pending, got %v", stats)
+	}
+}
+
+// verifies a crashed process catches up on recovery from the packets held
+// for it.
+func TestTotalOrderCrash(t *testing.T) {
+	sim := NewSimulator(4)
+	sim.SetTotalOrderMulticast(TotalOrderLamport)
+	sim.SetDurability(DurabilityWAL, 0)
+	sim.ScheduleCrash(100*time.Millisecond, 2)
+	sim.ScheduleRecovery(120*time.Millisecond, 2)
+	sim.RunDiscreteEvent(300*time.Millisecond, 0.2, 0.3, 6)
+
+	stats := sim.GetTotalOrderStatistics()
+	if !stats["agreement"].(bool) || stats["deliveries"].(int) != 4*stats["multicasts"].(int) {
+		t.Errorf("Expected every process to deliver every multicast, got %v", stats)
+	}
+}
+
+// verifies the check catches processes delivering in different orders.
+func TestVerifyTotalOrderDetectsDisagreement(t *testing.T) {
+	sim := NewSimulator(3)
+	sim.SetTotalOrderMulticast(TotalOrderLamport)
+	sim.multicast(0)
+	sim.multicast(1)
+	for pending := true; pending; {
+		pending = false
+		for _, p := range sim.Processes {
+			for len(p.inbox) > 0 {
+				sim.receiveMessage(p.ID, <-p.inbox)
+				pending = true
+			}
+		}
+	}
+	if err := sim.VerifyTotalOrder(); err != nil {
+		t.Fatalf("Expected agreement, got %v", err)
+	}
+
+	// swap the two deliveries of process 2
+	var swapped []int
+	for i, e := range sim.Events {
+		if e.EventType == "delivered" && e.ProcessID == 2 {
+			swapped = append(swapped, i)
+		}
+	}
+	a, b := swapped[0], swapped[1]
+	sim.Events[a].MessageID, sim.Events[b].MessageID = sim.Events[b].MessageID, sim.Events[a].MessageID
+	if sim.VerifyTotalOrder() == nil {
+		t.Error("Expected the swapped deliveries to be reported")
+	}
+}
+
+// verifies the agreement check runs at the end of a real-time run.
+func TestTotalOrderRealTime(t *testing.T) {
+	for _, algorithm := range []string{TotalOrderLamport, TotalOrderISIS} {
+		sim := NewSimulator(4)
+		sim.SetTotalOrderMulticast(algorithm)
+		sim.RunSimulation(150*time.Millisecond, 0.2, 0.5)
+
+		stats := sim.GetTotalOrderStatistics()
+		if stats["deliveries"].(int) == 0 || !stats["agreement"].(bool) {
+			t.Errorf("%s: expected deliveries in agreement, got %v", algorithm, stats)
+		}
+	}
+}
+
+// verifies unknown algorithms and churn with total-order multicast panic.
+func TestTotalOrderPanics(t *testing.T) {
+	withTotalOrder := func() *Simulator {
+		sim := NewSimulatorWithCapacity(2, 4)
+		sim.SetTotalOrderMulticast(TotalOrderISIS)
+		return sim
+	}
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"unknown algorithm", func() { NewSimulator(2).SetTotalOrderMulticast("sequencer") }},
+		{"spawn", func() { withTotalOrder().SpawnProcess(0) }},
+		{"retire", func() { withTotalOrder().RetireProcess(0, 1) }},
+		{"churn", func() { withTotalOrder().RunSimulationWithChurn(time.Millisecond, 0.1, 0.1, 0.1, 0) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic")
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
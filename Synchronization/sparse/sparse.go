@@ -0,0 +1,194 @@
+package sparse
+
+import (
+	"encoding/binary"
+	"runtime"
+	"strings"
+	"sync"
+
+	vector "github.com/simonnyman/DISY_Projects/Synchronization/vector"
+)
+
+// sparse vector clock
+// keyed by arbitrary comparable process IDs such as hostnames or UUIDs.
+// missing entries count as zero, so only processes that have actually
+// been heard of take up space.
+// thread-safe for concurrent use.
+type Vector[K comparable] struct {
+	id    K
+	clock map[K]int64
+	mu    sync.RWMutex
+}
+
+// creates a new sparse Vector clock for the process with the given ID.
+func NewVector[K comparable](id K) *Vector[K] {
+	return &Vector[K]{
+		id:    id,
+		clock: make(map[K]int64),
+	}
+}
+
+// increments the clock for a local event.
+func (v *Vector[K]) Tick() map[K]int64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.clock[v.id]++
+	return Copy(v.clock)
+}
+
+// increments the clock and returns timestamp for outgoing message.
+func (v *Vector[K]) Send() map[K]int64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.clock[v.id]++
+	return Copy(v.clock)
+}
+
+// updates the clock based on received timestamp.
+// merges by taking entry-wise max, then increments own counter.
+func (v *Vector[K]) Receive(receivedClock map[K]int64) map[K]int64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.merge(receivedClock)
+	v.clock[v.id]++
+	return Copy(v.clock)
+}
+
+// merges a received timestamp without recording an event.
+func (v *Vector[K]) Merge(receivedClock map[K]int64) map[K]int64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.merge(receivedClock)
+	return Copy(v.clock)
+}
+
+// returns a copy of the current clock.
+func (v *Vector[K]) Clock() map[K]int64 {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return Copy(v.clock)
+}
+
+// returns the number of stored entries.
+func (v *Vector[K]) Len() int {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return len(v.clock)
+}
+
+// removes entries whose counter is zero and returns how many were removed.
+func (v *Vector[K]) Prune() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	removed := 0
+	for k, n := range v.clock {
+		if n == 0 {
+			delete(v.clock, k)
+			removed++
+		}
+	}
+	return removed
+}
+
+// removes all entries.
+func (v *Vector[K]) Reset() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.clock = make(map[K]int64)
+}
+
+// takes the entry-wise max with a received timestamp.
+// must be called with lock held.
+func (v *Vector[K]) merge(receivedClock map[K]int64) {
+	for k, n := range receivedClock {
+		if n > v.clock[k] {
+			v.clock[k] = n
+		}
+	}
+}
+
+// creates a copy of a sparse timestamp, leaving out zero entries.
+func Copy[K comparable](clock map[K]int64) map[K]int64 {
+	clockCopy := make(map[K]int64, len(clock))
+	for k, n := range clock {
+		if n != 0 {
+			clockCopy[k] = n
+		}
+	}
+	return clockCopy
+}
+
+// determines the causal relationship between two sparse timestamps.
+// missing entries count as zero, so timestamps of any size can be compared.
+func Compare[K comparable](v1, v2 map[K]int64) vector.Ordering {
+	hasLess := false
+	hasGreater := false
+
+	for k, n := range v1 {
+		if n < v2[k] {
+			hasLess = true
+		} else if n > v2[k] {
+			hasGreater = true
+		}
+	}
+	for k, n := range v2 {
+		if _, ok := v1[k]; !ok && n > 0 {
+			hasLess = true
+		}
+	}
+
+	switch {
+	case !hasLess && !hasGreater:
+		return vector.Equal
+	case hasLess && !hasGreater:
+		return vector.Before
+	case hasGreater && !hasLess:
+		return vector.After
+	default:
+		return vector.Concurrent
+	}
+}
+
+// returns the wire size in bytes of a sparse timestamp encoded as
+// an entry count followed by (key, counter) pairs with varint counters.
+// keySize returns the encoded size of a single key.
+func EncodedSize[K comparable](clock map[K]int64, keySize func(K) int) int {
+	size := uvarintSize(uint64(len(clock)))
+	for k, n := range clock {
+		size += keySize(k) + uvarintSize(uint64(n))
+	}
+	return size
+}
+
+// returns the encoded size of a string key: a varint length and the bytes.
+func StringKeySize(k string) int {
+	return uvarintSize(uint64(len(k))) + len(k)
+}
+
+// returns the heap bytes the runtime allocates to hold copies of the
+// string-keyed sparse timestamps, map buckets and key bytes included.
+// measured with runtime.ReadMemStats, so allocations by other goroutines
+// during the call are counted as well.
+func MeasureMemory(clocks []map[string]int64) int {
+	copies := make([]map[string]int64, len(clocks))
+	var before, after runtime.MemStats
+
+	runtime.ReadMemStats(&before)
+	for i, clock := range clocks {
+		copies[i] = make(map[string]int64, len(clock))
+		for k, n := range clock {
+			copies[i][strings.Clone(k)] = n
+		}
+	}
+	runtime.ReadMemStats(&after)
+
+	runtime.KeepAlive(copies)
+	return int(after.TotalAlloc - before.TotalAlloc)
+}
+
+// returns the number of bytes needed to varint-encode x.
+func uvarintSize(x uint64) int {
+	var buf [binary.MaxVarintLen64]byte
+	return binary.PutUvarint(buf[:], x)
+}
@@ -0,0 +1,213 @@
+package sparse
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	vector "github.com/simonnyman/DISY_Projects/Synchronization/vector"
+)
+
+// Basic functionality tests
+
+// creates a new sparse Vector clock and verifies it has no entries.
+func TestNewVector(t *testing.T) {
+	v := NewVector("node-a")
+
+	if v.Len() != 0 {
+		t.Errorf("Expected no entries, got %v", v.Clock())
+	}
+}
+
+// verifies tick creates and increments only the own entry.
+func TestSparseTick(t *testing.T) {
+	v := NewVector("node-a")
+
+	v.Tick()
+	clock := v.Tick()
+	expected := map[string]int64{"node-a": 2}
+
+	if !reflect.DeepEqual(clock, expected) {
+		t.Errorf("Expected %v, got %v", expected, clock)
+	}
+}
+
+// verifies receive merges entry-wise and increments own counter.
+func TestSparseReceive(t *testing.T) {
+	v := NewVector("node-b")
+	v.Tick()
+
+	clock := v.Receive(map[string]int64{"node-a": 3, "node-c": 1})
+	expected := map[string]int64{"node-a": 3, "node-b": 2, "node-c": 1}
+
+	if !reflect.DeepEqual(clock, expected) {
+		t.Errorf("Expected %v, got %v", expected, clock)
+	}
+}
+
+// verifies merge does not record an event.
+func TestSparseMerge(t *testing.T) {
+	v := NewVector(7)
+
+	clock := v.Merge(map[int]int64{3: 4})
+	expected := map[int]int64{3: 4}
+
+	if !reflect.DeepEqual(clock, expected) {
+		t.Errorf("Expected %v, got %v", expected, clock)
+	}
+}
+
+// verifies prune removes zero entries received from other clocks.
+func TestSparsePrune(t *testing.T) {
+	v := NewVector("node-a")
+	v.Tick()
+	v.clock["node-b"] = 0
+	v.clock["node-c"] = 0
+
+	if removed := v.Prune(); removed != 2 {
+		t.Errorf("Expected 2 entries pruned, got %d", removed)
+	}
+	if v.Len() != 1 {
+		t.Errorf("Expected 1 entry after prune, got %d", v.Len())
+	}
+}
+
+// verifies returned timestamps leave out zero entries.
+func TestCopyDropsZeros(t *testing.T) {
+	clock := Copy(map[string]int64{"a": 0, "b": 2})
+	expected := map[string]int64{"b": 2}
+
+	if !reflect.DeepEqual(clock, expected) {
+		t.Errorf("Expected %v, got %v", expected, clock)
+	}
+}
+
+// verifies reset removes all entries.
+func TestSparseReset(t *testing.T) {
+	v := NewVector("node-a")
+	v.Receive(map[string]int64{"node-b": 4})
+
+	v.Reset()
+	if v.Len() != 0 {
+		t.Errorf("Expected no entries after reset, got %v", v.Clock())
+	}
+}
+
+// verifies comparison treats missing entries as zero.
+func TestSparseCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		v1, v2   map[string]int64
+		expected vector.Ordering
+	}{
+		{"equal", map[string]int64{"a": 1}, map[string]int64{"a": 1}, vector.Equal},
+		{"equal with zero entry", map[string]int64{"a": 1, "b": 0}, map[string]int64{"a": 1}, vector.Equal},
+		{"both empty", map[string]int64{}, nil, vector.Equal},
+		{"before", map[string]int64{"a": 1}, map[string]int64{"a": 1, "b": 1}, vector.Before},
+		{"after", map[string]int64{"a": 2, "b": 1}, map[string]int64{"a": 1}, vector.After},
+		{"concurrent", map[string]int64{"a": 1}, map[string]int64{"b": 1}, vector.Concurrent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Compare(tt.v1, tt.v2); got != tt.expected {
+				t.Errorf("Compare(%v, %v) = %v, expected %v", tt.v1, tt.v2, got, tt.expected)
+			}
+		})
+	}
+}
+
+// verifies the encoded size counts keys and varint counters.
+func TestEncodedSize(t *testing.T) {
+	clock := map[string]int64{"abc": 1, "de": 300}
+
+	// count(1) + [len(1) + "abc"(3) + 1(1)] + [len(1) + "de"(2) + 300(2)]
+	if got := EncodedSize(clock, StringKeySize); got != 11 {
+		t.Errorf("Expected 11 bytes, got %d", got)
+	}
+	if got := EncodedSize(map[string]int64{}, StringKeySize); got != 1 {
+		t.Errorf("Empty clock should encode to 1 byte, got %d", got)
+	}
+}
+
+// verifies the measured memory covers at least the keys and counters,
+// and grows with the number of entries.
+func TestMeasureMemory(t *testing.T) {
+	small := map[string]int64{"proc-0.sim.local": 1}
+	large := make(map[string]int64)
+	for i := 0; i < 100; i++ {
+		large[fmt.Sprintf("proc-%d.sim.local", i)] = int64(i + 1)
+	}
+
+	one := MeasureMemory([]map[string]int64{small})
+	if one < len("proc-0.sim.local")+8 {
+		t.Errorf("Expected at least the key and counter bytes, got %d", one)
+	}
+	if many := MeasureMemory([]map[string]int64{large}); many <= 100*(len("proc-0.sim.local")+8) {
+		t.Errorf("Expected more than the raw bytes of 100 entries, got %d", many)
+	}
+}
+
+// Scenario-based tests
+
+// verifies sparse clocks agree with dense vector clocks on a message chain.
+func TestSparseMatchesDense(t *testing.T) {
+	names := []string{"alpha", "beta", "gamma"}
+	sparseClocks := make([]*Vector[string], 3)
+	denseClocks := make([]*vector.Vector, 3)
+	for i := range names {
+		sparseClocks[i] = NewVector(names[i])
+		denseClocks[i] = vector.NewVector(i, 3)
+	}
+
+	type sample struct {
+		sparse map[string]int64
+		dense  []int64
+	}
+	samples := []sample{
+		{sparseClocks[0].Tick(), denseClocks[0].Tick()},
+		{sparseClocks[2].Tick(), denseClocks[2].Tick()},
+	}
+	s, d := sparseClocks[0].Send(), denseClocks[0].Send()
+	samples = append(samples, sample{s, d})
+	samples = append(samples, sample{sparseClocks[1].Receive(s), denseClocks[1].Receive(d)})
+	s, d = sparseClocks[1].Send(), denseClocks[1].Send()
+	samples = append(samples, sample{s, d})
+	samples = append(samples, sample{sparseClocks[2].Receive(s), denseClocks[2].Receive(d)})
+
+	for i := range samples {
+		for j := range samples {
+			got := Compare(samples[i].sparse, samples[j].sparse)
+			want := vector.CompareClocks(samples[i].dense, samples[j].dense)
+			if got != want {
+				t.Errorf("Sample %d vs %d: sparse %v, dense %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+// verifies thread-safety with mixed concurrent operations.
+func TestSparseConcurrentSendReceive(t *testing.T) {
+	v := NewVector("node-a")
+	done := make(chan bool)
+	operations := 100
+
+	for i := 0; i < operations; i++ {
+		go func(val int) {
+			if val%2 == 0 {
+				v.Send()
+			} else {
+				v.Receive(map[string]int64{"node-b": int64(val)})
+			}
+			done <- true
+		}(i)
+	}
+
+	for i := 0; i < operations; i++ {
+		<-done
+	}
+
+	if v.Clock()["node-a"] != int64(operations) {
+		t.Errorf("Expected own counter %d, got %d", operations, v.Clock()["node-a"])
+	}
+}
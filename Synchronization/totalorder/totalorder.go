@@ -0,0 +1,340 @@
+package totalorder
+
+import (
+	"sort"
+	"sync"
+
+	lamport "github.com/simonnyman/DISY_Projects/Synchronization/lamport"
+)
+
+// kinds of protocol packets
+const (
+	Data    = "data"    // the multicast itself
+	Ack     = "ack"     // Lamport: the receiver has queued a multicast
+	Propose = "propose" // ISIS: the receiver's proposed priority
+	Agree   = "agree"   // ISIS: the agreed priority, the largest proposal
+)
+
+// ID identifies a multicast by its sender and the sender's count of
+// multicasts, starting at 1.
+type ID struct {
+	Sender int
+	Seq    int
+}
+
+// Packet is a protocol message from one process to another.
+type Packet struct {
+	Kind    string
+	From    int
+	To      int
+	ID      ID
+	Stamp   lamport.Timestamp // Lamport time or priority, with the process it belongs to
+	Payload interface{}       // the multicast payload, Data packets only
+}
+
+// Delivery is a multicast handed to the application in the total order.
+type Delivery struct {
+	ID      ID
+	Stamp   lamport.Timestamp // position in the total order
+	Payload interface{}
+}
+
+// Protocol is a total-order multicast algorithm run by one process of a
+// fixed group. packets are returned for the caller to transmit; a process
+// never sends packets to itself.
+type Protocol interface {
+	// starts a multicast to the whole group, including the sender.
+	// returns the packets to send and the multicasts now deliverable.
+	Multicast(payload interface{}) ([]Packet, []Delivery)
+	// handles a packet from another process.
+	// returns the packets to send and the multicasts now deliverable.
+	Receive(p Packet) ([]Packet, []Delivery)
+	// returns the number of multicasts received but not yet delivered.
+	Pending() int
+}
+
+// a multicast in a delivery queue
+type entry struct {
+	id          ID
+	stamp       lamport.Timestamp
+	payload     interface{}
+	deliverable bool
+}
+
+// delivery queue ordered by stamp
+type queue []*entry
+
+// adds an entry, keeping the queue sorted.
+func (q *queue) insert(e *entry) {
+	*q = append(*q, e)
+	q.sort()
+}
+
+// restores the order after a stamp changed.
+func (q *queue) sort() {
+	sort.Slice(*q, func(i, j int) bool { return (*q)[i].stamp.Less((*q)[j].stamp) })
+}
+
+// returns the entry of a multicast, or nil if it is not queued.
+func (q queue) find(id ID) *entry {
+	for _, e := range q {
+		if e.id == id {
+			return e
+		}
+	}
+	return nil
+}
+
+// removes and returns the deliverable entries at the head of the queue.
+func (q *queue) pop() []Delivery {
+	var ready []Delivery
+	for len(*q) > 0 && (*q)[0].deliverable {
+		e := (*q)[0]
+		*q = (*q)[1:]
+		ready = append(ready, Delivery{ID: e.id, Stamp: e.stamp, Payload: e.payload})
+	}
+	return ready
+}
+
+// panics if self is not in [0, n).
+func checkMember(self, n int) {
+	if self < 0 || self >= n {
+		panic("totalorder: process ID out of bounds")
+	}
+}
+
+// total-order multicast with Lamport timestamps and acknowledgements
+// every multicast is stamped (LamportTime, sender) and queued in stamp
+// order everywhere. each receiver acknowledges it to the whole group; a
+// multicast is delivered once it heads the queue and every process has
+// acknowledged it, since no process can then still send one with a
+// smaller stamp. needs reliable FIFO links.
+// thread-safe for concurrent use.
+type Lamport struct {
+	self  int
+	n     int
+	clock *lamport.LamportClock
+	seq   int
+	queue queue
+	acks  map[ID]map[int]bool // processes that acknowledged each multicast, possibly before its data arrived
+	mu    sync.Mutex
+}
+
+// creates the Lamport protocol for process self in a group of n.
+// panics if self is not in [0, n).
+func NewLamport(self, n int) *Lamport {
+	checkMember(self, n)
+	return &Lamport{
+		self:  self,
+		n:     n,
+		clock: lamport.NewLamportClock(),
+		acks:  make(map[ID]map[int]bool),
+	}
+}
+
+// stamps a multicast with the next Lamport time and sends it to the group.
+func (l *Lamport) Multicast(payload interface{}) ([]Packet, []Delivery) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.seq++
+	id := ID{l.self, l.seq}
+	stamp := lamport.Timestamp{Time: l.clock.Send(), ProcessID: l.self}
+	l.queue.insert(&entry{id: id, stamp: stamp, payload: payload})
+	l.acknowledge(id, l.self)
+
+	packets := l.toOthers(Packet{Kind: Data, ID: id, Stamp: stamp, Payload: payload})
+	return packets, l.ready()
+}
+
+// queues a multicast and acknowledges it, or records an acknowledgement.
+// panics on an unknown packet kind or sender.
+func (l *Lamport) Receive(p Packet) ([]Packet, []Delivery) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if p.From < 0 || p.From >= l.n || p.From == l.self {
+		panic("totalorder: sender out of bounds")
+	}
+	l.clock.Receive(p.Stamp.Time)
+
+	var packets []Packet
+	switch p.Kind {
+	case Data:
+		l.queue.insert(&entry{id: p.ID, stamp: p.Stamp, payload: p.Payload})
+		// the data itself shows the sender has it queued
+		l.acknowledge(p.ID, p.From)
+		l.acknowledge(p.ID, l.self)
+		stamp := lamport.Timestamp{Time: l.clock.Send(), ProcessID: l.self}
+		packets = l.toOthers(Packet{Kind: Ack, ID: p.ID, Stamp: stamp})
+	case Ack:
+		l.acknowledge(p.ID, p.From)
+	default:
+		panic("totalorder: unexpected packet kind " + p.Kind)
+	}
+	return packets, l.ready()
+}
+
+// returns the number of multicasts queued but not yet delivered.
+func (l *Lamport) Pending() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.queue)
+}
+
+// records that a process acknowledged a multicast.
+// must be called with lock held.
+func (l *Lamport) acknowledge(id ID, from int) {
+	if l.acks[id] == nil {
+		l.acks[id] = make(map[int]bool)
+	}
+	l.acks[id][from] = true
+}
+
+// marks fully acknowledged multicasts deliverable and pops the head.
+// must be called with lock held.
+func (l *Lamport) ready() []Delivery {
+	for _, e := range l.queue {
+		e.deliverable = len(l.acks[e.id]) == l.n
+	}
+	ready := l.queue.pop()
+	for _, d := range ready {
+		delete(l.acks, d.ID)
+	}
+	return ready
+}
+
+// addresses a copy of p from this process to every other process.
+// must be called with lock held.
+func (l *Lamport) toOthers(p Packet) []Packet {
+	return toOthers(p, l.self, l.n)
+}
+
+// returns copies of p from self to every other member of a group of n.
+func toOthers(p Packet, self, n int) []Packet {
+	packets := make([]Packet, 0, n-1)
+	for to := 0; to < n; to++ {
+		if to != self {
+			p.From, p.To = self, to
+			packets = append(packets, p)
+		}
+	}
+	return packets
+}
+
+// ISIS total-order multicast with agreed priorities
+// every receiver proposes a priority above all priorities it has seen and
+// queues the multicast as undeliverable; the sender picks the largest
+// proposal, tie-broken by process ID, and announces it. a multicast is
+// delivered once its priority is agreed and it heads the queue, since
+// every undecided one will end up with a larger priority.
+// works over reliable links in any order.
+// thread-safe for concurrent use.
+type ISIS struct {
+	self      int
+	n         int
+	seq       int
+	proposed  int64 // largest priority proposed
+	agreed    int64 // largest priority agreed
+	queue     queue
+	proposals map[ID][]lamport.Timestamp // proposals collected for own multicasts
+	mu        sync.Mutex
+}
+
+// creates the ISIS protocol for process self in a group of n.
+// panics if self is not in [0, n).
+func NewISIS(self, n int) *ISIS {
+	checkMember(self, n)
+	return &ISIS{
+		self:      self,
+		n:         n,
+		proposals: make(map[ID][]lamport.Timestamp),
+	}
+}
+
+// proposes a priority for a new multicast and sends it to the group.
+func (is *ISIS) Multicast(payload interface{}) ([]Packet, []Delivery) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	is.seq++
+	id := ID{is.self, is.seq}
+	stamp := is.propose()
+	is.queue.insert(&entry{id: id, stamp: stamp, payload: payload})
+	is.proposals[id] = []lamport.Timestamp{stamp}
+
+	packets := toOthers(Packet{Kind: Data, ID: id, Payload: payload}, is.self, is.n)
+	return append(packets, is.decide(id)...), is.queue.pop()
+}
+
+// proposes a priority for a multicast, collects a proposal for an own
+// multicast, or applies an agreed priority.
+// panics on an unknown packet kind or sender.
+func (is *ISIS) Receive(p Packet) ([]Packet, []Delivery) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	if p.From < 0 || p.From >= is.n || p.From == is.self {
+		panic("totalorder: sender out of bounds")
+	}
+
+	var packets []Packet
+	switch p.Kind {
+	case Data:
+		stamp := is.propose()
+		is.queue.insert(&entry{id: p.ID, stamp: stamp, payload: p.Payload})
+		packets = []Packet{{Kind: Propose, From: is.self, To: p.From, ID: p.ID, Stamp: stamp}}
+	case Propose:
+		is.proposals[p.ID] = append(is.proposals[p.ID], p.Stamp)
+		packets = is.decide(p.ID)
+	case Agree:
+		is.agree(p.ID, p.Stamp)
+	default:
+		panic("totalorder: unexpected packet kind " + p.Kind)
+	}
+	return packets, is.queue.pop()
+}
+
+// returns the number of multicasts queued but not yet delivered.
+func (is *ISIS) Pending() int {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+	return len(is.queue)
+}
+
+// returns a priority above every priority proposed or agreed so far.
+// must be called with lock held.
+func (is *ISIS) propose() lamport.Timestamp {
+	is.proposed = max(is.proposed, is.agreed) + 1
+	return lamport.Timestamp{Time: is.proposed, ProcessID: is.self}
+}
+
+// agrees on the largest proposal once every process has proposed and
+// announces it. must be called with lock held.
+func (is *ISIS) decide(id ID) []Packet {
+	proposals := is.proposals[id]
+	if len(proposals) < is.n {
+		return nil
+	}
+	delete(is.proposals, id)
+
+	final := proposals[0]
+	for _, p := range proposals[1:] {
+		if final.Less(p) {
+			final = p
+		}
+	}
+	is.agree(id, final)
+	return toOthers(Packet{Kind: Agree, ID: id, Stamp: final}, is.self, is.n)
+}
+
+// gives a queued multicast its agreed priority.
+// must be called with lock held.
+func (is *ISIS) agree(id ID, final lamport.Timestamp) {
+	is.agreed = max(is.agreed, final.Time)
+	if e := is.queue.find(id); e != nil {
+		e.stamp = final
+		e.deliverable = true
+		is.queue.sort()
+	}
+}
@@ -0,0 +1,262 @@
+package totalorder
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// a group of processes connected by reliable links, delivering packets in a
+// random order that is FIFO per link unless fifo is false
+type group struct {
+	procs     []Protocol
+	links     map[[2]int][]Packet
+	delivered [][]ID
+	rng       *rand.Rand
+	fifo      bool
+}
+
+func newGroup(n int, isis, fifo bool, seed int64) *group {
+	g := &group{
+		links:     make(map[[2]int][]Packet),
+		delivered: make([][]ID, n),
+		rng:       rand.New(rand.NewSource(seed)),
+		fifo:      fifo,
+	}
+	for i := 0; i < n; i++ {
+		if isis {
+			g.procs = append(g.procs, NewISIS(i, n))
+		} else {
+			g.procs = append(g.procs, NewLamport(i, n))
+		}
+	}
+	return g
+}
+
+func (g *group) handle(self int, packets []Packet, deliveries []Delivery) {
+	for _, p := range packets {
+		link := [2]int{p.From, p.To}
+		g.links[link] = append(g.links[link], p)
+	}
+	for _, d := range deliveries {
+		g.delivered[self] = append(g.delivered[self], d.ID)
+	}
+}
+
+func (g *group) multicast(self int) {
+	packets, deliveries := g.procs[self].Multicast(self)
+	g.handle(self, packets, deliveries)
+}
+
+// delivers one packet from a random link; returns false if none is in flight.
+func (g *group) step() bool {
+	var links [][2]int
+	for link, packets := range g.links {
+		if len(packets) > 0 {
+			links = append(links, link)
+		}
+	}
+	if len(links) == 0 {
+		return false
+	}
+	// map order is random; sort for reproducibility
+	for i := range links {
+		for j := i + 1; j < len(links); j++ {
+			if links[j][0] < links[i][0] || (links[j][0] == links[i][0] && links[j][1] < links[i][1]) {
+				links[i], links[j] = links[j], links[i]
+			}
+		}
+	}
+	link := links[g.rng.Intn(len(links))]
+	i := 0
+	if !g.fifo {
+		i = g.rng.Intn(len(g.links[link]))
+	}
+	p := g.links[link][i]
+	g.links[link] = append(g.links[link][:i:i], g.links[link][i+1:]...)
+
+	packets, deliveries := g.procs[p.To].Receive(p)
+	g.handle(p.To, packets, deliveries)
+	return true
+}
+
+// Basic functionality tests
+
+// verifies a single process delivers its multicasts at once with either
+// protocol.
+func TestSingleProcess(t *testing.T) {
+	for _, p := range []Protocol{NewLamport(0, 1), NewISIS(0, 1)} {
+		packets, deliveries := p.Multicast("x")
+		if len(packets) != 0 || len(deliveries) != 1 || deliveries[0].Payload != "x" {
+			t.Errorf("%T: expected immediate delivery, got %v and %v", p, packets, deliveries)
+		}
+		if p.Pending() != 0 {
+			t.Errorf("%T: expected empty queue, got %d", p, p.Pending())
+		}
+	}
+}
+
+// verifies the Lamport protocol waits for every acknowledgement.
+func TestLamportWaitsForAcks(t *testing.T) {
+	g := newGroup(3, false, true, 1)
+	g.multicast(0)
+	if len(g.delivered[0]) != 0 || g.procs[0].Pending() != 1 {
+		t.Fatal("Sender should hold its multicast until acknowledged")
+	}
+
+	// process 1 queues the multicast and acknowledges it to 0 and 2
+	data := g.links[[2]int{0, 1}][0]
+	g.links[[2]int{0, 1}] = nil
+	packets, deliveries := g.procs[1].Receive(data)
+	if len(packets) != 2 || packets[0].Kind != Ack || len(deliveries) != 0 {
+		t.Fatalf("Expected 2 acks and no delivery, got %v and %v", packets, deliveries)
+	}
+	if packets[0].Stamp.Time <= data.Stamp.Time {
+		t.Error("Ack should be stamped after the data it acknowledges")
+	}
+
+	// process 0 still misses the acknowledgement from 2
+	if _, deliveries := g.procs[0].Receive(packets[0]); len(deliveries) != 0 {
+		t.Error("Sender should wait for every process")
+	}
+}
+
+// verifies the Lamport protocol orders by time and breaks ties by sender.
+func TestLamportOrder(t *testing.T) {
+	g := newGroup(2, false, true, 1)
+	g.multicast(1)
+	g.multicast(0) // same Lamport time, smaller sender
+	for g.step() {
+	}
+
+	expected := []ID{{0, 1}, {1, 1}}
+	for i, seq := range g.delivered {
+		if !reflect.DeepEqual(seq, expected) {
+			t.Errorf("Process %d delivered %v, expected %v", i, seq, expected)
+		}
+	}
+}
+
+// verifies the ISIS sender agrees on the largest proposal.
+func TestISISAgreesOnLargestProposal(t *testing.T) {
+	g := newGroup(3, true, true, 1)
+	g.multicast(2) // process 2 has proposed 1 for its own multicast
+	g.multicast(0)
+
+	// deliver 0's multicast everywhere first: 1 proposes 1, 2 proposes 2
+	for _, to := range []int{1, 2} {
+		link := [2]int{0, to}
+		p := g.links[link][0]
+		g.links[link] = g.links[link][1:]
+		packets, _ := g.procs[to].Receive(p)
+		if packets[0].Kind != Propose {
+			t.Fatalf("Expected a proposal, got %v", packets[0])
+		}
+		g.handle(to, packets, nil)
+	}
+	// the proposals queue behind 2's own data on its link to 0
+	var agree []Packet
+	for _, from := range []int{1, 2} {
+		packets := g.links[[2]int{from, 0}]
+		proposal := packets[len(packets)-1]
+		out, _ := g.procs[0].Receive(proposal)
+		agree = append(agree, out...)
+	}
+
+	if len(agree) != 2 || agree[0].Kind != Agree || agree[0].Stamp.Time != 2 || agree[0].Stamp.ProcessID != 2 {
+		t.Errorf("Expected agreement on priority 2 proposed by process 2, got %v", agree)
+	}
+}
+
+// verifies unknown packets and invalid members panic.
+func TestPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"negative member", func() { NewLamport(-1, 2) }},
+		{"member out of bounds", func() { NewISIS(2, 2) }},
+		{"lamport unknown kind", func() { NewLamport(0, 2).Receive(Packet{Kind: Agree, From: 1}) }},
+		{"isis unknown kind", func() { NewISIS(0, 2).Receive(Packet{Kind: Ack, From: 1}) }},
+		{"packet from self", func() { NewLamport(0, 2).Receive(Packet{Kind: Ack, From: 0}) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic")
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
+
+// Scenario-based tests
+
+// verifies every process delivers every multicast in the same order when
+// multicasts and packets interleave at random.
+func TestAgreement(t *testing.T) {
+	tests := []struct {
+		name string
+		isis bool
+		fifo bool
+	}{
+		{"lamport", false, true},
+		{"isis", true, true},
+		{"isis unordered links", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for seed := int64(1); seed <= 20; seed++ {
+				const n, multicasts = 5, 30
+				g := newGroup(n, tt.isis, tt.fifo, seed)
+				for sent := 0; sent < multicasts; {
+					if g.rng.Intn(3) == 0 {
+						g.multicast(g.rng.Intn(n))
+						sent++
+					} else {
+						g.step()
+					}
+				}
+				for g.step() {
+				}
+
+				for i := 0; i < n; i++ {
+					if len(g.delivered[i]) != multicasts {
+						t.Fatalf("Seed %d: process %d delivered %d of %d multicasts", seed, i, len(g.delivered[i]), multicasts)
+					}
+					if !reflect.DeepEqual(g.delivered[i], g.delivered[0]) {
+						t.Fatalf("Seed %d: process %d delivered %v, process 0 delivered %v", seed, i, g.delivered[i], g.delivered[0])
+					}
+					if g.procs[i].Pending() != 0 {
+						t.Fatalf("Seed %d: process %d still holds %d multicasts", seed, i, g.procs[i].Pending())
+					}
+				}
+			}
+		})
+	}
+}
+
+// verifies a multicast sent after delivering another is ordered after it,
+// so the total order respects causality.
+func TestCausalOrder(t *testing.T) {
+	for _, isis := range []bool{false, true} {
+		g := newGroup(3, isis, true, 5)
+		g.multicast(0)
+		for len(g.delivered[1]) == 0 {
+			g.step()
+		}
+		g.multicast(1)
+		for g.step() {
+		}
+
+		for i, seq := range g.delivered {
+			if len(seq) != 2 || seq[0] != (ID{0, 1}) {
+				t.Errorf("isis=%v: process %d delivered %v, expected 0's multicast first", isis, i, seq)
+			}
+		}
+	}
+}
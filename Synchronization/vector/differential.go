@@ -0,0 +1,65 @@
+package vector
+
+// one vector component sent by differential transmission
+type Entry struct {
+	Index int
+	Value int64
+}
+
+// wire size of an entry: int32 index + int64 counter.
+const EntrySize = 12
+
+// increments the clock and returns the timestamp for a message to toID,
+// together with the entries that changed since the last message to toID
+// (Singhal–Kshemkalyani). only those entries need to be transmitted,
+// provided messages between each pair of processes arrive in FIFO order.
+// panics if toID is out of bounds.
+func (v *Vector) SendTo(toID int) ([]int64, []Entry) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if toID < 0 || toID >= len(v.clock) {
+		panic("vector: toID out of bounds")
+	}
+
+	v.increment()
+
+	entries := make([]Entry, 0)
+	for i, updated := range v.lastUpdate {
+		if updated > v.lastSent[toID] {
+			entries = append(entries, Entry{Index: i, Value: v.clock[i]})
+		}
+	}
+	v.lastSent[toID] = v.clock[v.processID]
+
+	return v.copyClock(), entries
+}
+
+// updates the clock from entries received via differential transmission.
+// entries that are not listed have not changed since the sender's previous
+// message, so merging the listed ones rebuilds the same clock as Receive
+// with the full vector would.
+// panics if an entry index is out of bounds.
+func (v *Vector) ReceiveEntries(entries []Entry) []int64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	own := v.clock[v.processID]
+	for _, e := range entries {
+		if e.Index < 0 || e.Index >= len(v.clock) {
+			panic("vector: entry index out of bounds")
+		}
+		if e.Index == v.processID {
+			own = max(own, e.Value)
+		}
+	}
+
+	for _, e := range entries {
+		if e.Value > v.clock[e.Index] {
+			v.clock[e.Index] = e.Value
+			v.lastUpdate[e.Index] = own + 1
+		}
+	}
+	v.increment()
+	return v.copyClock()
+}
@@ -7,16 +7,20 @@ import (
 // vector clock
 // thread-safe for concurrent use.
 type Vector struct {
-	processID int
-	clock     []int64
-	mu        sync.RWMutex
+	processID  int
+	clock      []int64
+	lastSent   []int64 // own counter when last sending to each process
+	lastUpdate []int64 // own counter when each entry last changed
+	mu         sync.RWMutex
 }
 
 // creates a new Vector clock for the specified process.
 func NewVector(processID, numProcesses int) *Vector {
 	return &Vector{
-		processID: processID,
-		clock:     make([]int64, numProcesses),
+		processID:  processID,
+		clock:      make([]int64, numProcesses),
+		lastSent:   make([]int64, numProcesses),
+		lastUpdate: make([]int64, numProcesses),
 	}
 }
 
@@ -24,7 +28,7 @@ func NewVector(processID, numProcesses int) *Vector {
 func (v *Vector) Tick() []int64 {
 	v.mu.Lock()
 	defer v.mu.Unlock()
-	v.clock[v.processID]++
+	v.increment()
 	return v.copyClock()
 }
 
@@ -32,20 +36,32 @@ func (v *Vector) Tick() []int64 {
 func (v *Vector) Send() []int64 {
 	v.mu.Lock()
 	defer v.mu.Unlock()
-	v.clock[v.processID]++
+	v.increment()
 	return v.copyClock()
 }
 
 // updates the clock based on received timestamp.
-// merges by taking component-wise max, then increments own counter.
+// merges by taking component-wise max, then increments own counter, so the
+// result follows the received timestamp even when it knows of more own
+// events than the clock, as after a crash rolled the clock back.
+// panics if the received clock has a different length.
 func (v *Vector) Receive(receivedClock []int64) []int64 {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 
+	if len(receivedClock) != len(v.clock) {
+		panic("vector: cannot merge clocks of different lengths")
+	}
+
+	// entries change at the own counter the receive will be stamped with
+	own := max(v.clock[v.processID], receivedClock[v.processID]) + 1
 	for i := range v.clock {
-		v.clock[i] = max(v.clock[i], receivedClock[i])
+		if receivedClock[i] > v.clock[i] {
+			v.clock[i] = receivedClock[i]
+			v.lastUpdate[i] = own
+		}
 	}
-	v.clock[v.processID]++
+	v.increment()
 	return v.copyClock()
 }
 
@@ -63,9 +79,47 @@ func (v *Vector) Reset() {
 
 	for i := range v.clock {
 		v.clock[i] = 0
+		v.lastSent[i] = 0
+		v.lastUpdate[i] = 0
 	}
 }
 
+// sets the clock to a previously saved timestamp, e.g. when a process
+// recovers from a crash. the clock may move backwards. every non-zero entry
+// counts as changed, so the next differential message to each process
+// carries it.
+// panics if saved has a different length or a negative entry.
+func (v *Vector) Restore(saved []int64) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if len(saved) != len(v.clock) {
+		panic("vector: cannot restore a clock of different length")
+	}
+	for _, value := range saved {
+		if value < 0 {
+			panic("vector: cannot restore a negative entry")
+		}
+	}
+
+	changed := max(saved[v.processID], 1)
+	for i, value := range saved {
+		v.clock[i] = value
+		v.lastSent[i] = 0
+		v.lastUpdate[i] = 0
+		if value > 0 {
+			v.lastUpdate[i] = changed
+		}
+	}
+}
+
+// increments own counter and marks it as changed.
+// must be called with lock held.
+func (v *Vector) increment() {
+	v.clock[v.processID]++
+	v.lastUpdate[v.processID] = v.clock[v.processID]
+}
+
 // creates a copy of the clock slice.
 // must be called with lock held.
 func (v *Vector) copyClock() []int64 {
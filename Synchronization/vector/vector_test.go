@@ -1,6 +1,7 @@
 package vector
 
 import (
+	"math/rand"
 	"reflect"
 	"testing"
 )
@@ -95,6 +96,35 @@ func TestVectorReceiveLowerTimestamp(t *testing.T) {
 	}
 }
 
+// verifies a received timestamp that knows of more own events than the
+// clock, as after a rollback, still happened before the receive.
+func TestVectorReceiveOwnEntryAhead(t *testing.T) {
+	received := []int64{5, 2}
+
+	v := NewVector(0, 2)
+	v.Tick() // [1, 0], rolled back from [5, 0]
+	clock := v.Receive(received)
+	if expected := []int64{6, 2}; !reflect.DeepEqual(clock, expected) {
+		t.Errorf("Expected %v, got %v", expected, clock)
+	}
+	if order := CompareClocks(received, clock); order != Before {
+		t.Errorf("Expected the send before the receive, got %v", order)
+	}
+
+	v = NewVector(0, 2)
+	v.Tick()
+	clock = v.ReceiveEntries([]Entry{{0, 5}, {1, 2}})
+	if expected := []int64{6, 2}; !reflect.DeepEqual(clock, expected) {
+		t.Errorf("Expected %v from entries, got %v", expected, clock)
+	}
+
+	// both entries changed at the receive, so a differential message carries them
+	_, entries := v.SendTo(1)
+	if expected := []Entry{{Index: 0, Value: 7}, {Index: 1, Value: 2}}; !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Expected %v, got %v", expected, entries)
+	}
+}
+
 // verifies reset sets all components to zero.
 func TestVectorReset(t *testing.T) {
 	v := NewVector(0, 3)
@@ -111,6 +141,25 @@ func TestVectorReset(t *testing.T) {
 	}
 }
 
+// verifies restore sets a saved clock and differential messages carry it.
+func TestVectorRestore(t *testing.T) {
+	v := NewVector(0, 3)
+	v.Receive([]int64{0, 4, 0})
+	v.SendTo(1)
+	v.Tick()
+
+	v.Restore([]int64{1, 2, 0})
+	if clock := v.Clock(); !reflect.DeepEqual(clock, []int64{1, 2, 0}) {
+		t.Errorf("Expected [1 2 0] after restore, got %v", clock)
+	}
+
+	_, entries := v.SendTo(1)
+	expected := []Entry{{Index: 0, Value: 2}, {Index: 1, Value: 2}}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Expected %v after restore, got %v", expected, entries)
+	}
+}
+
 // verifies CompareClocks correctly identifies all relationships.
 func TestCompareClocks(t *testing.T) {
 	tests := []struct {
@@ -191,6 +240,109 @@ func TestCompareClocksPanic(t *testing.T) {
 	CompareClocks(v1, v2)
 }
 
+// verifies panic when receiving a clock of a different length.
+func TestVectorReceivePanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic when receiving clock of different length")
+		}
+	}()
+
+	v := NewVector(0, 2)
+	v.Receive([]int64{1, 2, 3})
+}
+
+// verifies panic when restoring a clock of a different length.
+func TestVectorRestorePanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic when restoring clock of different length")
+		}
+	}()
+
+	v := NewVector(0, 2)
+	v.Restore([]int64{1, 2, 3})
+}
+
+// verifies a rejected restore leaves the clock unchanged.
+func TestVectorRestoreNegativeKeepsClock(t *testing.T) {
+	v := NewVector(0, 3)
+	v.Receive([]int64{0, 4, 2})
+	before := v.Clock()
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("Expected panic when restoring a negative entry")
+			}
+		}()
+		v.Restore([]int64{7, 1, -1})
+	}()
+
+	if clock := v.Clock(); !reflect.DeepEqual(clock, before) {
+		t.Errorf("Expected %v after the rejected restore, got %v", before, clock)
+	}
+}
+
+// verifies SendTo transmits only entries changed since the last message to that destination.
+func TestVectorSendToEntries(t *testing.T) {
+	v := NewVector(0, 3)
+	v.Receive([]int64{0, 2, 0})
+
+	_, entries := v.SendTo(2)
+	expected := []Entry{{0, 2}, {1, 2}}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("First message should carry %v, got %v", expected, entries)
+	}
+
+	// nothing but the own counter changed since
+	_, entries = v.SendTo(2)
+	expected = []Entry{{0, 3}}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Second message should carry %v, got %v", expected, entries)
+	}
+
+	// a different destination has not seen anything yet
+	_, entries = v.SendTo(1)
+	if len(entries) != 2 {
+		t.Errorf("Message to a new destination should carry 2 entries, got %v", entries)
+	}
+}
+
+// verifies ReceiveEntries merges the listed entries and increments own counter.
+func TestVectorReceiveEntries(t *testing.T) {
+	v := NewVector(1, 3)
+	v.Receive([]int64{3, 0, 1})
+
+	clock := v.ReceiveEntries([]Entry{{0, 4}, {2, 1}})
+	expected := []int64{4, 2, 1}
+	if !reflect.DeepEqual(clock, expected) {
+		t.Errorf("Expected %v, got %v", expected, clock)
+	}
+}
+
+// verifies panic on an out-of-bounds destination or entry index.
+func TestVectorDifferentialPanic(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(v *Vector)
+	}{
+		{"destination", func(v *Vector) { v.SendTo(2) }},
+		{"entry index", func(v *Vector) { v.ReceiveEntries([]Entry{{5, 1}}) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("Expected panic for out-of-bounds %s", tt.name)
+				}
+			}()
+			tt.fn(NewVector(0, 2))
+		})
+	}
+}
+
 // Scenario-based tests
 
 // simulates message exchange between two processes.
@@ -349,3 +501,55 @@ func TestVectorClockTransitivity(t *testing.T) {
 		t.Errorf("Expected transitive Before relationship, got %v", ordering)
 	}
 }
+
+// verifies differential transmission over FIFO channels rebuilds exactly the
+// clocks that full vector transmission produces.
+func TestVectorDifferentialMatchesFull(t *testing.T) {
+	const n = 4
+	full := make([]*Vector, n)
+	diff := make([]*Vector, n)
+	for i := 0; i < n; i++ {
+		full[i] = NewVector(i, n)
+		diff[i] = NewVector(i, n)
+	}
+
+	type message struct {
+		clock   []int64
+		entries []Entry
+	}
+	channels := make(map[[2]int][]message)
+
+	// fixed schedule of sends, receives and local events
+	rng := rand.New(rand.NewSource(1))
+	sentEntries := 0
+	for step := 0; step < 500; step++ {
+		from, to := rng.Intn(n), rng.Intn(n)
+		switch {
+		case from == to:
+			a, b := full[from].Tick(), diff[from].Tick()
+			if !reflect.DeepEqual(a, b) {
+				t.Fatalf("Step %d: tick diverged: %v vs %v", step, a, b)
+			}
+		case rng.Intn(2) == 0:
+			clock := full[from].Send()
+			_, entries := diff[from].SendTo(to)
+			sentEntries += len(entries)
+			channels[[2]int{from, to}] = append(channels[[2]int{from, to}], message{clock, entries})
+		default:
+			queue := channels[[2]int{from, to}]
+			if len(queue) == 0 {
+				continue
+			}
+			m := queue[0]
+			channels[[2]int{from, to}] = queue[1:]
+			a, b := full[to].Receive(m.clock), diff[to].ReceiveEntries(m.entries)
+			if !reflect.DeepEqual(a, b) {
+				t.Fatalf("Step %d: receive diverged: %v vs %v", step, a, b)
+			}
+		}
+	}
+
+	if sentEntries == 0 {
+		t.Error("Expected some entries to be sent")
+	}
+}